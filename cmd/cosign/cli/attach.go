@@ -19,7 +19,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/sigstore/cosign/cmd/cosign/cli/attach"
+	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
 	"github.com/spf13/cobra"
 )
@@ -34,6 +36,7 @@ func Attach() *cobra.Command {
 		attachSignature(),
 		attachSBOM(),
 		attachAttestation(),
+		attachFile(),
 	)
 
 	return cmd
@@ -61,16 +64,44 @@ func attachSBOM() *cobra.Command {
 	o := &options.AttachSBOMOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "sbom",
-		Short:   "Attach sbom to the supplied container image",
-		Example: "  cosign attach sbom <image uri>",
-		Args:    cobra.ExactArgs(1),
+		Use:   "sbom",
+		Short: "Attach sbom to the supplied container image",
+		Example: `  cosign attach sbom <image uri>
+
+  # validate a CycloneDX SBOM, attach it and sign it with a key
+  cosign attach sbom --sbom bom.json --type cyclonedx --sign --key cosign.key <image uri>
+
+  # validate an SPDX SBOM, attach it and sign it with Google sign-in (experimental)
+  COSIGN_EXPERIMENTAL=1 cosign attach sbom --sbom sbom.spdx --sign <image uri>`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mediaType, err := o.MediaType()
 			if err != nil {
 				return err
 			}
-			fmt.Fprintf(os.Stderr, "WARNING: Attaching SBOMs this way does not sign them. If you want to sign them, use 'cosign attest -predicate %s -key <key path>' or 'cosign sign -key <key path> <sbom image>'.\n", o.SBOM)
+			if o.Sign {
+				oidcClientSecret, err := o.OIDC.ClientSecret()
+				if err != nil {
+					return err
+				}
+				ko := options.KeyOpts{
+					KeyRef:                   o.Key,
+					PassFunc:                 generate.GetPass,
+					Sk:                       o.SecurityKey.Use,
+					Slot:                     o.SecurityKey.Slot,
+					FulcioURL:                o.Fulcio.URL,
+					IDToken:                  o.Fulcio.IdentityToken,
+					InsecureSkipFulcioVerify: o.Fulcio.InsecureSkipFulcioVerify,
+					RekorURL:                 o.Rekor.URL,
+					OIDCIssuer:               o.OIDC.Issuer,
+					OIDCClientID:             o.OIDC.ClientID,
+					OIDCClientSecret:         oidcClientSecret,
+					OIDCRedirectURL:          o.OIDC.RedirectURL,
+					OIDCDisableProviders:     o.OIDC.DisableAmbientProviders,
+				}
+				return attach.SignedSBOMCmd(cmd.Context(), ro, ko, o.Registry, o.SBOM, mediaType, args[0], o.Cert, o.CertChain)
+			}
+			fmt.Fprintf(os.Stderr, "WARNING: Attaching SBOMs this way does not sign them. If you want to sign them, use 'cosign attach sbom --sign', 'cosign attest -predicate %s -key <key path>' or 'cosign sign -key <key path> <sbom image>'.\n", o.SBOM)
 			return attach.SBOMCmd(cmd.Context(), o.Registry, o.SBOM, mediaType, args[0])
 		},
 	}
@@ -97,3 +128,27 @@ func attachAttestation() *cobra.Command {
 
 	return cmd
 }
+
+func attachFile() *cobra.Command {
+	o := &options.AttachFileOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "file",
+		Short: "Attach a named file to the supplied container image",
+		Example: `  cosign attach file --file <path> --name <name> [--media-type <media type>] <image uri>
+
+  # attach release notes to an image
+  cosign attach file --file NOTES.md --name release-notes --media-type text/markdown <image uri>
+
+  # sign the attached release notes
+  cosign sign --key cosign.key --attachment release-notes <image uri>`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return attach.FileCmd(cmd.Context(), o.Registry, o.File, o.Name, types.MediaType(o.MediaType), args[0])
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
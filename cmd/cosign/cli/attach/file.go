@@ -0,0 +1,76 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+// FileCmd attaches the file found at filePath (or stdin) to the image, as the
+// attachment named attName.
+func FileCmd(ctx context.Context, regOpts options.RegistryOptions, filePath, attName string, mediaType types.MediaType, imageRef string) error {
+	if err := options.ValidateAttachmentName(attName); err != nil {
+		return err
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return err
+	}
+
+	b, err := fileBytes(filePath)
+	if err != nil {
+		return err
+	}
+
+	remoteOpts, err := regOpts.ClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+
+	dstRef, err := ociremote.AttachmentTag(ref, attName, remoteOpts...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Uploading file %s for [%s] to [%s] with mediaType [%s].\n", attName, ref.Name(), dstRef.Name(), mediaType)
+	img, err := static.NewFile(b, static.WithLayerMediaType(mediaType))
+	if err != nil {
+		return err
+	}
+	writeOpts, err := regOpts.RegistryClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+	return remote.Write(dstRef, img, writeOpts...)
+}
+
+func fileBytes(filePath string) ([]byte, error) {
+	if filePath == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(filepath.Clean(filePath))
+}
@@ -27,6 +27,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+	"github.com/sigstore/cosign/pkg/cosign/sbom"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
 	"github.com/sigstore/cosign/pkg/oci/static"
 )
@@ -52,12 +54,90 @@ func SBOMCmd(ctx context.Context, regOpts options.RegistryOptions, sbomRef strin
 		return err
 	}
 
+	_, err = uploadSBOM(ctx, regOpts, b, sbomType, ref, dstRef)
+	return err
+}
+
+// SignedSBOMCmd validates the SBOM against sbomType, attaches it to the image
+// and signs the attached SBOM image, so it can be checked with verify-sbom.
+func SignedSBOMCmd(ctx context.Context, ro *options.RootOptions, ko options.KeyOpts, regOpts options.RegistryOptions,
+	sbomRef string, sbomType types.MediaType, imageRef, certPath, certChainPath string) error {
+	if options.EnableExperimental() {
+		if options.NOf(ko.KeyRef, ko.Sk) > 1 {
+			return &options.KeyParseError{}
+		}
+	} else {
+		if !options.OneOf(ko.KeyRef, ko.Sk) {
+			return &options.KeyParseError{}
+		}
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return err
+	}
+
+	b, err := sbomBytes(sbomRef)
+	if err != nil {
+		return err
+	}
+	if err := sbom.Validate(b, sbomType); err != nil {
+		return err
+	}
+
+	if ro.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ro.Timeout)
+		defer cancel()
+	}
+
+	// Get the signer before overwriting the SBOM of the image, so that a
+	// wrong password or a failed OIDC flow leaves the signed one in place.
+	sv, err := sign.SignerFromKeyOpts(ctx, certPath, certChainPath, ko)
+	if err != nil {
+		return fmt.Errorf("getting signer: %w", err)
+	}
+	defer sv.Close()
+
+	remoteOpts, err := regOpts.ClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+
+	dstRef, err := ociremote.SBOMTag(ref, remoteOpts...)
+	if err != nil {
+		return err
+	}
+
+	digest, err := uploadSBOM(ctx, regOpts, b, sbomType, ref, dstRef)
+	if err != nil {
+		return err
+	}
+
+	// Sign the digest we just wrote rather than the tag, which may have
+	// been overwritten in the meantime.
+	return sign.SignDigest(ctx, ko, regOpts, digest, sv)
+}
+
+// uploadSBOM writes the SBOM to dstRef and returns the digest of the image holding it.
+func uploadSBOM(ctx context.Context, regOpts options.RegistryOptions, b []byte, sbomType types.MediaType, ref name.Reference, dstRef name.Tag) (name.Digest, error) {
 	fmt.Fprintf(os.Stderr, "Uploading SBOM file for [%s] to [%s] with mediaType [%s].\n", ref.Name(), dstRef.Name(), sbomType)
 	img, err := static.NewFile(b, static.WithLayerMediaType(sbomType))
 	if err != nil {
-		return err
+		return name.Digest{}, err
+	}
+	remoteOpts, err := regOpts.RegistryClientOpts(ctx)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	if err := remote.Write(dstRef, img, remoteOpts...); err != nil {
+		return name.Digest{}, err
+	}
+	h, err := img.Digest()
+	if err != nil {
+		return name.Digest{}, err
 	}
-	return remote.Write(dstRef, img, regOpts.GetRegistryClientOpts(ctx)...)
+	return dstRef.Context().Digest(h.String()), nil
 }
 
 func sbomBytes(sbomRef string) ([]byte, error) {
@@ -79,7 +79,7 @@ func Attest() *cobra.Command {
 			}
 			for _, img := range args {
 				if err := attest.AttestCmd(cmd.Context(), ko, o.Registry, img, o.Cert, o.CertChain, o.NoUpload,
-					o.Predicate.Path, o.Force, o.Predicate.Type, o.Replace, ro.Timeout); err != nil {
+					o.Predicate.Path, o.Predicate.Format, o.Predicate.ScanTime, o.Predicate.Schema, o.Force, o.Predicate.Type, o.Replace, ro.Timeout); err != nil {
 					return fmt.Errorf("signing %s: %w", img, err)
 				}
 			}
@@ -74,7 +74,7 @@ func uploadToTlog(ctx context.Context, sv *sign.SignerVerifier, rekorURL string,
 
 //nolint
 func AttestCmd(ctx context.Context, ko options.KeyOpts, regOpts options.RegistryOptions, imageRef string, certPath string, certChainPath string,
-	noUpload bool, predicatePath string, force bool, predicateType string, replace bool, timeout time.Duration) error {
+	noUpload bool, predicatePath string, predicateFormat string, scanTime string, predicateSchemaPath string, force bool, predicateType string, replace bool, timeout time.Duration) error {
 	// A key file or token is required unless we're in experimental mode!
 	if options.EnableExperimental() {
 		if options.NOf(ko.KeyRef, ko.Sk) > 1 {
@@ -91,6 +91,13 @@ func AttestCmd(ctx context.Context, ko options.KeyOpts, regOpts options.Registry
 		return err
 	}
 
+	var reportScanTime time.Time
+	if scanTime != "" {
+		if reportScanTime, err = time.Parse(time.RFC3339, scanTime); err != nil {
+			return fmt.Errorf("invalid --scan-time: %w", err)
+		}
+	}
+
 	ref, err := name.ParseReference(imageRef)
 	if err != nil {
 		return fmt.Errorf("parsing reference: %w", err)
@@ -116,14 +123,8 @@ func AttestCmd(ctx context.Context, ko options.KeyOpts, regOpts options.Registry
 	// each access.
 	ref = digest // nolint
 
-	sv, err := sign.SignerFromKeyOpts(ctx, certPath, certChainPath, ko)
-	if err != nil {
-		return fmt.Errorf("getting signer: %w", err)
-	}
-	defer sv.Close()
-	wrapped := dsse.WrapSigner(sv, types.IntotoPayloadType)
-	dd := cremote.NewDupeDetector(sv)
-
+	// The predicate is validated before getting a signer, which may need to
+	// authenticate with Fulcio.
 	fmt.Fprintln(os.Stderr, "Using payload from:", predicatePath)
 	predicate, err := os.Open(predicatePath)
 	if err != nil {
@@ -131,11 +132,22 @@ func AttestCmd(ctx context.Context, ko options.KeyOpts, regOpts options.Registry
 	}
 	defer predicate.Close()
 
+	var predicateSchema []byte
+	if predicateSchemaPath != "" {
+		predicateSchema, err = os.ReadFile(predicateSchemaPath)
+		if err != nil {
+			return fmt.Errorf("reading predicate schema: %w", err)
+		}
+	}
+
 	sh, err := attestation.GenerateStatement(attestation.GenerateOpts{
 		Predicate: predicate,
 		Type:      predicateType,
 		Digest:    h.Hex,
 		Repo:      digest.Repository.String(),
+		Format:    predicateFormat,
+		Schema:    predicateSchema,
+		ScanTime:  reportScanTime,
 	})
 	if err != nil {
 		return err
@@ -145,6 +157,15 @@ func AttestCmd(ctx context.Context, ko options.KeyOpts, regOpts options.Registry
 	if err != nil {
 		return err
 	}
+
+	sv, err := sign.SignerFromKeyOpts(ctx, certPath, certChainPath, ko)
+	if err != nil {
+		return fmt.Errorf("getting signer: %w", err)
+	}
+	defer sv.Close()
+	wrapped := dsse.WrapSigner(sv, types.IntotoPayloadType)
+	dd := cremote.NewDupeDetector(sv)
+
 	signedPayload, err := wrapped.SignMessage(bytes.NewReader(payload), signatureoptions.WithContext(ctx))
 	if err != nil {
 		return fmt.Errorf("signing: %w", err)
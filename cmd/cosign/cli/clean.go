@@ -36,12 +36,15 @@ func Clean() *cobra.Command {
 	c := &options.CleanOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "clean",
-		Short:   "Remove all signatures from an image.",
-		Example: "  cosign clean <IMAGE>",
-		Args:    cobra.ExactArgs(1),
+		Use:   "clean",
+		Short: "Remove all signatures from an image.",
+		Example: `  cosign clean <IMAGE>
+
+  # remove the file attachment named release-notes
+  cosign clean --type file --name release-notes <IMAGE>`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return CleanCmd(cmd.Context(), c.Registry, c.CleanType, args[0], c.Force)
+			return CleanCmd(cmd.Context(), c.Registry, c.CleanType, c.Name, args[0], c.Force)
 		},
 	}
 
@@ -49,9 +52,18 @@ func Clean() *cobra.Command {
 	return cmd
 }
 
-func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType, imageRef string, force bool) error {
+func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType, attName, imageRef string, force bool) error {
+	if attName != "" {
+		if cleanType != "file" {
+			return errors.New("--name can only be used with --type file")
+		}
+		if err := options.ValidateAttachmentName(attName); err != nil {
+			return err
+		}
+	}
+
 	if !force {
-		ok, err := cosign.ConfirmPromptDestructive(prompt(cleanType))
+		ok, err := cosign.ConfirmPromptDestructive(prompt(cleanType, attName))
 		if err != nil {
 			return err
 		}
@@ -65,7 +77,10 @@ func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType, i
 		return err
 	}
 
-	remoteOpts := regOpts.GetRegistryClientOpts(ctx)
+	remoteOpts, err := regOpts.RegistryClientOpts(ctx)
+	if err != nil {
+		return err
+	}
 
 	sigRef, err := ociremote.SignatureTag(ref, ociremote.WithRemoteOptions(remoteOpts...))
 	if err != nil {
@@ -90,8 +105,29 @@ func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType, i
 		cleanTags = []name.Tag{sbomRef}
 	case "attestation":
 		cleanTags = []name.Tag{attRef}
-	case "all":
-		cleanTags = []name.Tag{sigRef, attRef, sbomRef}
+	case "file", "all":
+		if cleanType == "all" {
+			cleanTags = []name.Tag{sigRef, attRef, sbomRef}
+		}
+		names := []string{attName}
+		if attName == "" {
+			if names, err = fileAttachmentNames(ref, remoteOpts...); err != nil {
+				if cleanType == "file" {
+					return err
+				}
+				// Registries may not allow listing tags, clean the rest anyway.
+				fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+			}
+		}
+		for _, n := range names {
+			t, err := ociremote.AttachmentTag(ref, n, ociremote.WithRemoteOptions(remoteOpts...))
+			if err != nil {
+				return err
+			}
+			cleanTags = append(cleanTags, t)
+		}
+	default:
+		return fmt.Errorf("invalid clean type %q: must be one of signature, attestation, sbom, file or all", cleanType)
 	}
 
 	for _, t := range cleanTags {
@@ -112,7 +148,24 @@ func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType, i
 	return nil
 }
 
-func prompt(cleanType string) string {
+// fileAttachmentNames returns the names of the file attachments of ref.
+func fileAttachmentNames(ref name.Reference, remoteOpts ...remote.Option) ([]string, error) {
+	all, err := ociremote.AttachmentNames(ref, ociremote.WithRemoteOptions(remoteOpts...))
+	if err != nil {
+		return nil, fmt.Errorf("listing file attachments: %w", err)
+	}
+	var names []string
+	for _, n := range all {
+		switch n {
+		case ociremote.SignatureTagSuffix, ociremote.AttestationTagSuffix, ociremote.SBOMTagSuffix:
+		default:
+			names = append(names, n)
+		}
+	}
+	return names, nil
+}
+
+func prompt(cleanType, attName string) string {
 	switch cleanType {
 	case "signature":
 		return "WARNING: this will remove all signatures from the image"
@@ -120,8 +173,13 @@ func prompt(cleanType string) string {
 		return "WARNING: this will remove all SBOMs from the image"
 	case "attestation":
 		return "WARNING: this will remove all attestations from the image"
+	case "file":
+		if attName != "" {
+			return fmt.Sprintf("WARNING: this will remove the file attachment %s from the image", attName)
+		}
+		return "WARNING: this will remove all file attachments from the image"
 	case "all":
-		return "WARNING: this will remove all signatures, SBOMs and attestations from the image"
+		return "WARNING: this will remove all signatures, SBOMs, attestations and file attachments from the image"
 	}
 	return ""
 }
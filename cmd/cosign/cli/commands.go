@@ -27,6 +27,7 @@ import (
 	cranecmd "github.com/google/go-containerregistry/cmd/crane/cmd"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/retry"
 )
 
 var (
@@ -79,6 +80,12 @@ func New() *cobra.Command {
 				cosign.SetSkipConfirmation(ro.SkipConfirmation)
 			}
 
+			policy, err := ro.RetryPolicy(cmd)
+			if err != nil {
+				return fmt.Errorf("invalid retry policy: %w", err)
+			}
+			retry.SetDefault(policy)
+
 			return nil
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
@@ -116,6 +123,7 @@ func New() *cobra.Command {
 	cmd.AddCommand(Verify())
 	cmd.AddCommand(VerifyAttestation())
 	cmd.AddCommand(VerifyBlob())
+	cmd.AddCommand(VerifySBOM())
 	cmd.AddCommand(Triangulate())
 	cmd.AddCommand(version.WithFont("starwars"))
 
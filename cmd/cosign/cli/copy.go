@@ -37,11 +37,21 @@ func Copy() *cobra.Command {
   cosign copy --sig-only example.com/src example.com/dest
 
   # overwrite destination image and signatures
-  cosign copy -f example.com/src example.com/dest`,
+  cosign copy -f example.com/src example.com/dest
+
+  # copy every tag of a repository, along with its signatures
+  cosign copy --all-tags example.com/src example.com/dest
+
+  # copy the release tags of a repository, 8 manifests at a time
+  cosign copy --tag-regex '^v[0-9.]+$' --jobs 8 example.com/src example.com/dest
+
+  # copy the linux/amd64 image of an index with its signatures and SLSA provenance only,
+  # the filtered index has to be re-signed
+  cosign copy --platform linux/amd64 --allow-unsigned-index --attachments sig,att --attestation-type slsaprovenance example.com/src:latest example.com/dest:latest`,
 
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return copy.CopyCmd(cmd.Context(), o.Registry, args[0], args[1], o.SignatureOnly, o.Force)
+			return copy.CopyCmd(cmd.Context(), *o, args[0], args[1])
 		},
 	}
 
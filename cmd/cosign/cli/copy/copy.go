@@ -20,90 +20,555 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
+	"sort"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"golang.org/x/sync/errgroup"
+
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
 	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/empty"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
 	"github.com/sigstore/cosign/pkg/oci/walk"
 )
 
+// attachmentTagRegexp matches the tags cosign uses to store attachments, as
+// well as the digest tags used to copy index children. These are copied along
+// with the image they belong to, never as tags of their own.
+var attachmentTagRegexp = regexp.MustCompile(`sha256-[a-f0-9]{64}(\.[\w-]+)?$`)
+
 // CopyCmd implements the logic to copy the supplied container image and signatures.
+// When the options select a whole repository, srcImg and dstImg name repositories
+// and every matching tag is copied.
 // nolint
-func CopyCmd(ctx context.Context, regOpts options.RegistryOptions, srcImg, dstImg string, sigOnly, force bool) error {
-	srcRef, err := name.ParseReference(srcImg)
+func CopyCmd(ctx context.Context, o options.CopyOptions, srcImg, dstImg string) error {
+	c, err := newCopier(ctx, o)
 	if err != nil {
 		return err
 	}
-	srcRepoRef := srcRef.Context()
 
+	if o.AllRepository() {
+		return c.copyRepository(ctx, srcImg, dstImg)
+	}
+
+	srcRef, err := name.ParseReference(srcImg)
+	if err != nil {
+		return err
+	}
 	dstRef, err := name.ParseReference(dstImg)
 	if err != nil {
 		return err
 	}
-	dstRepoRef := dstRef.Context()
+	return c.copyAll(ctx, []copyRef{{src: srcRef, dst: dstRef}})
+}
+
+// copyRef is a single source reference and the destination it is copied to.
+type copyRef struct {
+	src name.Reference
+	dst name.Reference
+}
+
+// operation is a single, idempotent copy of a manifest and its blobs.
+type operation struct {
+	// key identifies the operation so that manifests shared by several
+	// tags are only copied once.
+	key string
+	fn  func(ctx context.Context) error
+}
+
+// plan holds the operations needed to copy a single reference.
+type plan struct {
+	// ops copy the image, its children and their attachments. They are
+	// independent of each other and run concurrently.
+	ops []operation
+	// root updates the destination reference, once all ops succeeded.
+	root *operation
+}
+
+type copier struct {
+	opts           options.CopyOptions
+	remoteOpts     []remote.Option
+	attachments    map[string]bool
+	predicateTypes map[string]bool
+	platforms      []*v1.Platform
+}
+
+func newCopier(ctx context.Context, o options.CopyOptions) (*copier, error) {
+	remoteOpts, err := o.Registry.RegistryClientOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &copier{
+		opts:           o,
+		remoteOpts:     remoteOpts,
+		attachments:    make(map[string]bool, len(o.Attachments)),
+		predicateTypes: make(map[string]bool, len(o.AttestationTypes)),
+	}
+
+	for _, a := range o.Attachments {
+		switch a {
+		case options.AttachmentSignature, options.AttachmentAttestation, options.AttachmentSBOM, options.AttachmentFiles:
+			c.attachments[a] = true
+		default:
+			if err := options.ValidateAttachmentName(a); err != nil {
+				return nil, fmt.Errorf("invalid attachment %q: must be one of sig, att, sbom, files or the name of a file attachment", a)
+			}
+			c.attachments[a] = true
+		}
+	}
+	if o.SignatureOnly {
+		c.attachments = map[string]bool{options.AttachmentSignature: true}
+	}
+
+	for _, t := range o.AttestationTypes {
+		uri, err := options.ParsePredicateType(t)
+		if err != nil {
+			return nil, err
+		}
+		c.predicateTypes[uri] = true
+	}
+
+	for _, p := range o.Platforms {
+		platform, err := v1.ParsePlatform(p)
+		if err != nil {
+			return nil, fmt.Errorf("parsing platform %q: %w", p, err)
+		}
+		c.platforms = append(c.platforms, platform)
+	}
+
+	if o.Jobs < 1 {
+		return nil, fmt.Errorf("--jobs must be at least 1, got %d", o.Jobs)
+	}
+	return c, nil
+}
+
+// copyRepository copies every tag of srcRepoName (matching --tag-regex, if set)
+// to the same tag in dstRepoName.
+func (c *copier) copyRepository(ctx context.Context, srcRepoName, dstRepoName string) error {
+	srcRepo, err := name.NewRepository(srcRepoName)
+	if err != nil {
+		return err
+	}
+	dstRepo, err := name.NewRepository(dstRepoName)
+	if err != nil {
+		return err
+	}
+
+	var tagRegexp *regexp.Regexp
+	if c.opts.TagRegex != "" {
+		tagRegexp, err = regexp.Compile(c.opts.TagRegex)
+		if err != nil {
+			return fmt.Errorf("parsing --tag-regex: %w", err)
+		}
+	}
 
-	remoteOpts := regOpts.GetRegistryClientOpts(ctx)
-	root, err := ociremote.SignedEntity(srcRef, ociremote.WithRemoteOptions(remoteOpts...))
+	tags, err := remote.List(srcRepo, c.remoteOptions(ctx)...)
 	if err != nil {
+		return fmt.Errorf("listing tags of %s: %w", srcRepo, err)
+	}
+
+	refs := make([]copyRef, 0, len(tags))
+	for _, tag := range tags {
+		if attachmentTagRegexp.MatchString(tag) {
+			continue
+		}
+		if tagRegexp != nil && !tagRegexp.MatchString(tag) {
+			continue
+		}
+		refs = append(refs, copyRef{src: srcRepo.Tag(tag), dst: dstRepo.Tag(tag)})
+	}
+	if len(refs) == 0 {
+		fmt.Fprintf(os.Stderr, "No tags to copy in %s\n", srcRepo)
+		return nil
+	}
+	return c.copyAll(ctx, refs)
+}
+
+// copyAll plans the copy of every reference, runs the resulting operations
+// concurrently and, once they all succeeded, updates the destination references.
+func (c *copier) copyAll(ctx context.Context, refs []copyRef) error {
+	var ops, roots []operation
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		p, err := c.plan(ctx, ref)
+		if err != nil {
+			return err
+		}
+		for _, op := range p.ops {
+			if !seen[op.key] {
+				seen[op.key] = true
+				ops = append(ops, op)
+			}
+		}
+		if p.root != nil {
+			roots = append(roots, *p.root)
+		}
+	}
+
+	if err := c.run(ctx, ops); err != nil {
 		return err
 	}
+	// Now that everything has been copied over, update the tags.
+	return c.run(ctx, roots)
+}
+
+// run runs the operations concurrently. Their requests are retried by the
+// transport, according to the --retry-* flags, and the remaining ones are
+// canceled once one fails.
+func (c *copier) run(ctx context.Context, ops []operation) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(c.opts.Jobs)
+	for _, op := range ops {
+		op := op
+		g.Go(func() error {
+			return op.fn(gctx)
+		})
+	}
+	return g.Wait()
+}
+
+// remoteOptions returns the registry options sending their requests with ctx.
+func (c *copier) remoteOptions(ctx context.Context) []remote.Option {
+	return append(c.remoteOpts[:len(c.remoteOpts):len(c.remoteOpts)], remote.WithContext(ctx))
+}
+
+func (c *copier) plan(ctx context.Context, ref copyRef) (*plan, error) {
+	srcRepoRef := ref.src.Context()
+	dstRepoRef := ref.dst.Context()
 
-	if err := walk.SignedEntity(ctx, root, func(ctx context.Context, se oci.SignedEntity) error {
+	root, err := ociremote.SignedEntity(ref.src, ociremote.WithRemoteOptions(c.remoteOptions(ctx)...))
+	if err != nil {
+		return nil, err
+	}
+	rootDigest, err := root.(interface{ Digest() (v1.Hash, error) }).Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	filteredIdx, excluded, err := c.filterPlatforms(root)
+	if err != nil {
+		return nil, err
+	}
+	if filteredIdx != nil && !c.opts.AllowUnsignedIndex {
+		hasSigs, err := hasSignatures(root)
+		if err != nil {
+			return nil, err
+		}
+		if hasSigs {
+			return nil, fmt.Errorf("filtering the platforms of %s changes its digest, its signatures and attestations can't be copied: "+
+				"pass --allow-unsigned-index to copy it anyway, and re-sign the copy", ref.src)
+		}
+	}
+
+	// Fetching the manifests of a large index dominates planning, walk it
+	// concurrently; Collect keeps the operations in a deterministic order.
+	entityOps, err := walk.Collect(ctx, root, func(ctx context.Context, se oci.SignedEntity) (interface{}, error) {
 		// Both of the SignedEntity types implement Digest()
 		h, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if excluded[h] {
+			return []operation(nil), nil
 		}
 		srcDigest := srcRepoRef.Digest(h.String())
 
-		// Copy signatures.
-		if err := copyTagImage(ociremote.SignatureTag, srcDigest, dstRepoRef, force, remoteOpts...); err != nil {
-			return err
+		if h == rootDigest && filteredIdx != nil {
+			// The attachments of the index reference its original digest,
+			// which no longer exists at the destination.
+			fmt.Fprintf(os.Stderr, "WARNING: not copying the attachments of %s, filtering its platforms changes its digest, re-sign the copy\n", srcDigest)
+			return []operation(nil), nil
 		}
-		if sigOnly {
-			return nil
+		ops := c.attachmentOps(srcDigest, dstRepoRef)
+		if c.opts.SignatureOnly {
+			return ops, nil
 		}
 
-		// Copy attestations
-		if err := copyTagImage(ociremote.AttestationTag, srcDigest, dstRepoRef, force, remoteOpts...); err != nil {
-			return err
+		// Copy the entity itself.
+		dstDigest := dstRepoRef.Tag(srcDigest.Identifier())
+		return append(ops, operation{
+			key: "image:" + dstDigest.String(),
+			fn: func(ctx context.Context) error {
+				return copyImage(srcDigest, dstDigest, c.opts.Force, c.remoteOptions(ctx)...)
+			},
+		}), nil
+	}, walk.WithJobs(c.opts.Jobs))
+	if err != nil {
+		return nil, err
+	}
+	p := &plan{}
+	for _, ops := range entityOps {
+		p.ops = append(p.ops, ops.([]operation)...)
+	}
+	if c.opts.SignatureOnly {
+		return p, nil
+	}
+
+	p.root = &operation{
+		key: "tag:" + ref.dst.String(),
+		fn: func(ctx context.Context) error {
+			if filteredIdx != nil {
+				return writeIndex(filteredIdx, ref.dst, c.opts.Force, c.remoteOptions(ctx)...)
+			}
+			return copyImage(srcRepoRef.Digest(rootDigest.String()), ref.dst, c.opts.Force, c.remoteOptions(ctx)...)
+		},
+	}
+	return p, nil
+}
+
+// hasSignatures returns whether se has signatures or attestations.
+func hasSignatures(se oci.SignedEntity) (bool, error) {
+	sigs, err := se.Signatures()
+	if err != nil {
+		return false, err
+	}
+	sl, err := sigs.Get()
+	if err != nil {
+		return false, err
+	}
+	if len(sl) > 0 {
+		return true, nil
+	}
+	atts, err := se.Attestations()
+	if err != nil {
+		return false, err
+	}
+	al, err := atts.Get()
+	if err != nil {
+		return false, err
+	}
+	return len(al) > 0, nil
+}
+
+// filterPlatforms returns the root index without the children that do not
+// match the requested platforms, along with the digests of every entity
+// (transitively) excluded that way. It returns a nil index when nothing has
+// to be filtered.
+func (c *copier) filterPlatforms(root oci.SignedEntity) (v1.ImageIndex, map[v1.Hash]bool, error) {
+	idx, ok := root.(oci.SignedImageIndex)
+	if !ok || len(c.platforms) == 0 {
+		return nil, nil, nil
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	excluded := make(map[v1.Hash]bool)
+	var removed []v1.Hash
+	for _, desc := range im.Manifests {
+		if desc.Platform == nil || c.matchesPlatform(desc.Platform) {
+			continue
+		}
+		removed = append(removed, desc.Digest)
+		excluded[desc.Digest] = true
+		if desc.MediaType.IsIndex() {
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := collectDigests(child, excluded); err != nil {
+				return nil, nil, err
+			}
 		}
+	}
+	if len(removed) == 0 {
+		return nil, nil, nil
+	}
+	return ggcrmutate.RemoveManifests(idx, match.Digests(removed...)), excluded, nil
+}
 
-		// Copy SBOMs
-		if err := copyTagImage(ociremote.SBOMTag, srcDigest, dstRepoRef, force, remoteOpts...); err != nil {
-			return err
+// matchesPlatform returns whether p matches one of the requested platforms.
+// An empty variant in the requested platform matches any variant.
+func (c *copier) matchesPlatform(p *v1.Platform) bool {
+	for _, want := range c.platforms {
+		if want.OS != p.OS || want.Architecture != p.Architecture {
+			continue
+		}
+		if want.Variant == "" || want.Variant == p.Variant {
+			return true
 		}
+	}
+	return false
+}
 
-		// Copy the entity itself.
-		if err := copyImage(srcDigest, dstRepoRef.Tag(srcDigest.Identifier()), force, remoteOpts...); err != nil {
-			return err
+func collectDigests(idx v1.ImageIndex, digests map[v1.Hash]bool) error {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range im.Manifests {
+		digests[desc.Digest] = true
+		if desc.MediaType.IsIndex() {
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := collectDigests(child, digests); err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
 
-		return nil
-	}); err != nil {
+// attachmentOps returns the operations copying the selected attachments of srcDigest.
+func (c *copier) attachmentOps(srcDigest name.Digest, dstRepo name.Repository) []operation {
+	var ops []operation
+	add := func(kind string, fn func(ctx context.Context) error) {
+		ops = append(ops, operation{key: kind + ":" + srcDigest.String() + ">" + dstRepo.String(), fn: fn})
+	}
+
+	if c.attachments[options.AttachmentSignature] {
+		add(options.AttachmentSignature, func(ctx context.Context) error {
+			return copyTagImage(ociremote.SignatureTag, srcDigest, dstRepo, c.opts.Force, c.remoteOptions(ctx)...)
+		})
+	}
+	if c.attachments[options.AttachmentAttestation] {
+		add(options.AttachmentAttestation, func(ctx context.Context) error {
+			if len(c.predicateTypes) > 0 {
+				return c.copyAttestations(ctx, srcDigest, dstRepo)
+			}
+			return copyTagImage(ociremote.AttestationTag, srcDigest, dstRepo, c.opts.Force, c.remoteOptions(ctx)...)
+		})
+	}
+	if c.attachments[options.AttachmentSBOM] {
+		add(options.AttachmentSBOM, func(ctx context.Context) error {
+			return copyTagImage(ociremote.SBOMTag, srcDigest, dstRepo, c.opts.Force, c.remoteOptions(ctx)...)
+		})
+	}
+	if c.attachments[options.AttachmentFiles] {
+		add(options.AttachmentFiles, func(ctx context.Context) error {
+			names, err := ociremote.AttachmentNames(srcDigest, ociremote.WithRemoteOptions(c.remoteOptions(ctx)...))
+			if err != nil {
+				return err
+			}
+			for _, attName := range names {
+				switch attName {
+				case ociremote.SignatureTagSuffix, ociremote.AttestationTagSuffix, ociremote.SBOMTagSuffix:
+					continue
+				}
+				if err := c.copyFile(ctx, srcDigest, dstRepo, attName); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	var names []string
+	for a := range c.attachments {
+		switch a {
+		case options.AttachmentSignature, options.AttachmentAttestation, options.AttachmentSBOM, options.AttachmentFiles:
+			continue
+		}
+		names = append(names, a)
+	}
+	sort.Strings(names)
+	for _, a := range names {
+		attName := a
+		add("file:"+attName, func(ctx context.Context) error {
+			return c.copyFile(ctx, srcDigest, dstRepo, attName)
+		})
+	}
+	return ops
+}
+
+// copyFile copies the file attachment attName of srcDigest along with its
+// own signatures.
+func (c *copier) copyFile(ctx context.Context, srcDigest name.Digest, dstRepo name.Repository, attName string) error {
+	remoteOpts := c.remoteOptions(ctx)
+	src, err := ociremote.AttachmentTag(srcDigest, attName, ociremote.WithRemoteOptions(remoteOpts...))
+	if err != nil {
+		return err
+	}
+	if err := copyImage(src, dstRepo.Tag(src.Identifier()), c.opts.Force, remoteOpts...); err != nil {
+		return err
+	}
+	desc, err := remote.Head(src, remoteOpts...)
+	if err != nil {
+		var te *transport.Error
+		if errors.As(err, &te) && te.StatusCode == http.StatusNotFound {
+			return nil
+		}
 		return err
 	}
-	if sigOnly {
+	return copyTagImage(ociremote.SignatureTag, src.Context().Digest(desc.Digest.String()), dstRepo, c.opts.Force, remoteOpts...)
+}
+
+// copyAttestations copies the attestations of srcDigest whose predicate type
+// was requested.
+func (c *copier) copyAttestations(ctx context.Context, srcDigest name.Digest, dstRepo name.Repository) error {
+	remoteOpts := c.remoteOptions(ctx)
+	ociremoteOpts := ociremote.WithRemoteOptions(remoteOpts...)
+	src, err := ociremote.AttestationTag(srcDigest, ociremoteOpts)
+	if err != nil {
+		return err
+	}
+	atts, err := ociremote.Signatures(src, ociremoteOpts)
+	if err != nil {
+		return err
+	}
+	all, err := atts.Get()
+	if err != nil {
+		return err
+	}
+
+	var kept []oci.Signature
+	for _, att := range all {
+		statement, err := attestation.StatementFromAttestation(att)
+		if err != nil {
+			return fmt.Errorf("reading attestation of %s: %w", srcDigest, err)
+		}
+		if c.predicateTypes[statement.PredicateType] {
+			kept = append(kept, att)
+		}
+	}
+	if len(kept) == 0 {
 		return nil
 	}
+	if len(kept) < len(all) {
+		if atts, err = mutate.AppendSignatures(empty.Signatures(), kept...); err != nil {
+			return err
+		}
+	}
 
-	// Now that everything has been copied over, update the tag.
-	h, err := root.(interface{ Digest() (v1.Hash, error) }).Digest()
+	h, err := atts.Digest()
 	if err != nil {
 		return err
 	}
-	return copyImage(srcRepoRef.Digest(h.String()), dstRef, force, remoteOpts...)
+	dest := dstRepo.Tag(src.Identifier())
+	if done, err := checkDestination(h, dest, c.opts.Force, remoteOpts...); done || err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Copying %d attestation(s) from %s to %s...\n", len(kept), src, dest)
+	return remote.Write(dest, atts, remoteOpts...)
 }
 
-func descriptorsEqual(a, b *v1.Descriptor) bool {
-	if a == nil || b == nil {
-		return a == nil && b == nil
+// checkDestination returns true if dest already holds the manifest with digest h,
+// so there is nothing left to copy. It fails if dest holds something else and
+// overwrite is not set.
+func checkDestination(h v1.Hash, dest name.Reference, overwrite bool, opts ...remote.Option) (bool, error) {
+	dstDesc, err := remote.Head(dest, opts...)
+	if err != nil {
+		// Most likely the destination does not exist yet, if not the
+		// error will surface when writing to it.
+		return false, nil
+	}
+	if h == dstDesc.Digest {
+		return true, nil
+	}
+	if !overwrite {
+		return false, fmt.Errorf("image %q already exists. Use `-f` to overwrite", dest.Name())
 	}
-	return a.Digest == b.Digest
+	return false, nil
 }
 
 type tagMap func(name.Reference, ...ociremote.Option) (name.Tag, error)
@@ -130,13 +595,10 @@ func copyImage(src, dest name.Reference, overwrite bool, opts ...remote.Option)
 		return err
 	}
 
-	if !overwrite {
-		if dstDesc, err := remote.Head(dest, opts...); err == nil {
-			if descriptorsEqual(&got.Descriptor, dstDesc) {
-				return nil
-			}
-			return fmt.Errorf("image %q already exists. Use `-f` to overwrite", dest.Name())
-		}
+	// Manifests already present at the destination are skipped, blobs
+	// already present are skipped by remote.Write itself.
+	if done, err := checkDestination(got.Digest, dest, overwrite, opts...); done || err != nil {
+		return err
 	}
 
 	fmt.Fprintf(os.Stderr, "Copying %s to %s...\n", src, dest)
@@ -154,3 +616,16 @@ func copyImage(src, dest name.Reference, overwrite bool, opts ...remote.Option)
 	}
 	return remote.Write(dest, img, opts...)
 }
+
+// writeIndex writes the (platform filtered) index to dest, unless it is already there.
+func writeIndex(idx v1.ImageIndex, dest name.Reference, overwrite bool, opts ...remote.Option) error {
+	h, err := idx.Digest()
+	if err != nil {
+		return err
+	}
+	if done, err := checkDestination(h, dest, overwrite, opts...); done || err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Writing filtered index to %s...\n", dest)
+	return remote.WriteIndex(dest, idx, opts...)
+}
@@ -0,0 +1,467 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copy
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/oci/signed"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+func TestAttachmentTagRegexp(t *testing.T) {
+	hex := strings.Repeat("a", 64)
+	tests := map[string]bool{
+		"latest":                      false,
+		"v1.2.3":                      false,
+		"sha256-" + hex + ".sig":      true,
+		"sha256-" + hex + ".att":      true,
+		"sha256-" + hex + ".sbom":     true,
+		"sha256-" + hex:               true,
+		"prefix-sha256-" + hex + ".x": true,
+		"sha256-abc.sig":              false,
+	}
+	for tag, want := range tests {
+		if got := attachmentTagRegexp.MatchString(tag); got != want {
+			t.Errorf("attachmentTagRegexp.MatchString(%q) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestNewCopier(t *testing.T) {
+	base := func() options.CopyOptions {
+		return options.CopyOptions{
+			Attachments: []string{options.AttachmentSignature, options.AttachmentAttestation, options.AttachmentSBOM},
+			Jobs:        4,
+		}
+	}
+	tests := []struct {
+		name    string
+		mutate  func(*options.CopyOptions)
+		wantErr bool
+	}{{
+		name:   "defaults",
+		mutate: func(*options.CopyOptions) {},
+	}, {
+		name:    "invalid attachment",
+		mutate:  func(o *options.CopyOptions) { o.Attachments = []string{"foo/bar"} },
+		wantErr: true,
+	}, {
+		name:   "file attachments",
+		mutate: func(o *options.CopyOptions) { o.Attachments = []string{options.AttachmentFiles, "release-notes"} },
+	}, {
+		name:   "predicate type name",
+		mutate: func(o *options.CopyOptions) { o.AttestationTypes = []string{"slsaprovenance"} },
+	}, {
+		name:    "invalid predicate type",
+		mutate:  func(o *options.CopyOptions) { o.AttestationTypes = []string{"not a uri"} },
+		wantErr: true,
+	}, {
+		name:    "invalid platform",
+		mutate:  func(o *options.CopyOptions) { o.Platforms = []string{"linux/amd64/v2/extra"} },
+		wantErr: true,
+	}, {
+		name:    "no jobs",
+		mutate:  func(o *options.CopyOptions) { o.Jobs = 0 },
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := base()
+			tt.mutate(&o)
+			if _, err := newCopier(context.Background(), o); (err != nil) != tt.wantErr {
+				t.Errorf("newCopier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignatureOnly(t *testing.T) {
+	c, err := newCopier(context.Background(), options.CopyOptions{
+		SignatureOnly: true,
+		Attachments:   []string{options.AttachmentAttestation, options.AttachmentSBOM},
+		Jobs:          1,
+	})
+	if err != nil {
+		t.Fatalf("newCopier() = %v", err)
+	}
+	if !c.attachments[options.AttachmentSignature] || len(c.attachments) != 1 {
+		t.Errorf("attachments = %v, want only signatures", c.attachments)
+	}
+}
+
+func TestMatchesPlatform(t *testing.T) {
+	c, err := newCopier(context.Background(), options.CopyOptions{
+		Platforms: []string{"linux/amd64", "linux/arm/v7"},
+		Jobs:      1,
+	})
+	if err != nil {
+		t.Fatalf("newCopier() = %v", err)
+	}
+	tests := []struct {
+		platform v1.Platform
+		want     bool
+	}{
+		{v1.Platform{OS: "linux", Architecture: "amd64"}, true},
+		{v1.Platform{OS: "linux", Architecture: "amd64", Variant: "v3"}, true},
+		{v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, true},
+		{v1.Platform{OS: "linux", Architecture: "arm", Variant: "v6"}, false},
+		{v1.Platform{OS: "linux", Architecture: "arm64"}, false},
+		{v1.Platform{OS: "windows", Architecture: "amd64"}, false},
+	}
+	for _, tt := range tests {
+		if got := c.matchesPlatform(&tt.platform); got != tt.want {
+			t.Errorf("matchesPlatform(%s) = %v, want %v", tt.platform, got, tt.want)
+		}
+	}
+}
+
+// newRegistry starts an in-memory registry, and returns its host along with
+// the number of manifests written to it.
+func newRegistry(t *testing.T) (string, *int32) {
+	t.Helper()
+	var manifests int32
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/") {
+			atomic.AddInt32(&manifests, 1)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host, &manifests
+}
+
+// sign writes a signature of se to repo, along with an attestation of each
+// of the predicate types.
+func sign(t *testing.T, repo name.Repository, se oci.SignedEntity, predicateTypes ...string) {
+	t.Helper()
+	sig, err := static.NewSignature([]byte("payload"), base64.StdEncoding.EncodeToString([]byte("signature")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if se, err = mutate.AttachSignatureToEntity(se, sig); err != nil {
+		t.Fatal(err)
+	}
+	for _, predicateType := range predicateTypes {
+		statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":%q,"subject":[],"predicate":{}}`, predicateType)
+		envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+			base64.StdEncoding.EncodeToString([]byte(statement)))
+		att, err := static.NewAttestation([]byte(envelope))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if se, err = mutate.AttachAttestationToEntity(se, att); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ociremote.WriteSignatures(repo, se); err != nil {
+		t.Fatal(err)
+	}
+	if len(predicateTypes) > 0 {
+		if err := ociremote.WriteAttestations(repo, se); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// pushSignedImage writes a random image signed with attestations of the
+// predicate types to ref.
+func pushSignedImage(t *testing.T, ref name.Reference, predicateTypes ...string) v1.Image {
+	t.Helper()
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	sign(t, ref.Context(), signed.Image(img), predicateTypes...)
+	return img
+}
+
+func parseRef(t *testing.T, s string) name.Reference {
+	t.Helper()
+	ref, err := name.ParseReference(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func digestOf(t *testing.T, d interface{ Digest() (v1.Hash, error) }) v1.Hash {
+	t.Helper()
+	h, err := d.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func listTags(t *testing.T, repo name.Repository) []string {
+	t.Helper()
+	tags, err := remote.List(repo)
+	if err != nil {
+		t.Fatalf("remote.List(%s) = %v", repo, err)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func copyOptions(mutate func(*options.CopyOptions)) options.CopyOptions {
+	o := options.CopyOptions{
+		Attachments: []string{options.AttachmentSignature, options.AttachmentAttestation, options.AttachmentSBOM},
+		Jobs:        4,
+	}
+	mutate(&o)
+	return o
+}
+
+func TestRunCancelsOnFailure(t *testing.T) {
+	c := &copier{opts: options.CopyOptions{Jobs: 1}}
+	var canceled bool
+	err := c.run(context.Background(), []operation{{
+		key: "fails",
+		fn: func(context.Context) error {
+			return fmt.Errorf("copy failed")
+		},
+	}, {
+		key: "queued",
+		fn: func(ctx context.Context) error {
+			canceled = ctx.Err() != nil
+			return ctx.Err()
+		},
+	}})
+	if err == nil || err.Error() != "copy failed" {
+		t.Errorf("run() = %v, wanted the error of the failed operation", err)
+	}
+	if !canceled {
+		t.Error("run() didn't cancel the operation queued after a failure")
+	}
+}
+
+func TestCopyRepository(t *testing.T) {
+	host, _ := newRegistry(t)
+	src, dst := host+"/src", host+"/dst"
+	images := map[string]v1.Image{}
+	for _, tag := range []string{"v1", "v2", "nightly"} {
+		images[tag] = pushSignedImage(t, parseRef(t, src+":"+tag))
+	}
+	sigTag := func(tag string) string {
+		return strings.Replace(digestOf(t, images[tag]).String(), ":", "-", 1) + ".sig"
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*options.CopyOptions)
+		want   []string
+	}{{
+		name:   "all tags",
+		mutate: func(o *options.CopyOptions) { o.AllTags = true },
+		want:   []string{"nightly", "v1", "v2"},
+	}, {
+		name:   "tag regex",
+		mutate: func(o *options.CopyOptions) { o.TagRegex = "^v[0-9]+$" },
+		want:   []string{"v1", "v2"},
+	}}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := fmt.Sprintf("%s%d", dst, i)
+			if err := CopyCmd(context.Background(), copyOptions(tt.mutate), src, dst); err != nil {
+				t.Fatalf("CopyCmd() = %v", err)
+			}
+			var want []string
+			for _, tag := range tt.want {
+				want = append(want, tag, sigTag(tag))
+			}
+			sort.Strings(want)
+			dstRepo := parseRef(t, dst).Context()
+			if got := listTags(t, dstRepo); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("tags = %v, want %v", got, want)
+			}
+			for _, tag := range tt.want {
+				desc, err := remote.Head(dstRepo.Tag(tag))
+				if err != nil {
+					t.Fatalf("remote.Head(%s) = %v", tag, err)
+				}
+				if desc.Digest != digestOf(t, images[tag]) {
+					t.Errorf("%s has digest %s, want %s", tag, desc.Digest, digestOf(t, images[tag]))
+				}
+			}
+		})
+	}
+}
+
+func TestCopyAttestationTypes(t *testing.T) {
+	host, _ := newRegistry(t)
+	src, dst := host+"/src:v1", host+"/dst:v1"
+	img := pushSignedImage(t, parseRef(t, src), attestation.CosignVulnProvenanceV01, "https://slsa.dev/provenance/v0.2")
+
+	o := copyOptions(func(o *options.CopyOptions) { o.AttestationTypes = []string{"vuln"} })
+	if err := CopyCmd(context.Background(), o, src, dst); err != nil {
+		t.Fatalf("CopyCmd() = %v", err)
+	}
+
+	dstRef := parseRef(t, dst)
+	se, err := ociremote.SignedEntity(dstRef.Context().Digest(digestOf(t, img).String()))
+	if err != nil {
+		t.Fatalf("SignedEntity() = %v", err)
+	}
+	atts, err := se.Attestations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := atts.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("copied %d attestations, want 1", len(got))
+	}
+	statement, err := attestation.StatementFromAttestation(got[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statement.PredicateType != attestation.CosignVulnProvenanceV01 {
+		t.Errorf("copied a %s attestation, want %s", statement.PredicateType, attestation.CosignVulnProvenanceV01)
+	}
+	sigs, err := se.Signatures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sl, err := sigs.Get(); err != nil || len(sl) != 1 {
+		t.Errorf("copied signatures = %v, %v, want 1", sl, err)
+	}
+}
+
+func TestCopyPlatforms(t *testing.T) {
+	host, _ := newRegistry(t)
+	src, dst := host+"/src:v1", host+"/dst:v1"
+	srcRef := parseRef(t, src)
+	children := map[string]v1.Image{}
+	idx := v1.ImageIndex(empty.Index)
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(256, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		children[arch] = img
+		idx = ggcrmutate.AppendManifests(idx, ggcrmutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+	}
+	if err := remote.WriteIndex(srcRef, idx); err != nil {
+		t.Fatal(err)
+	}
+	for _, img := range children {
+		sign(t, srcRef.Context(), signed.Image(img))
+	}
+	sign(t, srcRef.Context(), signed.ImageIndex(idx))
+
+	// The signatures of the index don't survive filtering its platforms.
+	o := copyOptions(func(o *options.CopyOptions) { o.Platforms = []string{"linux/amd64"} })
+	if err := CopyCmd(context.Background(), o, src, dst); err == nil || !strings.Contains(err.Error(), "--allow-unsigned-index") {
+		t.Fatalf("CopyCmd() of a signed index = %v, want an error asking for --allow-unsigned-index", err)
+	}
+	dstRef := parseRef(t, dst)
+	if _, err := remote.Head(dstRef); err == nil {
+		t.Error("CopyCmd() of a signed index copied it")
+	}
+
+	o.AllowUnsignedIndex = true
+	if err := CopyCmd(context.Background(), o, src, dst); err != nil {
+		t.Fatalf("CopyCmd() = %v", err)
+	}
+
+	copied, err := remote.Index(dstRef)
+	if err != nil {
+		t.Fatalf("remote.Index() = %v", err)
+	}
+	im, err := copied.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(im.Manifests) != 1 || im.Manifests[0].Digest != digestOf(t, children["amd64"]) {
+		t.Errorf("copied index manifests = %v, want only the linux/amd64 image", im.Manifests)
+	}
+	for arch, want := range map[string]bool{"amd64": true, "arm64": false} {
+		h := digestOf(t, children[arch])
+		sigTag := dstRef.Context().Tag(strings.Replace(h.String(), ":", "-", 1) + ".sig")
+		if _, err := remote.Head(sigTag); (err == nil) != want {
+			t.Errorf("signature of the %s image copied = %v, want %v", arch, err == nil, want)
+		}
+		if _, err := remote.Head(dstRef.Context().Digest(h.String())); (err == nil) != want {
+			t.Errorf("%s image copied = %v, want %v", arch, err == nil, want)
+		}
+	}
+}
+
+func TestCopySkipsExisting(t *testing.T) {
+	host, manifests := newRegistry(t)
+	src, dst := host+"/src:v1", host+"/dst:v1"
+	pushSignedImage(t, parseRef(t, src))
+	o := copyOptions(func(*options.CopyOptions) {})
+
+	if err := CopyCmd(context.Background(), o, src, dst); err != nil {
+		t.Fatalf("CopyCmd() = %v", err)
+	}
+	written := atomic.LoadInt32(manifests)
+	if err := CopyCmd(context.Background(), o, src, dst); err != nil {
+		t.Fatalf("CopyCmd() again = %v", err)
+	}
+	if got := atomic.LoadInt32(manifests); got != written {
+		t.Errorf("copying again wrote %d manifests, want none", got-written)
+	}
+
+	// Another image at the destination is only overwritten with --force.
+	other := pushSignedImage(t, parseRef(t, host+"/dst:v2"))
+	if err := remote.Write(parseRef(t, dst), other); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyCmd(context.Background(), o, src, dst); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("CopyCmd() = %v, want an error about the existing image", err)
+	}
+	o.Force = true
+	if err := CopyCmd(context.Background(), o, src, dst); err != nil {
+		t.Errorf("CopyCmd() with --force = %v", err)
+	}
+}
@@ -99,6 +99,8 @@ Shell-like variables in the Dockerfile's FROM lines will be substituted with val
 					RekorURL:        o.Rekor.URL,
 					Attachment:      o.Attachment,
 					Annotations:     annotations,
+					Policies:        o.Policies,
+					RegoOptions:     regoOptions(o.Rego),
 				},
 				BaseOnly: o.BaseImageOnly,
 			}
@@ -35,6 +35,7 @@ func Download() *cobra.Command {
 		downloadSignature(),
 		downloadSBOM(),
 		downloadAttestation(),
+		downloadFile(),
 	)
 
 	return cmd
@@ -67,7 +68,7 @@ func downloadSBOM() *cobra.Command {
 		Example: "  cosign download sbom <image uri>",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Fprintln(os.Stderr, "WARNING: Downloading SBOMs this way does not ensure its authenticity. If you want to ensure a tamper-proof SBOM, download it using 'cosign download attestation <image uri>' or verify its signature.")
+			fmt.Fprintln(os.Stderr, "WARNING: Downloading SBOMs this way does not ensure its authenticity. If you want to ensure a tamper-proof SBOM, download it using 'cosign download attestation <image uri>' or verify its signature using 'cosign verify-sbom <image uri>'.")
 			_, err := download.SBOMCmd(cmd.Context(), *o, args[0], cmd.OutOrStdout())
 			return err
 		},
@@ -95,3 +96,21 @@ func downloadAttestation() *cobra.Command {
 
 	return cmd
 }
+
+func downloadFile() *cobra.Command {
+	o := &options.DownloadFileOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "file",
+		Short:   "Download a named file attached to the supplied container image",
+		Example: "  cosign download file --name <name> <image uri>",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return download.FileCmd(cmd.Context(), o.Registry, args[0], o.Name, cmd.OutOrStdout())
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+)
+
+// FileCmd writes the content of the attachment named attName to out.
+func FileCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef, attName string, out io.Writer) error {
+	if err := options.ValidateAttachmentName(attName); err != nil {
+		return err
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return err
+	}
+
+	ociremoteOpts, err := regOpts.ClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+
+	se, err := ociremote.SignedEntity(ref, ociremoteOpts...)
+	if err != nil {
+		return err
+	}
+
+	file, err := se.Attachment(attName)
+	if err != nil {
+		return err
+	}
+
+	mt, err := file.FileMediaType()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Found %s file of media type: %s\n", attName, mt)
+
+	payload, err := file.Payload()
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(payload)
+	return err
+}
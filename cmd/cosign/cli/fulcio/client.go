@@ -0,0 +1,142 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fulcio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/sigstore/fulcio/pkg/api"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/retry"
+)
+
+// The endpoints of the Fulcio API.
+const (
+	signingCertPath = "/api/v1/signingCert"
+	rootCertPath    = "/api/v1/rootCert"
+)
+
+// retryClient is the Fulcio API client of api.NewClient, with its own HTTP
+// client sending the requests through the retry policy: the upstream client
+// has no option to set its transport.
+type retryClient struct {
+	baseURL *url.URL
+	client  *http.Client
+}
+
+var _ api.Client = (*retryClient)(nil)
+
+// newRetryClient returns a Fulcio client sending its requests through the
+// retry policy. The certificate requests are not idempotent, each one issues a
+// certificate: they are only retried when Fulcio tells it didn't process them.
+func newRetryClient(fulcioServer *url.URL) api.Client {
+	return &retryClient{
+		baseURL: fulcioServer,
+		client: &http.Client{
+			Transport: &userAgentTransport{
+				RoundTripper: retry.NewTransport(http.DefaultTransport, retry.Default()),
+				userAgent:    options.UserAgent(),
+			},
+		},
+	}
+}
+
+// SigningCert implements api.Client
+func (c *retryClient) SigningCert(cr api.CertificateRequest, token string) (*api.CertificateResponse, error) {
+	endpoint := *c.baseURL
+	endpoint.Path = path.Join(endpoint.Path, signingCertPath)
+
+	b, err := json.Marshal(cr)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Fulcio returns a 201 Created on success, the body is the error
+	// otherwise.
+	if resp.StatusCode != http.StatusCreated {
+		return nil, errors.New(string(body))
+	}
+
+	sct, err := base64.StdEncoding.DecodeString(resp.Header.Get("SCT"))
+	if err != nil {
+		return nil, err
+	}
+	// Split the cert and the chain
+	certBlock, chainPem := pem.Decode(body)
+	if certBlock == nil {
+		return nil, errors.New("did not find a cert from Fulcio")
+	}
+	return &api.CertificateResponse{
+		CertPEM:  pem.EncodeToMemory(certBlock),
+		ChainPEM: chainPem,
+		SCT:      sct,
+	}, nil
+}
+
+// RootCert implements api.Client
+func (c *retryClient) RootCert() (*api.RootResponse, error) {
+	endpoint := *c.baseURL
+	endpoint.Path = path.Join(endpoint.Path, rootCertPath)
+
+	resp, err := c.client.Get(endpoint.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(string(body))
+	}
+	return &api.RootResponse{ChainPEM: body}, nil
+}
+
+// userAgentTransport sets the User-Agent of the requests it sends.
+type userAgentTransport struct {
+	http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.RoundTripper.RoundTrip(req)
+}
@@ -199,6 +199,5 @@ func NewClient(fulcioURL string) (api.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	fClient := api.NewClient(fulcioServer, api.WithUserAgent(options.UserAgent()))
-	return fClient, nil
+	return newRetryClient(fulcioServer), nil
 }
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
@@ -173,3 +174,32 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("no requests were received")
 	}
 }
+
+func TestNewClientRetries(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch atomic.AddInt32(&calls, 1) {
+			case 1:
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			default:
+				// Certificate requests are not idempotent, they aren't
+				// retried on errors which may have issued one.
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+	defer testServer.Close()
+
+	client, err := NewClient(testServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SigningCert(api.CertificateRequest{}, ""); err == nil {
+		t.Error("SigningCert() succeeded, wanted an error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("server called %d times, wanted 2", got)
+	}
+}
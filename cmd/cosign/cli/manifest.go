@@ -94,6 +94,8 @@ against the transparency log.`,
 					RekorURL:        o.Rekor.URL,
 					Attachment:      o.Attachment,
 					Annotations:     annotations,
+					Policies:        o.Policies,
+					RegoOptions:     regoOptions(o.Rego),
 				},
 			}
 			return v.Exec(cmd.Context(), args)
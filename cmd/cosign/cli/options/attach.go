@@ -17,6 +17,7 @@ package options
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/v1/types"
@@ -51,6 +52,16 @@ type AttachSBOMOptions struct {
 	SBOMType        string
 	SBOMInputFormat string
 	Registry        RegistryOptions
+
+	// The options below are only used with --sign.
+	Sign        bool
+	Key         string
+	Cert        string
+	CertChain   string
+	Rekor       RekorOptions
+	Fulcio      FulcioOptions
+	OIDC        OIDCOptions
+	SecurityKey SecurityKeyOptions
 }
 
 var _ Interface = (*AttachSBOMOptions)(nil)
@@ -58,6 +69,23 @@ var _ Interface = (*AttachSBOMOptions)(nil)
 // AddFlags implements Interface
 func (o *AttachSBOMOptions) AddFlags(cmd *cobra.Command) {
 	o.Registry.AddFlags(cmd)
+	o.Rekor.AddFlags(cmd)
+	o.Fulcio.AddFlags(cmd)
+	o.OIDC.AddFlags(cmd)
+	o.SecurityKey.AddFlags(cmd)
+
+	cmd.Flags().BoolVar(&o.Sign, "sign", false,
+		"validate the SBOM against its type and sign it once attached")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret, used with --sign")
+
+	cmd.Flags().StringVar(&o.Cert, "certificate", "",
+		"path to the X.509 certificate in PEM format to include in the OCI Signature, used with --sign")
+
+	cmd.Flags().StringVar(&o.CertChain, "certificate-chain", "",
+		"path to a list of CA X.509 certificates in PEM format which will be needed "+
+			"when building the certificate chain for the signing certificate, used with --sign")
 
 	cmd.Flags().StringVar(&o.SBOM, "sbom", "",
 		"path to the sbom, or {-} for stdin")
@@ -115,3 +143,46 @@ func (o *AttachAttestationOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Attestation, "attestation", "",
 		"path to the attestation envelope")
 }
+
+// AttachFileOptions is the top level wrapper for the attach file command.
+type AttachFileOptions struct {
+	File      string
+	Name      string
+	MediaType string
+	Registry  RegistryOptions
+}
+
+var _ Interface = (*AttachFileOptions)(nil)
+
+// AddFlags implements Interface
+func (o *AttachFileOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.File, "file", "",
+		"path to the file to attach, or {-} for stdin")
+	_ = cmd.MarkFlagRequired("file")
+
+	cmd.Flags().StringVar(&o.Name, "name", "",
+		"name of the attachment, used as the suffix of the attachment tag")
+	_ = cmd.MarkFlagRequired("name")
+
+	cmd.Flags().StringVar(&o.MediaType, "media-type", "application/octet-stream",
+		"media type of the attached file")
+}
+
+// attachmentNameRegexp matches the names which are valid as the suffix of a tag.
+var attachmentNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,63}$`)
+
+// ValidateAttachmentName checks that name can be used for a file attachment:
+// it must be usable in a tag and must not collide with the signatures,
+// attestations or SBOMs attached to an image.
+func ValidateAttachmentName(name string) error {
+	switch name {
+	case AttachmentSignature, AttachmentAttestation, AttachmentSBOM:
+		return fmt.Errorf("attachment name %q is reserved", name)
+	}
+	if !attachmentNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid attachment name %q, expected at most 64 letters, digits, '_', '.' or '-'", name)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import "github.com/spf13/cobra"
 type CleanOptions struct {
 	Registry  RegistryOptions
 	CleanType string
+	Name      string
 	Force     bool
 }
 
@@ -26,6 +27,7 @@ var _ Interface = (*CleanOptions)(nil)
 
 func (c *CleanOptions) AddFlags(cmd *cobra.Command) {
 	c.Registry.AddFlags(cmd)
-	cmd.Flags().StringVarP(&c.CleanType, "type", "", "all", "a type of clean: <signature|attestation|sbom|all> (default: all)")
+	cmd.Flags().StringVarP(&c.CleanType, "type", "", "all", "a type of clean: <signature|attestation|sbom|file|all> (default: all)")
+	cmd.Flags().StringVar(&c.Name, "name", "", "the name of the file attachment to remove with --type file (default: every file attachment)")
 	cmd.Flags().BoolVarP(&c.Force, "force", "f", false, "do not prompt for confirmation")
 }
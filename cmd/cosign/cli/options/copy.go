@@ -19,11 +19,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	AttachmentSignature   = "sig"
+	AttachmentAttestation = "att"
+	AttachmentSBOM        = "sbom"
+	// AttachmentFiles selects every named file attachment.
+	AttachmentFiles = "files"
+)
+
 // CopyOptions is the top level wrapper for the copy command.
 type CopyOptions struct {
-	SignatureOnly bool
-	Force         bool
-	Registry      RegistryOptions
+	SignatureOnly      bool
+	Force              bool
+	AllTags            bool
+	TagRegex           string
+	Attachments        []string
+	AttestationTypes   []string
+	Platforms          []string
+	AllowUnsignedIndex bool
+	Jobs               int
+	Registry           RegistryOptions
 }
 
 var _ Interface = (*CopyOptions)(nil)
@@ -37,4 +52,32 @@ func (o *CopyOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().BoolVarP(&o.Force, "force", "f", false,
 		"overwrite destination image(s), if necessary")
+
+	cmd.Flags().BoolVar(&o.AllTags, "all-tags", false,
+		"copy every tag of the source repository to the destination repository")
+
+	cmd.Flags().StringVar(&o.TagRegex, "tag-regex", "",
+		"copy the tags of the source repository matching this regular expression to the destination repository")
+
+	cmd.Flags().StringSliceVar(&o.Attachments, "attachments", []string{AttachmentSignature, AttachmentAttestation, AttachmentSBOM},
+		"comma separated list of attachments to copy along with the image: <sig|att|sbom|files> or the names of file attachments")
+
+	cmd.Flags().StringSliceVar(&o.AttestationTypes, "attestation-type", nil,
+		"only copy attestations with these predicate types (slsaprovenance|slsaprovenance1|link|spdx|vuln|openvex|cyclonedx|custom) or URIs, may be repeated")
+
+	cmd.Flags().StringSliceVar(&o.Platforms, "platform", nil,
+		"only copy the manifests of an image index matching these platforms (e.g. linux/amd64), may be repeated. "+
+			"Filtering an index changes its digest: its signatures and attestations can't be copied, and the copy has to be re-signed")
+
+	cmd.Flags().BoolVar(&o.AllowUnsignedIndex, "allow-unsigned-index", false,
+		"with --platform, copy the filtered index even though its signatures and attestations are dropped")
+
+	cmd.Flags().IntVar(&o.Jobs, "jobs", 4,
+		"the maximum number of concurrent copy operations")
+}
+
+// AllRepository returns whether the copy command operates on every
+// (or every matching) tag of a repository instead of a single image.
+func (o *CopyOptions) AllRepository() bool {
+	return o.AllTags || o.TagRegex != ""
 }
@@ -80,3 +80,54 @@ func (o *PolicySignOptions) AddFlags(cmd *cobra.Command) {
 	o.Rekor.AddFlags(cmd)
 	o.OIDC.AddFlags(cmd)
 }
+
+// PolicyTestOptions is the top level wrapper for the policy-test command.
+type PolicyTestOptions struct {
+	Output  string
+	Explain string
+}
+
+var _ Interface = (*PolicyTestOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PolicyTestOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Output, "output", "text",
+		"output format of the results (text|json|junit)")
+
+	cmd.Flags().StringVar(&o.Explain, "explain", "",
+		"explain why the policies fail the test cases: the conflicting values of CUE policies, and the evaluation trace of Rego policies (notes|fails|full)")
+	cmd.Flags().Lookup("explain").NoOptDefVal = "fails"
+}
+
+// PolicyPushOptions is the top level wrapper for the policy-push command.
+type PolicyPushOptions struct {
+	Type  string
+	Key   string
+	Force bool
+
+	Registry    RegistryOptions
+	Fulcio      FulcioOptions
+	Rekor       RekorOptions
+	OIDC        OIDCOptions
+	SecurityKey SecurityKeyOptions
+}
+
+var _ Interface = (*PolicyPushOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PolicyPushOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Type, "type", "",
+		"type of the policy (cue|rego|jsonschema|cel), detected from the extension of the policy file by default")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret")
+
+	cmd.Flags().BoolVarP(&o.Force, "force", "f", false,
+		"skip warnings and confirmations")
+
+	o.Registry.AddFlags(cmd)
+	o.Fulcio.AddFlags(cmd)
+	o.Rekor.AddFlags(cmd)
+	o.OIDC.AddFlags(cmd)
+	o.SecurityKey.AddFlags(cmd)
+}
@@ -28,20 +28,26 @@ import (
 )
 
 const (
-	PredicateCustom = "custom"
-	PredicateSLSA   = "slsaprovenance"
-	PredicateSPDX   = "spdx"
-	PredicateLink   = "link"
-	PredicateVuln   = "vuln"
+	PredicateCustom    = "custom"
+	PredicateSLSA      = "slsaprovenance"
+	PredicateSLSA1     = "slsaprovenance1"
+	PredicateSPDX      = "spdx"
+	PredicateLink      = "link"
+	PredicateVuln      = "vuln"
+	PredicateOpenVEX   = "openvex"
+	PredicateCycloneDX = "cyclonedx"
 )
 
 // PredicateTypeMap is the mapping between the predicate `type` option to predicate URI.
 var PredicateTypeMap = map[string]string{
-	PredicateCustom: attestation.CosignCustomProvenanceV01,
-	PredicateSLSA:   slsa.PredicateSLSAProvenance,
-	PredicateSPDX:   in_toto.PredicateSPDX,
-	PredicateLink:   in_toto.PredicateLinkV1,
-	PredicateVuln:   attestation.CosignVulnProvenanceV01,
+	PredicateCustom:    attestation.CosignCustomProvenanceV01,
+	PredicateSLSA:      slsa.PredicateSLSAProvenance,
+	PredicateSLSA1:     attestation.PredicateSLSAProvenanceV1,
+	PredicateSPDX:      in_toto.PredicateSPDX,
+	PredicateLink:      in_toto.PredicateLinkV1,
+	PredicateVuln:      attestation.CosignVulnProvenanceV01,
+	PredicateOpenVEX:   attestation.PredicateOpenVEX,
+	PredicateCycloneDX: attestation.PredicateCycloneDX,
 }
 
 // PredicateOptions is the wrapper for predicate related options.
@@ -54,7 +60,7 @@ var _ Interface = (*PredicateOptions)(nil)
 // AddFlags implements Interface
 func (o *PredicateOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Type, "type", "custom",
-		"specify a predicate type (slsaprovenance|link|spdx|vuln|custom) or an URI")
+		"specify a predicate type (slsaprovenance|slsaprovenance1|link|spdx|vuln|openvex|cyclonedx|custom) or an URI")
 }
 
 // ParsePredicateType parses the predicate `type` flag passed into a predicate URI, or validates `type` is a valid URI.
@@ -72,7 +78,10 @@ func ParsePredicateType(t string) (string, error) {
 // PredicateLocalOptions is the wrapper for predicate related options.
 type PredicateLocalOptions struct {
 	PredicateOptions
-	Path string
+	Path     string
+	Format   string
+	ScanTime string
+	Schema   string
 }
 
 var _ Interface = (*PredicateLocalOptions)(nil)
@@ -83,6 +92,15 @@ func (o *PredicateLocalOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.Path, "predicate", "",
 		"path to the predicate file.")
+
+	cmd.Flags().StringVar(&o.Format, "predicate-format", "",
+		"format of the predicate file when it is a scanner report to convert into a vuln predicate (trivy|grype|sarif).")
+
+	cmd.Flags().StringVar(&o.ScanTime, "scan-time", "",
+		"end of the scan, as an RFC 3339 timestamp, for the scanner reports of --predicate-format which don't record it.")
+
+	cmd.Flags().StringVar(&o.Schema, "predicate-schema", "",
+		"path to a JSON schema (JSON or YAML) the predicate must be valid against, on top of the schema of its type.")
 }
 
 // PredicateRemoteOptions is the wrapper for remote predicate related options.
@@ -17,8 +17,10 @@ package options
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 
 	ecr "github.com/awslabs/amazon-ecr-credential-helper/ecr-login"
 	"github.com/chrismellard/docker-credential-acr-env/pkg/credhelper"
@@ -28,6 +30,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/google"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/retry"
 	"github.com/spf13/cobra"
 )
 
@@ -38,6 +41,8 @@ type Keychain = authn.Keychain
 type RegistryOptions struct {
 	AllowInsecure      bool
 	KubernetesKeychain bool
+	MirrorMapping      string
+	RegistryConfig     string
 	RefOpts            ReferenceOptions
 	Keychain           Keychain
 }
@@ -52,11 +57,23 @@ func (o *RegistryOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.KubernetesKeychain, "k8s-keychain", false,
 		"whether to use the kubernetes keychain instead of the default keychain (supports workload identity).")
 
+	cmd.Flags().StringVar(&o.MirrorMapping, "mirror-mapping", "",
+		"comma separated prefix=replacement rules mapping the repository an image is pulled from to the repository holding its signatures, "+
+			"e.g. mirror.corp/dockerhub=index.docker.io (default $"+ociremote.MirrorMappingEnvKey+")")
+
+	cmd.Flags().StringVar(&o.RegistryConfig, "registry-config", "",
+		"path to a YAML file configuring the credential helper, CA bundle, client certificate, proxy and user agent used for each registry "+
+			"(default $"+RegistryConfigEnvKey+")")
+
 	o.RefOpts.AddFlags(cmd)
 }
 
 func (o *RegistryOptions) ClientOpts(ctx context.Context) ([]ociremote.Option, error) {
-	opts := []ociremote.Option{ociremote.WithRemoteOptions(o.GetRegistryClientOpts(ctx)...)}
+	remoteOpts, err := o.RegistryClientOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := []ociremote.Option{ociremote.WithRemoteOptions(remoteOpts...)}
 	if o.RefOpts.TagPrefix != "" {
 		opts = append(opts, ociremote.WithPrefix(o.RefOpts.TagPrefix))
 	}
@@ -67,33 +84,101 @@ func (o *RegistryOptions) ClientOpts(ctx context.Context) ([]ociremote.Option, e
 	if (targetRepoOverride != name.Repository{}) {
 		opts = append(opts, ociremote.WithTargetRepository(targetRepoOverride))
 	}
+	mirrors, err := o.Mirrors()
+	if err != nil {
+		return nil, err
+	}
+	if len(mirrors) > 0 {
+		opts = append(opts, ociremote.WithMirrorMapping(mirrors))
+	}
 	return opts, nil
 }
 
+// Mirrors returns the mirror mapping set with --mirror-mapping, or else
+// with $COSIGN_MIRROR_MAPPING.
+func (o *RegistryOptions) Mirrors() (ociremote.MirrorMapping, error) {
+	if o.MirrorMapping == "" {
+		return ociremote.GetEnvMirrorMapping()
+	}
+	return ociremote.ParseMirrorMapping(o.MirrorMapping)
+}
+
+// GetRegistryClientOpts returns the options to talk to registries, ignoring
+// the registry configuration file if it cannot be loaded.
+// Prefer RegistryClientOpts, which reports it.
 func (o *RegistryOptions) GetRegistryClientOpts(ctx context.Context) []remote.Option {
-	opts := []remote.Option{
+	opts, err := o.RegistryClientOpts(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: ignoring registry config: %v\n", err)
+		// Without a registry config this cannot fail.
+		opts, _ = o.registryClientOpts(ctx, nil)
+	}
+	return opts
+}
+
+// RegistryClientOpts returns the options to talk to registries, configured
+// with the registry configuration file if one is set.
+func (o *RegistryOptions) RegistryClientOpts(ctx context.Context) ([]remote.Option, error) {
+	rc, err := o.registryConfig()
+	if err != nil {
+		return nil, err
+	}
+	return o.registryClientOpts(ctx, rc)
+}
+
+func (o *RegistryOptions) registryClientOpts(ctx context.Context, rc *RegistryConfig) (opts []remote.Option, err error) {
+	userAgent := UserAgent()
+	if rc != nil && rc.UserAgent != "" {
+		userAgent = rc.UserAgent
+	}
+	opts = []remote.Option{
 		remote.WithContext(ctx),
-		remote.WithUserAgent(UserAgent()),
+		remote.WithUserAgent(userAgent),
 	}
 
+	var kc authn.Keychain
 	switch {
 	case o.Keychain != nil:
-		opts = append(opts, remote.WithAuthFromKeychain(o.Keychain))
+		kc = o.Keychain
 	case o.KubernetesKeychain:
-		kc := authn.NewMultiKeychain(
+		kc = authn.NewMultiKeychain(
 			authn.DefaultKeychain,
 			google.Keychain,
 			authn.NewKeychainFromHelper(ecr.NewECRHelper(ecr.WithLogger(ioutil.Discard))),
 			authn.NewKeychainFromHelper(credhelper.NewACRCredentialsHelper()),
 			github.Keychain,
 		)
-		opts = append(opts, remote.WithAuthFromKeychain(kc))
 	default:
-		opts = append(opts, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+		kc = authn.DefaultKeychain
 	}
+	if rc != nil {
+		kc = rc.Keychain(kc)
+	}
+	opts = append(opts, remote.WithAuthFromKeychain(kc))
 
+	base := remote.DefaultTransport
 	if o.AllowInsecure {
-		opts = append(opts, remote.WithTransport(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}})) // #nosec G402
+		base = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}} // #nosec G402
 	}
-	return opts
+	var t http.RoundTripper = base
+	if rc != nil {
+		if t, err = rc.Transport(base.Clone()); err != nil {
+			return nil, err
+		}
+	}
+
+	// The transport retries the requests it can send again, the streamed
+	// blob uploads are retried as a whole by ggcr.
+	policy := retry.Default()
+	opts = append(opts,
+		remote.WithTransport(retry.NewTransport(t, policy)),
+		remote.WithRetryBackoff(remote.Backoff{
+			Duration: policy.InitialBackoff,
+			Factor:   2,
+			Steps:    policy.MaxAttempts,
+			Cap:      policy.MaxBackoff,
+		}),
+		remote.WithRetryPredicate(retry.Retryable),
+	)
+	return opts, nil
 }
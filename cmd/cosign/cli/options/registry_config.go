@@ -0,0 +1,272 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"sigs.k8s.io/yaml"
+)
+
+// RegistryConfigEnvKey is the environment variable holding the path of the
+// registry configuration file when --registry-config is not set.
+const RegistryConfigEnvKey = "COSIGN_REGISTRY_CONFIG"
+
+// RegistryConfig is the configuration file of the registries cosign talks to.
+// Relative paths in it are relative to the directory of the file.
+//
+// The requests are routed to the configuration of a registry by their host
+// alone: the token realm of a registry, or the storage its blobs are
+// redirected to, only get the CA bundle, client certificate and proxy of the
+// registry when they are listed in its hosts.
+//
+//	userAgent: my-pipeline/1.0
+//	registries:
+//	  registry.corp:
+//	    credentialHelper: corp # runs docker-credential-corp
+//	    caBundle: corp-ca.pem
+//	    clientCert: client.pem
+//	    clientKey: client.key
+//	    proxy: http://proxy.corp:3128
+//	    hosts: [auth.corp, blobs.corp] # token realm and blob storage
+type RegistryConfig struct {
+	// UserAgent, if set, is sent instead of the cosign user agent.
+	UserAgent string `json:"userAgent,omitempty"`
+	// Registries holds the configuration of each registry, by host (and
+	// optionally port). docker.io stands for Docker Hub.
+	Registries map[string]RegistryHostConfig `json:"registries,omitempty"`
+}
+
+// RegistryHostConfig is the configuration of a single registry.
+type RegistryHostConfig struct {
+	// CredentialHelper is the suffix of the docker-credential-<helper>
+	// program providing the credentials of the registry.
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+	// CABundle is the path to PEM encoded CA certificates trusted, on top
+	// of the system ones, to verify the certificate of the registry.
+	CABundle string `json:"caBundle,omitempty"`
+	// ClientCert and ClientKey are the paths to the PEM encoded certificate
+	// and key presented to the registry for mutual TLS.
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// Proxy is the URL of the HTTP proxy to reach the registry through.
+	Proxy string `json:"proxy,omitempty"`
+	// Insecure skips the verification of the certificate of the registry.
+	// Don't use this for anything but testing.
+	Insecure bool `json:"insecure,omitempty"`
+	// Hosts are the other hosts, and optionally ports, the requests to the
+	// registry lead to, such as its token realm or the storage its blobs
+	// are redirected to. They are sent with the same TLS and proxy
+	// configuration as the registry.
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// LoadRegistryConfig reads the registry configuration file at path.
+func LoadRegistryConfig(path string) (*RegistryConfig, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading registry config: %w", err)
+	}
+	c := &RegistryConfig{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("parsing registry config %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	registries := make(map[string]RegistryHostConfig, len(c.Registries))
+	claimed := make(map[string]string, len(c.Registries))
+	for host, rc := range c.Registries {
+		for _, h := range rc.Hosts {
+			if _, ok := c.Registries[h]; ok {
+				return nil, fmt.Errorf("registry %s: host %s is configured as a registry", host, h)
+			}
+			if other, ok := claimed[h]; ok && other != host {
+				return nil, fmt.Errorf("registry %s: host %s is also listed by registry %s", host, h, other)
+			}
+			claimed[h] = host
+		}
+		if (rc.ClientCert == "") != (rc.ClientKey == "") {
+			return nil, fmt.Errorf("registry %s: clientCert and clientKey must be set together", host)
+		}
+		if rc.Proxy != "" {
+			if _, err := url.Parse(rc.Proxy); err != nil {
+				return nil, fmt.Errorf("registry %s: invalid proxy: %w", host, err)
+			}
+		}
+		rc.CABundle = resolve(rc.CABundle)
+		rc.ClientCert = resolve(rc.ClientCert)
+		rc.ClientKey = resolve(rc.ClientKey)
+		// The requests to, and credentials of, Docker Hub use its
+		// canonical host.
+		if host == "docker.io" {
+			host = "index.docker.io"
+		}
+		registries[host] = rc
+	}
+	c.Registries = registries
+	return c, nil
+}
+
+// lookup returns the configuration of the registry at host, which may
+// include a port.
+func (c *RegistryConfig) lookup(host string) (RegistryHostConfig, bool) {
+	if rc, ok := c.Registries[host]; ok {
+		return rc, true
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		rc, ok := c.Registries[h]
+		return rc, ok
+	}
+	return RegistryHostConfig{}, false
+}
+
+// Keychain returns the keychain resolving the credentials of the registries
+// with a credential helper, and falling back to base for the others.
+func (c *RegistryConfig) Keychain(base authn.Keychain) authn.Keychain {
+	return authn.NewMultiKeychain(&helperKeychain{config: c}, base)
+}
+
+type helperKeychain struct {
+	config *RegistryConfig
+}
+
+// Resolve implements authn.Keychain
+func (k *helperKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	rc, ok := k.config.lookup(r.RegistryStr())
+	if !ok || rc.CredentialHelper == "" {
+		return authn.Anonymous, nil
+	}
+	return authn.NewKeychainFromHelper(credentialHelper{
+		program: client.NewShellProgramFunc("docker-credential-" + rc.CredentialHelper),
+	}).Resolve(r)
+}
+
+// credentialHelper implements authn.Helper with a docker credential helper program.
+type credentialHelper struct {
+	program client.ProgramFunc
+}
+
+func (h credentialHelper) Get(serverURL string) (string, string, error) {
+	creds, err := client.Get(h.program, serverURL)
+	if err != nil {
+		return "", "", err
+	}
+	return creds.Username, creds.Secret, nil
+}
+
+// Transport returns the transport sending the requests to each registry, and
+// to the hosts it lists, with its configuration, and the other requests with
+// base.
+func (c *RegistryConfig) Transport(base *http.Transport) (http.RoundTripper, error) {
+	t := &registryTransport{
+		base:  base,
+		hosts: make(map[string]http.RoundTripper, len(c.Registries)),
+	}
+	for host, rc := range c.Registries {
+		ht, err := hostTransport(base, rc)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", host, err)
+		}
+		t.hosts[host] = ht
+		for _, h := range rc.Hosts {
+			t.hosts[h] = ht
+		}
+	}
+	return t, nil
+}
+
+func hostTransport(base *http.Transport, rc RegistryHostConfig) (*http.Transport, error) {
+	t := base.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if rc.Insecure {
+		t.TLSClientConfig.InsecureSkipVerify = true // #nosec G402
+	}
+	if rc.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pems, err := os.ReadFile(rc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pems) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", rc.CABundle)
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	if rc.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(rc.ClientCert, rc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		t.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+	if rc.Proxy != "" {
+		u, err := url.Parse(rc.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	return t, nil
+}
+
+// registryTransport routes the requests by host.
+type registryTransport struct {
+	base  http.RoundTripper
+	hosts map[string]http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *registryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ht, ok := t.hosts[req.URL.Host]; ok {
+		return ht.RoundTrip(req)
+	}
+	if ht, ok := t.hosts[req.URL.Hostname()]; ok {
+		return ht.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// registryConfig loads the registry configuration set with --registry-config,
+// or else with $COSIGN_REGISTRY_CONFIG. It returns nil if neither is set.
+func (o *RegistryOptions) registryConfig() (*RegistryConfig, error) {
+	path := o.RegistryConfig
+	if path == "" {
+		path = os.Getenv(RegistryConfigEnvKey)
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return LoadRegistryConfig(path)
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRegistryConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "registries.yaml")
+	writeFile(t, path, `
+userAgent: my-pipeline/1.0
+registries:
+  docker.io:
+    credentialHelper: hub
+  registry.corp:5000:
+    caBundle: ca.pem
+    clientCert: /etc/certs/client.pem
+    clientKey: client.key
+    proxy: http://proxy.corp:3128
+`)
+	c, err := LoadRegistryConfig(path)
+	if err != nil {
+		t.Fatalf("LoadRegistryConfig() = %v", err)
+	}
+	if c.UserAgent != "my-pipeline/1.0" {
+		t.Errorf("UserAgent = %q", c.UserAgent)
+	}
+	if rc, ok := c.lookup("index.docker.io"); !ok || rc.CredentialHelper != "hub" {
+		t.Errorf("lookup(index.docker.io) = %v, %v", rc, ok)
+	}
+	rc, ok := c.lookup("registry.corp:5000")
+	if !ok {
+		t.Fatal("lookup(registry.corp:5000) found nothing")
+	}
+	if want := filepath.Join(dir, "ca.pem"); rc.CABundle != want {
+		t.Errorf("CABundle = %q, wanted %q", rc.CABundle, want)
+	}
+	if want := "/etc/certs/client.pem"; rc.ClientCert != want {
+		t.Errorf("ClientCert = %q, wanted %q", rc.ClientCert, want)
+	}
+	if _, ok := c.lookup("registry.corp"); ok {
+		t.Error("lookup(registry.corp) matched the configuration of registry.corp:5000")
+	}
+}
+
+func TestLoadRegistryConfigHostWithoutPort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registries.yaml")
+	writeFile(t, path, "registries:\n  registry.corp:\n    credentialHelper: corp\n")
+	c, err := LoadRegistryConfig(path)
+	if err != nil {
+		t.Fatalf("LoadRegistryConfig() = %v", err)
+	}
+	if rc, ok := c.lookup("registry.corp:5000"); !ok || rc.CredentialHelper != "corp" {
+		t.Errorf("lookup(registry.corp:5000) = %v, %v", rc, ok)
+	}
+}
+
+func TestLoadRegistryConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{{
+		name:    "unknown field",
+		content: "registries:\n  registry.corp:\n    caBundel: ca.pem\n",
+	}, {
+		name:    "cert without key",
+		content: "registries:\n  registry.corp:\n    clientCert: client.pem\n",
+	}, {
+		name:    "invalid proxy",
+		content: "registries:\n  registry.corp:\n    proxy: \"http://[::1\"\n",
+	}, {
+		name:    "host configured as a registry",
+		content: "registries:\n  registry.corp:\n    hosts: [auth.corp]\n  auth.corp: {}\n",
+	}, {
+		name:    "host listed twice",
+		content: "registries:\n  registry.corp:\n    hosts: [auth.corp]\n  other.corp:\n    hosts: [auth.corp]\n",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "registries.yaml")
+			writeFile(t, path, test.content)
+			if _, err := LoadRegistryConfig(path); err == nil {
+				t.Error("LoadRegistryConfig() succeeded, wanted an error")
+			}
+		})
+	}
+}
+
+func TestRegistryConfigTransport(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ca.pem"), string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: s.Certificate().Raw,
+	})))
+
+	get := func(rc *RegistryConfig) error {
+		tr, err := rc.Transport(remote.DefaultTransport.Clone())
+		if err != nil {
+			t.Fatalf("Transport() = %v", err)
+		}
+		resp, err := (&http.Client{Transport: tr}).Get(s.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	// The certificate of the server is only trusted for the configured host.
+	if err := get(&RegistryConfig{}); err == nil {
+		t.Error("GET succeeded without the CA bundle of the server")
+	}
+	if err := get(&RegistryConfig{Registries: map[string]RegistryHostConfig{
+		"registry.corp": {CABundle: filepath.Join(dir, "ca.pem")},
+	}}); err == nil {
+		t.Error("GET succeeded with the CA bundle of another host")
+	}
+	if err := get(&RegistryConfig{Registries: map[string]RegistryHostConfig{
+		u.Host: {CABundle: filepath.Join(dir, "ca.pem")},
+	}}); err != nil {
+		t.Errorf("GET with the CA bundle of the server = %v", err)
+	}
+	if err := get(&RegistryConfig{Registries: map[string]RegistryHostConfig{
+		u.Hostname(): {Insecure: true},
+	}}); err != nil {
+		t.Errorf("GET with an insecure host = %v", err)
+	}
+	// The hosts of a registry, such as its token realm, share its config.
+	if err := get(&RegistryConfig{Registries: map[string]RegistryHostConfig{
+		"registry.corp": {CABundle: filepath.Join(dir, "ca.pem"), Hosts: []string{u.Host}},
+	}}); err != nil {
+		t.Errorf("GET to a host of the registry = %v", err)
+	}
+}
+
+func TestRegistryClientOptsConfigError(t *testing.T) {
+	o := &RegistryOptions{RegistryConfig: filepath.Join(t.TempDir(), "missing.yaml")}
+	if _, err := o.RegistryClientOpts(context.Background()); err == nil {
+		t.Error("RegistryClientOpts() succeeded with a missing registry config")
+	}
+}
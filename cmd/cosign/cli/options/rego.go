@@ -0,0 +1,40 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// RegoOptions is the wrapper for the options of Rego policies.
+type RegoOptions struct {
+	Query   string
+	Data    []string
+	Bundles []string
+}
+
+var _ Interface = (*RegoOptions)(nil)
+
+// AddFlags implements Interface
+func (o *RegoOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Query, "policy-query", "",
+		"Rego query deciding whether the Rego policies are satisfied, data.signature.allow by default. The messages of the deny rule in its package, if any, are reported as violations")
+
+	cmd.Flags().StringSliceVar(&o.Data, "policy-data", nil,
+		"JSON or YAML documents to load in the data document of the Rego policies, prefix them with <path>: to load them under data.<path>")
+
+	cmd.Flags().StringSliceVar(&o.Bundles, "policy-bundle", nil,
+		"OPA bundles, tarballs or directories, whose modules and data are loaded with the Rego policies")
+}
@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/pkg/retry"
 )
 
 // RootOptions define flags and options for the root cosign cli.
@@ -27,6 +29,10 @@ type RootOptions struct {
 	Verbose          bool
 	Timeout          time.Duration
 	SkipConfirmation bool
+
+	RetryMaxAttempts    int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
 }
 
 // DefaultTimeout specifies the default timeout for commands.
@@ -47,4 +53,33 @@ func (o *RootOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.PersistentFlags().BoolVarP(&o.SkipConfirmation, "yes", "y", false,
 		"skip confirmation prompts for non-destructive operations")
+
+	cmd.PersistentFlags().IntVar(&o.RetryMaxAttempts, "retry-max-attempts", retry.DefaultPolicy.MaxAttempts,
+		"maximum number of attempts of the requests to registries, Rekor, Fulcio and TUF mirrors failing transiently, 1 disables retries (or $"+retry.MaxAttemptsEnvKey+")")
+
+	cmd.PersistentFlags().DurationVar(&o.RetryInitialBackoff, "retry-initial-backoff", retry.DefaultPolicy.InitialBackoff,
+		"time to wait before the first retry, doubled after each retry (or $"+retry.InitialBackoffEnvKey+")")
+
+	cmd.PersistentFlags().DurationVar(&o.RetryMaxBackoff, "retry-max-backoff", retry.DefaultPolicy.MaxBackoff,
+		"maximum time to wait between two attempts, requests asked to be retried later are not (or $"+retry.MaxBackoffEnvKey+")")
+}
+
+// RetryPolicy returns the retry policy configured by the environment,
+// overridden by the flags set on cmd.
+func (o *RootOptions) RetryPolicy(cmd *cobra.Command) (retry.Policy, error) {
+	p, err := retry.PolicyFromEnv()
+	if err != nil {
+		return retry.Policy{}, err
+	}
+	flags := cmd.Flags()
+	if flags.Changed("retry-max-attempts") {
+		p.MaxAttempts = o.RetryMaxAttempts
+	}
+	if flags.Changed("retry-initial-backoff") {
+		p.InitialBackoff = o.RetryInitialBackoff
+	}
+	if flags.Changed("retry-max-backoff") {
+		p.MaxBackoff = o.RetryMaxBackoff
+	}
+	return p, p.Validate()
 }
@@ -31,6 +31,7 @@ type SignOptions struct {
 	PayloadPath       string
 	Force             bool
 	Recursive         bool
+	Jobs              int
 	Attachment        string
 
 	Rekor       RekorOptions
@@ -82,6 +83,9 @@ func (o *SignOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false,
 		"if a multi-arch image is specified, additionally sign each discrete image")
 
+	cmd.Flags().IntVar(&o.Jobs, "jobs", 1,
+		"the maximum number of images of a multi-arch image signed at the same time with --recursive, always 1 with --sk or a PKCS#11 key")
+
 	cmd.Flags().StringVar(&o.Attachment, "attachment", "",
-		"related image attachment to sign (sbom), default none")
+		"related image attachment to sign (sbom or the name of a file attachment), default none")
 }
@@ -19,10 +19,22 @@ import "github.com/spf13/cobra"
 type TreeOptions struct {
 	Registry  RegistryOptions
 	CleanType string
+	Output    string
+	Recursive bool
+	Files     bool
 }
 
 var _ Interface = (*TreeOptions)(nil)
 
 func (c *TreeOptions) AddFlags(cmd *cobra.Command) {
 	c.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVarP(&c.Output, "output", "o", "text",
+		"output format for the artifacts (text|json)")
+
+	cmd.Flags().BoolVarP(&c.Recursive, "recursive", "r", false,
+		"if a multi-arch image is specified, additionally display the artifacts of each discrete image")
+
+	cmd.Flags().BoolVar(&c.Files, "files", false,
+		"additionally display the named file attachments, found by listing the tags of the repository holding the attachments")
 }
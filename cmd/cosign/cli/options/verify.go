@@ -16,6 +16,8 @@
 package options
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 )
 
@@ -27,8 +29,10 @@ type VerifyOptions struct {
 	Output       string
 	SignatureRef string
 	LocalImage   bool
+	Policies     []string
 
 	SecurityKey     SecurityKeyOptions
+	Rego            RegoOptions
 	CertVerify      CertVerifyOptions
 	Rekor           RekorOptions
 	Registry        RegistryOptions
@@ -46,6 +50,7 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 	o.Registry.AddFlags(cmd)
 	o.SignatureDigest.AddFlags(cmd)
 	o.AnnotationOptions.AddFlags(cmd)
+	o.Rego.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
 		"path to the public key file, KMS URI or Kubernetes Secret")
@@ -54,7 +59,7 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 		"whether to check the claims found")
 
 	cmd.Flags().StringVar(&o.Attachment, "attachment", "",
-		"related image attachment to sign (sbom), default none")
+		"related image attachment to verify (sbom or the name of a file attachment), default none")
 
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "json",
 		"output format for the signing image information (json|text)")
@@ -64,6 +69,40 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
 		"whether the specified image is a path to an image saved locally via 'cosign save'")
+
+	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
+		"specify CUE, Rego, JSON schema (.json) or CEL files to validate the signatures with, evaluated against their payload along with their certificate subject, issuer and extensions")
+}
+
+// VerifySBOMOptions is the top level wrapper for the `verify-sbom` command.
+type VerifySBOMOptions struct {
+	Key         string
+	CheckClaims bool
+
+	SecurityKey     SecurityKeyOptions
+	CertVerify      CertVerifyOptions
+	Rekor           RekorOptions
+	Registry        RegistryOptions
+	SignatureDigest SignatureDigestOptions
+	AnnotationOptions
+}
+
+var _ Interface = (*VerifySBOMOptions)(nil)
+
+// AddFlags implements Interface
+func (o *VerifySBOMOptions) AddFlags(cmd *cobra.Command) {
+	o.SecurityKey.AddFlags(cmd)
+	o.Rekor.AddFlags(cmd)
+	o.CertVerify.AddFlags(cmd)
+	o.Registry.AddFlags(cmd)
+	o.SignatureDigest.AddFlags(cmd)
+	o.AnnotationOptions.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret")
+
+	cmd.Flags().BoolVar(&o.CheckClaims, "check-claims", true,
+		"whether to check the claims found")
 }
 
 // VerifyAttestationOptions is the top level wrapper for the `verify attestation` command.
@@ -77,8 +116,11 @@ type VerifyAttestationOptions struct {
 	CertVerify  CertVerifyOptions
 	Registry    RegistryOptions
 	Predicate   PredicateRemoteOptions
+	Rego        RegoOptions
 	Policies    []string
 	LocalImage  bool
+	MaxAge      time.Duration
+	Explain     string
 }
 
 var _ Interface = (*VerifyAttestationOptions)(nil)
@@ -90,6 +132,7 @@ func (o *VerifyAttestationOptions) AddFlags(cmd *cobra.Command) {
 	o.CertVerify.AddFlags(cmd)
 	o.Registry.AddFlags(cmd)
 	o.Predicate.AddFlags(cmd)
+	o.Rego.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
 		"path to the public key file, KMS URI or Kubernetes Secret")
@@ -98,13 +141,20 @@ func (o *VerifyAttestationOptions) AddFlags(cmd *cobra.Command) {
 		"whether to check the claims found")
 
 	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
-		"specify CUE or Rego files will be using for validation")
+		"specify CUE, Rego, JSON schema (.json) or CEL files will be using for validation")
 
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "json",
-		"output format for the signing image information (json|text)")
+		"output format of the attestations: json prints the DSSE envelopes, text a summary of their decoded statements, decoded the statements as JSON, predicate only the predicates of the --type (json|text|decoded|predicate)")
 
 	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
 		"whether the specified image is a path to an image saved locally via 'cosign save'")
+
+	cmd.Flags().DurationVar(&o.MaxAge, "max-age", 0,
+		"reject the attestations produced longer ago than this, according to their transparency log entry or the timestamp of their predicate (e.g. the end of a vulnerability scan), 0 disables it")
+
+	cmd.Flags().StringVar(&o.Explain, "explain", "",
+		"explain why the policies fail: the conflicting values of CUE policies, and the evaluation trace of Rego policies (notes|fails|full)")
+	cmd.Flags().Lookup("explain").NoOptDefVal = "fails"
 }
 
 // VerifyBlobOptions is the top level wrapper for the `verify blob` command.
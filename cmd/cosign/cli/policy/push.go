@@ -0,0 +1,115 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+	"github.com/sigstore/cosign/pkg/apis/utils"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/pkg/policy"
+)
+
+// policyTypesByExtension is used to detect the type of the pushed policy when
+// it is not set explicitly.
+var policyTypesByExtension = map[string]string{
+	".cue":  "cue",
+	".rego": "rego",
+	".json": "jsonschema",
+	".cel":  "cel",
+}
+
+// PolicyType returns the type of the policy at path, policyType if set, or
+// else the type matching its extension.
+func PolicyType(path, policyType string) (string, error) {
+	if policyType != "" {
+		return policyType, nil
+	}
+	t, ok := policyTypesByExtension[filepath.Ext(path)]
+	if !ok {
+		return "", fmt.Errorf("cannot detect the type of policy %s from its extension, set it with --type", path)
+	}
+	return t, nil
+}
+
+// PushCmd uploads the policy at policyPath to imageRef as an OCI artifact and
+// signs it, so that ClusterImagePolicies can reference it with policy.oci.
+// The digest of the artifact is written to w.
+func PushCmd(ctx context.Context, ro *options.RootOptions, ko options.KeyOpts, regOpts options.RegistryOptions,
+	policyPath, policyType, imageRef string, force bool, w io.Writer) error {
+	policyType, err := PolicyType(policyPath, policyType)
+	if err != nil {
+		return err
+	}
+	body, err := os.ReadFile(policyPath)
+	if err != nil {
+		return fmt.Errorf("reading policy: %w", err)
+	}
+	// Catch invalid policies before they are signed, rather than when the
+	// policy-controller fetches them.
+	if err := utils.ValidatePolicy(policyType, string(body), ""); err != nil {
+		return fmt.Errorf("invalid %s policy %s: %w", policyType, policyPath, err)
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return err
+	}
+	remoteOpts, err := regOpts.RegistryClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+	digest, err := UploadPolicy(ref, policyType, body, remoteOpts...)
+	if err != nil {
+		return err
+	}
+
+	if err := sign.SignCmd(ro, ko, regOpts, nil, []string{digest.String()}, "", "", true, "", "", "", force, false, 0, ""); err != nil {
+		return fmt.Errorf("signing policy %s: %w", digest, err)
+	}
+	fmt.Fprintln(w, digest.String())
+	return nil
+}
+
+// UploadPolicy uploads the policy to ref as an artifact made of a single
+// layer, whose media type tells the type of the policy, and returns its
+// digest.
+func UploadPolicy(ref name.Reference, policyType string, body []byte, opts ...remote.Option) (name.Digest, error) {
+	mt, err := policy.MediaType(policyType)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	img, err := static.NewFile(body, static.WithLayerMediaType(mt))
+	if err != nil {
+		return name.Digest{}, err
+	}
+	if err := remote.Write(ref, img, opts...); err != nil {
+		return name.Digest{}, fmt.Errorf("uploading policy: %w", err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		return name.Digest{}, err
+	}
+	return ref.Context().Digest(d.String()), nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+
+	"github.com/sigstore/cosign/pkg/policy"
+)
+
+func TestPolicyType(t *testing.T) {
+	tests := []struct {
+		path, policyType, want string
+	}{
+		{path: "policy.cue", want: "cue"},
+		{path: "policies/policy.rego", want: "rego"},
+		{path: "schema.json", want: "jsonschema"},
+		{path: "expr.cel", want: "cel"},
+		{path: "policy.txt", policyType: "rego", want: "rego"},
+		{path: "policy.txt"},
+	}
+	for _, tc := range tests {
+		got, err := PolicyType(tc.path, tc.policyType)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("PolicyType(%q) = %q, want error", tc.path, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("PolicyType(%q, %q) = %q, %v, want %q", tc.path, tc.policyType, got, err, tc.want)
+		}
+	}
+}
+
+func TestUploadPolicy(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/policies/foo:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := UploadPolicy(ref, "rego", []byte(regoPolicy))
+	if err != nil {
+		t.Fatalf("UploadPolicy() = %v", err)
+	}
+	policyType, body, err := policy.FetchOCIPolicy(digest)
+	if err != nil {
+		t.Fatalf("FetchOCIPolicy() = %v", err)
+	}
+	if policyType != "rego" || string(body) != regoPolicy {
+		t.Errorf("fetched a %s policy %q", policyType, body)
+	}
+
+	if _, err := UploadPolicy(ref, "opa", []byte(regoPolicy)); err == nil {
+		t.Error("UploadPolicy() accepted an unknown policy type")
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sigstore/cosign/pkg/apis/utils"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
+	"github.com/sigstore/cosign/pkg/policy"
+)
+
+// The output formats of policy test.
+const (
+	// TestOutputText prints one line per test case and a summary.
+	TestOutputText = "text"
+	// TestOutputJSON prints the results of the test cases as a JSON array.
+	TestOutputJSON = "json"
+	// TestOutputJUnit prints the results as a JUnit XML report, with one test
+	// suite per test file.
+	TestOutputJUnit = "junit"
+)
+
+// TestOutputs lists the supported output formats of policy test.
+var TestOutputs = []string{TestOutputText, TestOutputJSON, TestOutputJUnit}
+
+// The expected outcomes of a test case.
+const (
+	ExpectAllow = "allow"
+	ExpectDeny  = "deny"
+)
+
+// TestFile declares a policy and the test cases it is run against.
+type TestFile struct {
+	Policy TestPolicy `json:"policy"`
+	Tests  []TestCase `json:"tests"`
+}
+
+// TestPolicy is the policy under test.
+type TestPolicy struct {
+	// Type is the language of the policy, one of utils.PolicyTypes.
+	Type string `json:"type"`
+	// File is the path of the policy, relative to the test file.
+	File string `json:"file"`
+	// Query decides whether a rego policy is satisfied, the default one is
+	// used when it is empty.
+	Query string `json:"query,omitempty"`
+}
+
+// TestCase is a fixture the policy is evaluated against.
+type TestCase struct {
+	Name string `json:"name"`
+	// Input is the path of the JSON document the policy is evaluated against,
+	// relative to the test file. DSSE envelopes, as printed by
+	// verify-attestation, are evaluated against their in-toto statement like
+	// the policies of verify-attestation and ClusterImagePolicies are.
+	Input string `json:"input"`
+	// Expect is either allow or deny.
+	Expect string `json:"expect"`
+	// ErrorContains, for denied cases, must be part of the error of the policy.
+	ErrorContains string `json:"errorContains,omitempty"`
+}
+
+// TestResult is the outcome of a test case.
+type TestResult struct {
+	// File is the test file which declares the test case.
+	File   string  `json:"file"`
+	Name   string  `json:"name"`
+	Passed bool    `json:"passed"`
+	Time   float64 `json:"time"`
+	// Message tells why the test case failed.
+	Message string `json:"message,omitempty"`
+}
+
+// TestCmd runs the test cases of each of the test files and writes their
+// results to w in the given output format. Failed cases are explained in one
+// of the rego.ExplainModes when explain is set. It returns an error when a
+// test case fails or a test file is invalid.
+func TestCmd(ctx context.Context, testFiles []string, output string, explain string, w io.Writer) error {
+	if err := validateTestOutput(output); err != nil {
+		return err
+	}
+	if explain != "" {
+		if err := rego.ValidateExplainMode(explain); err != nil {
+			return err
+		}
+		ctx = policy.WithExplain(ctx, explain)
+	}
+
+	var results []TestResult
+	for _, f := range testFiles {
+		r, err := RunTestFile(ctx, f)
+		if err != nil {
+			return err
+		}
+		results = append(results, r...)
+	}
+
+	if err := PrintTestResults(w, results, output); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d policy tests failed", failed, len(results))
+	}
+	return nil
+}
+
+func validateTestOutput(output string) error {
+	for _, o := range TestOutputs {
+		if o == output {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q, expected one of %s", output, strings.Join(TestOutputs, ", "))
+}
+
+// RunTestFile loads the test file at path, in YAML or JSON, and evaluates its
+// policy against each of its test cases.
+func RunTestFile(ctx context.Context, path string) ([]TestResult, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading test file: %w", err)
+	}
+	var tf TestFile
+	if err := yaml.UnmarshalStrict(raw, &tf); err != nil {
+		return nil, fmt.Errorf("parsing test file %s: %w", path, err)
+	}
+	if err := tf.validate(); err != nil {
+		return nil, fmt.Errorf("invalid test file %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	policyBody, err := os.ReadFile(filepath.Join(dir, tf.Policy.File))
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+
+	results := make([]TestResult, 0, len(tf.Tests))
+	for _, tc := range tf.Tests {
+		start := time.Now()
+		result := TestResult{File: path, Name: tc.Name}
+		if msg := runTestCase(ctx, tf.Policy, string(policyBody), dir, tc); msg != "" {
+			result.Message = msg
+		} else {
+			result.Passed = true
+		}
+		result.Time = time.Since(start).Seconds()
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (tf *TestFile) validate() error {
+	validType := false
+	for _, t := range utils.PolicyTypes {
+		if tf.Policy.Type == t {
+			validType = true
+		}
+	}
+	if !validType {
+		return fmt.Errorf("unsupported policy type %q, expected one of %s", tf.Policy.Type, strings.Join(utils.PolicyTypes, ", "))
+	}
+	if tf.Policy.File == "" {
+		return errors.New("missing policy file")
+	}
+	if tf.Policy.Query != "" && tf.Policy.Type != "rego" {
+		return errors.New("query is only supported for rego policies")
+	}
+	if len(tf.Tests) == 0 {
+		return errors.New("no test cases")
+	}
+	for i, tc := range tf.Tests {
+		if tc.Name == "" {
+			return fmt.Errorf("test case %d has no name", i)
+		}
+		if tc.Input == "" {
+			return fmt.Errorf("test case %q has no input", tc.Name)
+		}
+		switch tc.Expect {
+		case ExpectAllow:
+			if tc.ErrorContains != "" {
+				return fmt.Errorf("test case %q expects the policy to allow its input but sets errorContains", tc.Name)
+			}
+		case ExpectDeny:
+		default:
+			return fmt.Errorf("test case %q expects %q, expected %s or %s", tc.Name, tc.Expect, ExpectAllow, ExpectDeny)
+		}
+	}
+	return nil
+}
+
+// runTestCase returns why the test case failed, or an empty string if it
+// passed.
+func runTestCase(ctx context.Context, p TestPolicy, policyBody, dir string, tc TestCase) string {
+	raw, err := os.ReadFile(filepath.Join(dir, tc.Input))
+	if err != nil {
+		return fmt.Sprintf("reading input: %v", err)
+	}
+	input, err := policyInput(raw)
+	if err != nil {
+		return err.Error()
+	}
+
+	err = policy.EvaluatePolicyAgainstJSONWithQuery(ctx, tc.Name, p.Type, policyBody, p.Query, input)
+	switch {
+	case tc.Expect == ExpectAllow && err != nil:
+		msg := fmt.Sprintf("expected the policy to allow the input, got: %v", err)
+		if explanation := policy.Explanation(err); explanation != "" {
+			msg += "\n" + strings.TrimSpace(explanation)
+		}
+		return msg
+	case tc.Expect == ExpectDeny && err == nil:
+		return "expected the policy to deny the input, but it was allowed"
+	case tc.Expect == ExpectDeny && !strings.Contains(err.Error(), tc.ErrorContains):
+		return fmt.Sprintf("expected the error of the policy to contain %q, got: %v", tc.ErrorContains, err)
+	}
+	return ""
+}
+
+// policyInput returns the document policies are evaluated against for the
+// fixture: the in-toto statement of DSSE envelopes, and the fixture itself
+// otherwise.
+func policyInput(raw []byte) ([]byte, error) {
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("unmarshaling input: %w", err)
+	}
+	if envelope.PayloadType == "" || envelope.Payload == "" {
+		return raw, nil
+	}
+	statement, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding DSSE payload: %w", err)
+	}
+	return statement, nil
+}
+
+// PrintTestResults writes the results to w in the given output format.
+func PrintTestResults(w io.Writer, results []TestResult, output string) error {
+	switch output {
+	case TestOutputText:
+		passed := 0
+		for _, r := range results {
+			if r.Passed {
+				passed++
+				fmt.Fprintf(w, "PASS %s: %s\n", r.File, r.Name)
+				continue
+			}
+			fmt.Fprintf(w, "FAIL %s: %s\n", r.File, r.Name)
+			for _, line := range strings.Split(r.Message, "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+		fmt.Fprintf(w, "%d passed, %d failed\n", passed, len(results)-passed)
+	case TestOutputJSON:
+		if results == nil {
+			results = []TestResult{}
+		}
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	case TestOutputJUnit:
+		b, err := xml.MarshalIndent(junitReport(results), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s%s\n", xml.Header, b)
+	default:
+		return validateTestOutput(output)
+	}
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junitReport groups the results by test file, in the order they were run.
+func junitReport(results []TestResult) junitTestSuites {
+	report := junitTestSuites{}
+	index := map[string]int{}
+	suiteTimes := []float64{}
+	for _, r := range results {
+		i, ok := index[r.File]
+		if !ok {
+			i = len(report.Suites)
+			index[r.File] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: r.File})
+			suiteTimes = append(suiteTimes, 0)
+		}
+		suite := &report.Suites[i]
+		tc := junitTestCase{
+			Name:      r.Name,
+			ClassName: r.File,
+			Time:      fmt.Sprintf("%.3f", r.Time),
+		}
+		if !r.Passed {
+			message := r.Message
+			if i := strings.IndexByte(message, '\n'); i >= 0 {
+				message = message[:i]
+			}
+			tc.Failure = &junitFailure{Message: message, Body: r.Message}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Tests++
+		report.Tests++
+		suiteTimes[i] += r.Time
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for i := range report.Suites {
+		report.Suites[i].Time = fmt.Sprintf("%.3f", suiteTimes[i])
+	}
+	return report
+}
@@ -0,0 +1,266 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	regoPolicy = `package sigstore
+default isCompliant = false
+isCompliant {
+	input.predicate.foo == "bar"
+}
+deny[msg] {
+	input.predicate.foo != "bar"
+	msg := sprintf("foo is %s", [input.predicate.foo])
+}`
+	cuePolicy = `predicate: foo: "bar"`
+
+	barStatement = `{"predicateType":"https://example.com/custom","predicate":{"foo":"bar"}}`
+	bazStatement = `{"predicateType":"https://example.com/custom","predicate":{"foo":"baz"}}`
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func envelope(t *testing.T, statement string) string {
+	t.Helper()
+	b, err := json.Marshal(map[string]string{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString([]byte(statement)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRunTestFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		testFile string
+		policy   string
+		want     map[string]string
+	}{{
+		name: "rego passing cases",
+		testFile: `policy:
+  type: rego
+  file: policy.rego
+tests:
+- name: bar
+  input: fixtures/bar.json
+  expect: allow
+- name: bar envelope
+  input: fixtures/bar-envelope.json
+  expect: allow
+- name: baz
+  input: fixtures/baz.json
+  expect: deny
+  errorContains: foo is baz
+`,
+		policy: regoPolicy,
+		want:   map[string]string{"bar": "", "bar envelope": "", "baz": ""},
+	}, {
+		name: "rego failing cases",
+		testFile: `policy:
+  type: rego
+  file: policy.rego
+tests:
+- name: bar
+  input: fixtures/bar.json
+  expect: deny
+- name: baz
+  input: fixtures/baz.json
+  expect: allow
+- name: baz message
+  input: fixtures/baz.json
+  expect: deny
+  errorContains: foo is qux
+- name: missing
+  input: fixtures/missing.json
+  expect: allow
+`,
+		policy: regoPolicy,
+		want: map[string]string{
+			"bar":         "expected the policy to deny the input, but it was allowed",
+			"baz":         "expected the policy to allow the input, got: failed evaluating rego policy for type baz: policy denied: foo is baz",
+			"baz message": `expected the error of the policy to contain "foo is qux"`,
+			"missing":     "reading input:",
+		},
+	}, {
+		name: "cue test file in JSON",
+		testFile: `{"policy": {"type": "cue", "file": "policy.cue"},
+ "tests": [{"name": "bar", "input": "fixtures/bar.json", "expect": "allow"},
+           {"name": "baz", "input": "fixtures/baz.json", "expect": "deny"}]}`,
+		policy: cuePolicy,
+		want:   map[string]string{"bar": "", "baz": ""},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"policy_test.yaml":           tc.testFile,
+				"policy.rego":                tc.policy,
+				"policy.cue":                 tc.policy,
+				"fixtures/bar.json":          barStatement,
+				"fixtures/bar-envelope.json": envelope(t, barStatement),
+				"fixtures/baz.json":          bazStatement,
+			})
+			results, err := RunTestFile(context.Background(), filepath.Join(dir, "policy_test.yaml"))
+			if err != nil {
+				t.Fatalf("RunTestFile() = %v", err)
+			}
+			if len(results) != len(tc.want) {
+				t.Fatalf("got %d results, want %d", len(results), len(tc.want))
+			}
+			for _, r := range results {
+				want, ok := tc.want[r.Name]
+				if !ok {
+					t.Fatalf("unexpected result %q", r.Name)
+				}
+				if r.Passed != (want == "") {
+					t.Errorf("%s: passed = %t, message = %q", r.Name, r.Passed, r.Message)
+				}
+				if !strings.HasPrefix(r.Message, want) {
+					t.Errorf("%s: message = %q, want prefix %q", r.Name, r.Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunTestFileInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		testFile string
+		wantErr  string
+	}{{
+		name:     "unknown type",
+		testFile: "policy: {type: opa, file: policy.rego}\ntests: [{name: a, input: a.json, expect: allow}]",
+		wantErr:  `unsupported policy type "opa"`,
+	}, {
+		name:     "cue query",
+		testFile: "policy: {type: cue, file: policy.cue, query: data.foo}\ntests: [{name: a, input: a.json, expect: allow}]",
+		wantErr:  "query is only supported for rego policies",
+	}, {
+		name:     "no expectation",
+		testFile: "policy: {type: rego, file: policy.rego}\ntests: [{name: a, input: a.json}]",
+		wantErr:  `test case "a" expects ""`,
+	}, {
+		name:     "allowed with error",
+		testFile: "policy: {type: rego, file: policy.rego}\ntests: [{name: a, input: a.json, expect: allow, errorContains: foo}]",
+		wantErr:  "sets errorContains",
+	}, {
+		name:     "unknown field",
+		testFile: "policy: {type: rego, file: policy.rego}\ntests: [{name: a, input: a.json, expected: allow}]",
+		wantErr:  "unknown field",
+	}, {
+		name:     "missing policy",
+		testFile: "policy: {type: rego, file: missing.rego}\ntests: [{name: a, input: a.json, expect: allow}]",
+		wantErr:  "reading policy",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"policy_test.yaml": tc.testFile,
+				"policy.rego":      regoPolicy,
+				"policy.cue":       cuePolicy,
+			})
+			_, err := RunTestFile(context.Background(), filepath.Join(dir, "policy_test.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("RunTestFile() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTestCmd(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"pass_test.yaml": "policy: {type: rego, file: policy.rego}\ntests: [{name: bar, input: bar.json, expect: allow}]",
+		"fail_test.yaml": "policy: {type: rego, file: policy.rego}\ntests: [{name: baz, input: baz.json, expect: allow}]",
+		"policy.rego":    regoPolicy,
+		"bar.json":       barStatement,
+		"baz.json":       bazStatement,
+	})
+	pass, fail := filepath.Join(dir, "pass_test.yaml"), filepath.Join(dir, "fail_test.yaml")
+
+	var out bytes.Buffer
+	if err := TestCmd(context.Background(), []string{pass}, TestOutputText, "", &out); err != nil {
+		t.Fatalf("TestCmd() = %v", err)
+	}
+	if want := "PASS " + pass + ": bar\n1 passed, 0 failed\n"; out.String() != want {
+		t.Errorf("text output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	err := TestCmd(context.Background(), []string{pass, fail}, TestOutputJSON, "", &out)
+	if err == nil || err.Error() != "1 of 2 policy tests failed" {
+		t.Errorf("TestCmd() = %v", err)
+	}
+	var results []TestResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("unmarshaling JSON output: %v", err)
+	}
+	if len(results) != 2 || !results[0].Passed || results[1].Passed || results[1].File != fail {
+		t.Errorf("unexpected JSON results %+v", results)
+	}
+
+	out.Reset()
+	if err := TestCmd(context.Background(), []string{pass, fail}, TestOutputJUnit, "full", &out); err == nil {
+		t.Error("TestCmd() succeeded with a failing test case")
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshaling JUnit output: %v", err)
+	}
+	if report.Tests != 2 || report.Failures != 1 || len(report.Suites) != 2 {
+		t.Fatalf("unexpected JUnit report %+v", report)
+	}
+	failure := report.Suites[1].Cases[0].Failure
+	if failure == nil || !strings.HasPrefix(failure.Message, "expected the policy to allow the input") {
+		t.Fatalf("unexpected failure %+v", failure)
+	}
+	if !strings.Contains(failure.Body, "Enter data.sigstore.deny") {
+		t.Errorf("failure body %q has no evaluation trace", failure.Body)
+	}
+
+	if err := TestCmd(context.Background(), []string{pass}, "xml", "", &out); err == nil {
+		t.Error("TestCmd() accepted an unknown output format")
+	}
+	if err := TestCmd(context.Background(), []string{pass}, TestOutputText, "verbose", &out); err == nil {
+		t.Error("TestCmd() accepted an unknown explanation mode")
+	}
+}
@@ -32,7 +32,9 @@ import (
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/policy"
 	"github.com/sigstore/cosign/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
 	"github.com/sigstore/cosign/cmd/cosign/cli/upload"
@@ -69,6 +71,8 @@ func Policy() *cobra.Command {
 	cmd.AddCommand(
 		initPolicy(),
 		signPolicy(),
+		testPolicy(),
+		pushPolicy(),
 	)
 
 	return cmd
@@ -159,6 +163,97 @@ func initPolicy() *cobra.Command {
 	return cmd
 }
 
+func testPolicy() *cobra.Command {
+	o := &options.PolicyTestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "test CUE and Rego policies against fixtures.",
+		Long:  "test evaluates the CUE, Rego, JSON schema or CEL policy declared in each test file against the fixtures of its test cases,\nsuch as in-toto statements or DSSE envelopes printed by verify-attestation, and checks that each of them is allowed or denied as expected.",
+		Example: `  cosign policy test [--output text|json|junit] [--explain[=notes|fails|full]] <test file>...
+
+  # run the test cases of a policy
+  cosign policy test policy_test.yaml
+
+  # write a JUnit report of the test cases of several policies for CI
+  cosign policy test --output junit policies/*_test.yaml > report.xml
+
+  # with the test file policy_test.yaml:
+  #   policy:
+  #     type: rego
+  #     file: policy.rego
+  #   tests:
+  #   - name: recent scan
+  #     input: fixtures/recent-scan.json
+  #     expect: allow
+  #   - name: stale scan
+  #     input: fixtures/stale-scan.json
+  #     expect: deny
+  #     errorContains: scan is too old`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return policy.TestCmd(cmd.Context(), args, o.Output, o.Explain, cmd.OutOrStdout())
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func pushPolicy() *cobra.Command {
+	o := &options.PolicyPushOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "push and sign a CUE, Rego, JSON schema or CEL policy.",
+		Long:  "push uploads the policy as an OCI artifact and signs it, so that ClusterImagePolicies can reference it with policy.oci\nand have the policy-controller evaluate it only once its signature is verified with the authority of the policy.\nThe digest of the policy is printed.",
+		Example: `  cosign policy push [--type cue|rego|jsonschema|cel] [--key <key path>|<kms uri>] <policy file> <image uri>
+
+  # push a Rego policy and sign it with a local key pair
+  cosign policy push --key cosign.key policy.rego gcr.io/acme/policies/scans:v1
+
+  # push a CUE policy and sign it keyless
+  COSIGN_EXPERIMENTAL=1 cosign policy push policy.cue gcr.io/acme/policies/provenance:v1
+
+  # push a policy whose type can't be detected from its extension
+  cosign policy push --type cel --key cosign.key policy.txt gcr.io/acme/policies/cel:v1`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ro.Timeout != 0 {
+				var cancelFn context.CancelFunc
+				ctx, cancelFn = context.WithTimeout(ctx, ro.Timeout)
+				defer cancelFn()
+			}
+			oidcClientSecret, err := o.OIDC.ClientSecret()
+			if err != nil {
+				return err
+			}
+			ko := options.KeyOpts{
+				KeyRef:                   o.Key,
+				PassFunc:                 generate.GetPass,
+				Sk:                       o.SecurityKey.Use,
+				Slot:                     o.SecurityKey.Slot,
+				FulcioURL:                o.Fulcio.URL,
+				IDToken:                  o.Fulcio.IdentityToken,
+				InsecureSkipFulcioVerify: o.Fulcio.InsecureSkipFulcioVerify,
+				RekorURL:                 o.Rekor.URL,
+				OIDCIssuer:               o.OIDC.Issuer,
+				OIDCClientID:             o.OIDC.ClientID,
+				OIDCClientSecret:         oidcClientSecret,
+				OIDCRedirectURL:          o.OIDC.RedirectURL,
+				OIDCDisableProviders:     o.OIDC.DisableAmbientProviders,
+			}
+			return policy.PushCmd(ctx, ro, ko, o.Registry, args[0], o.Type, args[1], o.Force, cmd.OutOrStdout())
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
 func signPolicy() *cobra.Command {
 	o := &options.PolicySignOptions{}
 
@@ -15,10 +15,12 @@
 package rekor
 
 import (
+	httptransport "github.com/go-openapi/runtime/client"
 	rekor "github.com/sigstore/rekor/pkg/client"
 	"github.com/sigstore/rekor/pkg/generated/client"
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/retry"
 )
 
 func NewClient(rekorURL string) (*client.Rekor, error) {
@@ -26,5 +28,10 @@ func NewClient(rekorURL string) (*client.Rekor, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The Rekor client doesn't take a transport, retry the requests it sends
+	// with the transport it built.
+	if rt, ok := rekorClient.Transport.(*httptransport.Runtime); ok {
+		rt.Transport = retry.NewTransport(rt.Transport, retry.Default())
+	}
 	return rekorClient, nil
 }
@@ -17,6 +17,7 @@ package rekor
 import (
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
@@ -50,3 +51,28 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("no requests were received")
 	}
 }
+
+func TestNewClientRetries(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	defer testServer.Close()
+
+	client, err := NewClient(testServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.Tlog.GetLogInfo(nil)
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("server called %d times, wanted 2", got)
+	}
+}
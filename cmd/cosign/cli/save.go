@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
@@ -57,12 +58,28 @@ func SaveCmd(ctx context.Context, opts options.SaveOptions, imageRef string) err
 		return fmt.Errorf("signed entity: %w", err)
 	}
 
+	// Named file attachments can only be found by listing the tags of the
+	// repository, which registries may not allow: save the rest anyway.
+	var attachments []string
+	names, err := ociremote.AttachmentNames(ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: unable to list the file attachments of %s: %v\n", ref, err)
+	}
+	for _, attName := range names {
+		switch attName {
+		case ociremote.SignatureTagSuffix, ociremote.AttestationTagSuffix:
+			// Signatures and attestations are always saved.
+		default:
+			attachments = append(attachments, attName)
+		}
+	}
+
 	if _, ok := se.(oci.SignedImage); ok {
 		si, err := ociremote.SignedImage(ref)
 		if err != nil {
 			return fmt.Errorf("getting signed image: %w", err)
 		}
-		return layout.WriteSignedImage(opts.Directory, si)
+		return layout.WriteSignedImage(opts.Directory, si, attachments...)
 	}
 
 	if _, ok := se.(oci.SignedImageIndex); ok {
@@ -70,7 +87,7 @@ func SaveCmd(ctx context.Context, opts options.SaveOptions, imageRef string) err
 		if err != nil {
 			return fmt.Errorf("getting signed image index: %w", err)
 		}
-		return layout.WriteSignedImageIndex(opts.Directory, sii)
+		return layout.WriteSignedImageIndex(opts.Directory, sii, attachments...)
 	}
 	return errors.New("unknown signed entity")
 }
@@ -91,13 +91,16 @@ func GetAttachedImageRef(ref name.Reference, attachment string, opts ...ociremot
 	if attachment == "sbom" {
 		return ociremote.SBOMTag(ref, opts...)
 	}
-	return nil, fmt.Errorf("unknown attachment type %s", attachment)
+	if err := options.ValidateAttachmentName(attachment); err != nil {
+		return nil, fmt.Errorf("unknown attachment type %s: %w", attachment, err)
+	}
+	return ociremote.AttachmentTag(ref, attachment, opts...)
 }
 
 // nolint
 func SignCmd(ro *options.RootOptions, ko options.KeyOpts, regOpts options.RegistryOptions, annotations map[string]interface{},
 	imgs []string, certPath string, certChainPath string, upload bool, outputSignature, outputCertificate string,
-	payloadPath string, force bool, recursive bool, attachment string) error {
+	payloadPath string, force bool, recursive bool, jobs int, attachment string) error {
 	if options.EnableExperimental() {
 		if options.NOf(ko.KeyRef, ko.Sk) > 1 {
 			return &options.KeyParseError{}
@@ -118,6 +121,26 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, regOpts options.Regist
 	defer sv.Close()
 	dd := cremote.NewDupeDetector(sv)
 
+	// The certificate is the same for every image signed.
+	if outputCertificate != "" {
+		rekorBytes, err := sv.Bytes(ctx)
+		if err != nil {
+			return fmt.Errorf("create certificate file: %w", err)
+		}
+
+		if err := os.WriteFile(outputCertificate, rekorBytes, 0600); err != nil {
+			return fmt.Errorf("create certificate file: %w", err)
+		}
+		// TODO: maybe accept a --b64 flag as well?
+		fmt.Printf("Certificate wrote in the file %s\n", outputCertificate)
+	}
+
+	// Security keys and PKCS#11 tokens can't sign several payloads at the
+	// same time.
+	if ko.Sk || strings.HasPrefix(ko.KeyRef, pkcs11key.ReferenceScheme) {
+		jobs = 1
+	}
+
 	var staticPayload []byte
 	if payloadPath != "" {
 		fmt.Fprintln(os.Stderr, "Using payload from:", payloadPath)
@@ -152,7 +175,7 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, regOpts options.Regist
 			if err != nil {
 				return fmt.Errorf("accessing image: %w", err)
 			}
-			err = signDigest(ctx, digest, staticPayload, ko, regOpts, annotations, upload, outputSignature, outputCertificate, force, recursive, dd, sv, se)
+			err = signDigest(ctx, digest, staticPayload, ko, regOpts, annotations, upload, outputSignature, force, recursive, dd, sv, se)
 			if err != nil {
 				return fmt.Errorf("signing digest: %w", err)
 			}
@@ -164,7 +187,10 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, regOpts options.Regist
 			return fmt.Errorf("accessing entity: %w", err)
 		}
 
-		if err := walk.SignedEntity(ctx, se, func(ctx context.Context, se oci.SignedEntity) error {
+		// The images of a multi-arch image are signed up to jobs at a time,
+		// signDigest only writes the signature file and the signatures of the
+		// digest it signs.
+		if err := walk.SignedEntityConcurrently(ctx, se, func(ctx context.Context, se oci.SignedEntity) error {
 			// Get the digest for this entity in our walk.
 			d, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
 			if err != nil {
@@ -172,12 +198,12 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, regOpts options.Regist
 			}
 			digest := ref.Context().Digest(d.String())
 
-			err = signDigest(ctx, digest, staticPayload, ko, regOpts, annotations, upload, outputSignature, outputCertificate, force, recursive, dd, sv, se)
+			err = signDigest(ctx, digest, staticPayload, ko, regOpts, annotations, upload, outputSignature, force, recursive, dd, sv, se)
 			if err != nil {
 				return fmt.Errorf("signing digest: %w", err)
 			}
 			return ErrDone
-		}); err != nil {
+		}, walk.WithJobs(jobs)); err != nil {
 			return fmt.Errorf("recursively signing: %w", err)
 		}
 	}
@@ -185,8 +211,25 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, regOpts options.Regist
 	return nil
 }
 
+// SignDigest signs the image of digest with sv and uploads its signature, for
+// the commands which get the signer before writing the image to sign.
+func SignDigest(ctx context.Context, ko options.KeyOpts, regOpts options.RegistryOptions, digest name.Digest, sv *SignerVerifier) error {
+	opts, err := regOpts.ClientOpts(ctx)
+	if err != nil {
+		return fmt.Errorf("constructing client options: %w", err)
+	}
+	se, err := ociremote.SignedEntity(digest, opts...)
+	if err != nil {
+		return fmt.Errorf("accessing image: %w", err)
+	}
+	if err := signDigest(ctx, digest, nil, ko, regOpts, nil, true, "", false, false, cremote.NewDupeDetector(sv), sv, se); err != nil {
+		return fmt.Errorf("signing digest: %w", err)
+	}
+	return nil
+}
+
 func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko options.KeyOpts,
-	regOpts options.RegistryOptions, annotations map[string]interface{}, upload bool, outputSignature, outputCertificate string, force bool, recursive bool,
+	regOpts options.RegistryOptions, annotations map[string]interface{}, upload bool, outputSignature string, force bool, recursive bool,
 	dd mutate.DupeDetector, sv *SignerVerifier, se oci.SignedEntity) error {
 	var err error
 	// The payload can be passed to skip generation.
@@ -233,19 +276,6 @@ func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko opti
 		}
 	}
 
-	if outputCertificate != "" {
-		rekorBytes, err := sv.Bytes(ctx)
-		if err != nil {
-			return fmt.Errorf("create certificate file: %w", err)
-		}
-
-		if err := os.WriteFile(outputCertificate, rekorBytes, 0600); err != nil {
-			return fmt.Errorf("create certificate file: %w", err)
-		}
-		// TODO: maybe accept a --b64 flag as well?
-		fmt.Printf("Certificate wrote in the file %s\n", outputCertificate)
-	}
-
 	if !upload {
 		return nil
 	}
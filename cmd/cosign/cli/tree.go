@@ -17,28 +17,43 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/spf13/cobra"
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/pkg/oci"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	sigs "github.com/sigstore/cosign/pkg/signature"
 )
 
 func Tree() *cobra.Command {
 	c := &options.TreeOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "tree",
-		Short:   "Display supply chain security related artifacts for an image such as signatures, SBOMs and attestations",
-		Example: "  cosign tree <IMAGE>",
-		Args:    cobra.ExactArgs(1),
+		Use:   "tree",
+		Short: "Display supply chain security related artifacts for an image such as signatures, SBOMs and attestations",
+		Example: `  cosign tree <IMAGE>
+
+  # display the artifacts of a multi-arch image and of each of its images
+  cosign tree --recursive <IMAGE>
+
+  # display the named file attachments too
+  cosign tree --files <IMAGE>
+
+  # display the artifacts as JSON
+  cosign tree --output json <IMAGE>`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return TreeCmd(cmd.Context(), c.Registry, args[0])
+			return TreeCmd(cmd.Context(), *c, args[0])
 		},
 	}
 
@@ -46,97 +61,329 @@ func Tree() *cobra.Command {
 	return cmd
 }
 
-func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef string) error {
-	scsaMap := map[name.Tag][]v1.Layer{}
+// treeNode holds the supply chain security related artifacts of a single manifest.
+type treeNode struct {
+	Reference    string            `json:"reference"`
+	Digest       string            `json:"digest"`
+	MediaType    string            `json:"mediaType"`
+	Platform     *v1.Platform      `json:"platform,omitempty"`
+	Signatures   *treeAttachment   `json:"signatures,omitempty"`
+	Attestations *treeAttachment   `json:"attestations,omitempty"`
+	SBOMs        *treeAttachment   `json:"sboms,omitempty"`
+	Files        []*treeAttachment `json:"files,omitempty"`
+	Children     []*treeNode       `json:"children,omitempty"`
+}
+
+// treeAttachment is the image, stored under Tag, holding a kind of artifact.
+// Name is only set for named file attachments.
+type treeAttachment struct {
+	Name   string      `json:"name,omitempty"`
+	Tag    string      `json:"tag"`
+	Layers []treeLayer `json:"layers"`
+}
+
+// treeLayer is a single artifact: a signature, an attestation, an SBOM or a file.
+type treeLayer struct {
+	Digest        string      `json:"digest"`
+	MediaType     string      `json:"mediaType"`
+	Size          int64       `json:"size"`
+	PredicateType string      `json:"predicateType,omitempty"`
+	Signer        *treeSigner `json:"signer,omitempty"`
+}
+
+// treeSigner is the identity found in the certificate of a keyless signature.
+type treeSigner struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer,omitempty"`
+}
+
+func (n *treeNode) empty() bool {
+	if n.Signatures != nil || n.Attestations != nil || n.SBOMs != nil || len(n.Files) > 0 {
+		return false
+	}
+	for _, child := range n.Children {
+		if !child.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+func TreeCmd(ctx context.Context, opts options.TreeOptions, imageRef string) error {
+	if opts.Output != "text" && opts.Output != "json" {
+		return fmt.Errorf("unsupported output format %q, must be one of text or json", opts.Output)
+	}
+
 	ref, err := name.ParseReference(imageRef)
 	if err != nil {
 		return err
 	}
 
-	remoteOpts, err := regOpts.ClientOpts(ctx)
+	remoteOpts, err := opts.Registry.ClientOpts(ctx)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "📦 Supply Chain Security Related artifacts for an image: %s\n", ref.String())
 
 	simg, err := ociremote.SignedEntity(ref, remoteOpts...)
 	if err != nil {
 		return err
 	}
 
-	attRef, err := ociremote.AttestationTag(ref, remoteOpts...)
+	// The file attachments can only be found by listing the tags of the
+	// repository, which registries may not allow.
+	var files *ociremote.AttachmentLister
+	if opts.Files {
+		files = ociremote.NewAttachmentLister(remoteOpts...)
+	}
+	root, err := treeFor(ref, simg, nil, opts.Recursive, files, remoteOpts...)
 	if err != nil {
 		return err
 	}
 
-	atts, err := simg.Attestations()
-	if err == nil {
-		layers, err := atts.Layers()
+	if opts.Output == "json" {
+		b, err := json.Marshal(root)
 		if err != nil {
 			return err
 		}
-		if len(layers) > 0 {
-			scsaMap[attRef] = layers
-		}
+		fmt.Fprintln(os.Stdout, string(b))
+		return nil
 	}
 
-	sigRef, err := ociremote.SignatureTag(ref, remoteOpts...)
+	fmt.Fprintf(os.Stdout, "📦 Supply Chain Security Related artifacts for an image: %s\n", ref.String())
+	if root.empty() {
+		fmt.Fprintf(os.Stdout, "No Supply Chain Security Related Artifacts artifacts found for image %s\n, start creating one with simply running"+
+			"$ COSIGN_EXPERIMENTAL=1 cosign sign <img>", ref.String())
+		return nil
+	}
+	printNode(os.Stdout, root, "")
+	return nil
+}
+
+// treeFor collects the artifacts attached to se and, when recursive is set,
+// to the manifests it references. The named file attachments are only
+// collected when files is set.
+func treeFor(ref name.Reference, se oci.SignedEntity, platform *v1.Platform, recursive bool, files *ociremote.AttachmentLister, remoteOpts ...ociremote.Option) (*treeNode, error) {
+	node := &treeNode{
+		Reference: ref.String(),
+		Platform:  platform,
+	}
+
+	// Both of the SignedEntity types implement Digest() and MediaType()
+	h, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	node.Digest = h.String()
+	mt, err := se.(interface {
+		MediaType() (types.MediaType, error)
+	}).MediaType()
+	if err != nil {
+		return nil, err
 	}
+	node.MediaType = string(mt)
 
-	sigs, err := simg.Signatures()
-	if err == nil {
-		layers, err := sigs.Layers()
+	sigRef, err := ociremote.SignatureTag(ref, remoteOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if sl, err := se.Signatures(); err == nil {
+		node.Signatures, err = signaturesAttachment(sigRef, sl, false)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if len(layers) > 0 {
-			scsaMap[sigRef] = layers
+	}
+
+	attRef, err := ociremote.AttestationTag(ref, remoteOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if atts, err := se.Attestations(); err == nil {
+		node.Attestations, err = signaturesAttachment(attRef, atts, true)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	sbomRef, err := ociremote.SBOMTag(ref, remoteOpts...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if sboms, err := se.Attachment(ociremote.SBOMTagSuffix); err == nil {
+		node.SBOMs, err = layersAttachment(sbomRef, sboms)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if files != nil {
+		if node.Files, err = filesAttachments(ref.Context().Digest(node.Digest), se, files, remoteOpts...); err != nil {
+			return nil, err
+		}
 	}
 
-	sbombs, err := simg.Attachment(ociremote.SBOMTagSuffix)
-	if err == nil {
-		layers, err := sbombs.Layers()
+	idx, ok := se.(oci.SignedImageIndex)
+	if !ok || !recursive {
+		return node, nil
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range im.Manifests {
+		var child oci.SignedEntity
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err = idx.SignedImageIndex(desc.Digest)
+		case desc.MediaType.IsImage():
+			child, err = idx.SignedImage(desc.Digest)
+		default:
+			continue
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if len(layers) > 0 {
-			scsaMap[sbomRef] = layers
+		childNode, err := treeFor(ref.Context().Digest(desc.Digest.String()), child, desc.Platform, recursive, files, remoteOpts...)
+		if err != nil {
+			return nil, err
 		}
+		node.Children = append(node.Children, childNode)
 	}
+	return node, nil
+}
 
-	if len(scsaMap) == 0 {
-		fmt.Fprintf(os.Stdout, "No Supply Chain Security Related Artifacts artifacts found for image %s\n, start creating one with simply running"+
-			"$ COSIGN_EXPERIMENTAL=1 cosign sign <img>", ref.String())
-		return nil
+// filesAttachments describes the named file attachments of the image at digest.
+func filesAttachments(digest name.Digest, se oci.SignedEntity, files *ociremote.AttachmentLister, remoteOpts ...ociremote.Option) ([]*treeAttachment, error) {
+	names, err := files.Names(digest)
+	if err != nil {
+		return nil, fmt.Errorf("listing the file attachments of %s: %w", digest, err)
 	}
+	var atts []*treeAttachment
+	for _, attName := range names {
+		switch attName {
+		case ociremote.SignatureTagSuffix, ociremote.AttestationTagSuffix, ociremote.SBOMTagSuffix:
+			continue
+		}
+		f, err := se.Attachment(attName)
+		if err != nil {
+			return nil, err
+		}
+		fileRef, err := ociremote.AttachmentTag(digest, attName, remoteOpts...)
+		if err != nil {
+			return nil, err
+		}
+		att, err := layersAttachment(fileRef, f)
+		if err != nil {
+			return nil, err
+		}
+		if att != nil {
+			att.Name = attName
+			atts = append(atts, att)
+		}
+	}
+	return atts, nil
+}
 
-	for t, k := range scsaMap {
-		switch t {
-		case sigRef:
-			fmt.Fprintf(os.Stdout, "└── 🔐 Signatures for an image tag: %s\n", t.String())
-		case sbomRef:
-			fmt.Fprintf(os.Stdout, "└── 📦 SBOMs for an image tag: %s\n", t.String())
-		case attRef:
-			fmt.Fprintf(os.Stdout, "└── 💾 Attestations for an image tag: %s\n", t.String())
+// signaturesAttachment describes the signatures (or attestations) stored under tag.
+// It returns nil if there are none.
+func signaturesAttachment(tag name.Tag, sigList oci.Signatures, attestations bool) (*treeAttachment, error) {
+	sl, err := sigList.Get()
+	if err != nil {
+		return nil, err
+	}
+	if len(sl) == 0 {
+		return nil, nil
+	}
+	att := &treeAttachment{Tag: tag.String()}
+	for _, sig := range sl {
+		layer, err := describeLayer(sig)
+		if err != nil {
+			return nil, err
+		}
+		if cert, err := sig.Cert(); err == nil && cert != nil {
+			layer.Signer = &treeSigner{
+				Subject: sigs.CertSubject(cert),
+				Issuer:  sigs.CertIssuerExtension(cert),
+			}
+		}
+		if attestations {
+			if statement, err := attestation.StatementFromAttestation(sig); err == nil {
+				layer.PredicateType = statement.PredicateType
+			}
 		}
+		att.Layers = append(att.Layers, layer)
+	}
+	return att, nil
+}
 
-		if err := printLayers(k); err != nil {
-			return err
+// layersAttachment describes the layers of the image stored under tag.
+// It returns nil if there are none.
+func layersAttachment(tag name.Tag, img v1.Image) (*treeAttachment, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return nil, nil
+	}
+	att := &treeAttachment{Tag: tag.String()}
+	for _, l := range layers {
+		layer, err := describeLayer(l)
+		if err != nil {
+			return nil, err
 		}
+		att.Layers = append(att.Layers, layer)
 	}
+	return att, nil
+}
 
-	return nil
+func describeLayer(l v1.Layer) (treeLayer, error) {
+	digest, err := l.Digest()
+	if err != nil {
+		return treeLayer{}, err
+	}
+	mt, err := l.MediaType()
+	if err != nil {
+		return treeLayer{}, err
+	}
+	size, err := l.Size()
+	if err != nil {
+		return treeLayer{}, err
+	}
+	return treeLayer{
+		Digest:    digest.String(),
+		MediaType: string(mt),
+		Size:      size,
+	}, nil
 }
 
-func printLayers(layers []v1.Layer) error {
+func printNode(w io.Writer, node *treeNode, indent string) {
+	if node.Signatures != nil {
+		fmt.Fprintf(w, "%s└── 🔐 Signatures for an image tag: %s\n", indent, node.Signatures.Tag)
+		printLayers(w, node.Signatures.Layers, indent)
+	}
+	if node.Attestations != nil {
+		fmt.Fprintf(w, "%s└── 💾 Attestations for an image tag: %s\n", indent, node.Attestations.Tag)
+		printLayers(w, node.Attestations.Layers, indent)
+	}
+	if node.SBOMs != nil {
+		fmt.Fprintf(w, "%s└── 📦 SBOMs for an image tag: %s\n", indent, node.SBOMs.Tag)
+		printLayers(w, node.SBOMs.Layers, indent)
+	}
+	for _, f := range node.Files {
+		fmt.Fprintf(w, "%s└── 📎 File %s for an image tag: %s\n", indent, f.Name, f.Tag)
+		printLayers(w, f.Layers, indent)
+	}
+	for _, child := range node.Children {
+		platform := "unknown platform"
+		if child.Platform != nil {
+			platform = child.Platform.String()
+		}
+		fmt.Fprintf(w, "%s└── 🖼  Image for %s: %s\n", indent, platform, child.Reference)
+		printNode(w, child, indent+"   ")
+	}
+}
+
+func printLayers(w io.Writer, layers []treeLayer, indent string) {
 	for i, l := range layers {
 		last := i == len(layers)-1
 		var sym string
@@ -145,11 +392,6 @@ func printLayers(layers []v1.Layer) error {
 		} else {
 			sym = "   ├──"
 		}
-		digest, err := l.Digest()
-		if err != nil {
-			return err
-		}
-		fmt.Printf("%s 🍒 %s\n", sym, digest)
+		fmt.Fprintf(w, "%s%s 🍒 %s\n", indent, sym, l.Digest)
 	}
-	return nil
 }
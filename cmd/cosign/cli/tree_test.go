@@ -0,0 +1,299 @@
+//
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/oci/signed"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+const vulnPredicateType = "https://cosign.sigstore.dev/attestation/vuln/v1"
+
+// pushSignedIndex writes to ref an index of a linux/amd64 and a linux/arm64
+// image. The index and the amd64 image are signed, and the latter attested.
+func pushSignedIndex(t *testing.T, ref name.Reference) (v1.ImageIndex, map[string]v1.Image) {
+	t.Helper()
+	images := map[string]v1.Image{}
+	idx := v1.ImageIndex(empty.Index)
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(256, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		images[arch] = img
+		idx = ggcrmutate.AppendManifests(idx, ggcrmutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := static.NewSignature([]byte("payload"), base64.StdEncoding.EncodeToString([]byte("signature")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":%q,"subject":[],"predicate":{}}`, vulnPredicateType)
+	envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+		base64.StdEncoding.EncodeToString([]byte(statement)))
+	att, err := static.NewAttestation([]byte(envelope))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedIdx, err := mutate.AttachSignatureToEntity(signed.ImageIndex(idx), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteSignatures(ref.Context(), signedIdx); err != nil {
+		t.Fatal(err)
+	}
+	var amd64 oci.SignedEntity = signed.Image(images["amd64"])
+	if amd64, err = mutate.AttachSignatureToEntity(amd64, sig); err != nil {
+		t.Fatal(err)
+	}
+	if amd64, err = mutate.AttachAttestationToEntity(amd64, att); err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteSignatures(ref.Context(), amd64); err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteAttestations(ref.Context(), amd64); err != nil {
+		t.Fatal(err)
+	}
+	return idx, images
+}
+
+func TestTreeFor(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/repo:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, images := pushSignedIndex(t, ref)
+	idxDigest, err := idx.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	se, err := ociremote.SignedEntity(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := treeFor(ref, se, nil, false, nil)
+	if err != nil {
+		t.Fatalf("treeFor() = %v", err)
+	}
+	if node.Digest != idxDigest.String() || node.Signatures == nil || node.Attestations != nil || len(node.Children) != 0 {
+		t.Errorf("treeFor() = %+v, wanted the signed index without its children", node)
+	}
+
+	node, err = treeFor(ref, se, nil, true, nil)
+	if err != nil {
+		t.Fatalf("treeFor() recursive = %v", err)
+	}
+	if len(node.Children) != 2 {
+		t.Fatalf("treeFor() recursive has %d children, want 2", len(node.Children))
+	}
+	for _, child := range node.Children {
+		arch := child.Platform.Architecture
+		digest, err := images[arch].Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if child.Digest != digest.String() || child.Reference != ref.Context().Digest(digest.String()).String() {
+			t.Errorf("%s child = %s %s, want %s", arch, child.Reference, child.Digest, digest)
+		}
+		if attested := child.Signatures != nil && child.Attestations != nil; attested != (arch == "amd64") {
+			t.Errorf("%s child signed and attested = %v", arch, attested)
+		}
+	}
+	amd64 := node.Children[0]
+	if amd64.Platform.Architecture != "amd64" {
+		amd64 = node.Children[1]
+	}
+	if len(amd64.Attestations.Layers) != 1 || amd64.Attestations.Layers[0].PredicateType != vulnPredicateType {
+		t.Errorf("amd64 attestations = %+v, want a %s attestation", amd64.Attestations, vulnPredicateType)
+	}
+
+	// The JSON output nests the children, with their platform.
+	b, err := json.Marshal(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Digest     string
+		Signatures *struct{ Tag string }
+		Children   []struct {
+			Platform     v1.Platform
+			Attestations *struct {
+				Layers []struct{ PredicateType string }
+			}
+		}
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	wantTag := strings.Replace(idxDigest.String(), ":", "-", 1) + ".sig"
+	if got.Digest != idxDigest.String() || got.Signatures == nil || !strings.HasSuffix(got.Signatures.Tag, wantTag) || len(got.Children) != 2 {
+		t.Errorf("JSON output = %s", b)
+	}
+	for _, child := range got.Children {
+		if attested := child.Attestations != nil; attested != (child.Platform.Architecture == "amd64") {
+			t.Errorf("JSON output attests the %s child = %v: %s", child.Platform.Architecture, attested, b)
+		}
+	}
+}
+
+func TestTreeForFiles(t *testing.T) {
+	lists := 0
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/tags/list") {
+			lists++
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/repo:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, images := pushSignedIndex(t, ref)
+	digest, err := images["amd64"].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notesTag, err := ociremote.AttachmentTag(ref.Context().Digest(digest.String()), "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(notesTag, notes); err != nil {
+		t.Fatal(err)
+	}
+	se, err := ociremote.SignedEntity(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file attachments are only looked for when asked.
+	node, err := treeFor(ref, se, nil, true, nil)
+	if err != nil {
+		t.Fatalf("treeFor() = %v", err)
+	}
+	if lists != 0 {
+		t.Errorf("treeFor() without files listed the tags %d times", lists)
+	}
+
+	node, err = treeFor(ref, se, nil, true, ociremote.NewAttachmentLister())
+	if err != nil {
+		t.Fatalf("treeFor() with files = %v", err)
+	}
+	if lists != 1 {
+		t.Errorf("treeFor() with files listed the tags %d times, wanted once", lists)
+	}
+	for _, child := range node.Children {
+		hasNotes := len(child.Files) == 1 && child.Files[0].Name == "notes" && child.Files[0].Tag == notesTag.String()
+		if hasNotes != (child.Platform.Architecture == "amd64") {
+			t.Errorf("%s child files = %+v", child.Platform.Architecture, child.Files)
+		}
+	}
+	if len(node.Files) != 0 {
+		t.Errorf("index files = %+v, wanted none", node.Files)
+	}
+}
+
+func TestPrintNode(t *testing.T) {
+	layers := func(digests ...string) []treeLayer {
+		var ls []treeLayer
+		for _, d := range digests {
+			ls = append(ls, treeLayer{Digest: d})
+		}
+		return ls
+	}
+	node := &treeNode{
+		Reference:  "example.com/repo:v1",
+		Signatures: &treeAttachment{Tag: "example.com/repo:sha256-idx.sig", Layers: layers("sha256:s1", "sha256:s2")},
+		Files:      []*treeAttachment{{Name: "notes", Tag: "example.com/repo:sha256-idx.notes", Layers: layers("sha256:f1")}},
+		Children: []*treeNode{{
+			Reference:    "example.com/repo@sha256:amd64",
+			Platform:     &v1.Platform{OS: "linux", Architecture: "amd64"},
+			Attestations: &treeAttachment{Tag: "example.com/repo:sha256-amd64.att", Layers: layers("sha256:a1")},
+			SBOMs:        &treeAttachment{Tag: "example.com/repo:sha256-amd64.sbom", Layers: layers("sha256:b1")},
+		}, {
+			Reference: "example.com/repo@sha256:unknown",
+		}},
+	}
+	want := `└── 🔐 Signatures for an image tag: example.com/repo:sha256-idx.sig
+   ├── 🍒 sha256:s1
+   └── 🍒 sha256:s2
+└── 📎 File notes for an image tag: example.com/repo:sha256-idx.notes
+   └── 🍒 sha256:f1
+└── 🖼  Image for linux/amd64: example.com/repo@sha256:amd64
+   └── 💾 Attestations for an image tag: example.com/repo:sha256-amd64.att
+      └── 🍒 sha256:a1
+   └── 📦 SBOMs for an image tag: example.com/repo:sha256-amd64.sbom
+      └── 🍒 sha256:b1
+└── 🖼  Image for unknown platform: example.com/repo@sha256:unknown
+`
+	var b bytes.Buffer
+	printNode(&b, node, "")
+	if b.String() != want {
+		t.Errorf("printNode() =\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	if node.empty() {
+		t.Error("empty() = true for a node with signatures")
+	}
+	if !(&treeNode{Children: []*treeNode{{}}}).empty() {
+		t.Error("empty() = false for a node without artifacts")
+	}
+}
@@ -43,7 +43,11 @@ func BlobCmd(ctx context.Context, regOpts options.RegistryOptions, files []cremo
 		}
 	}
 
-	dgstAddr, err := cremote.UploadFiles(ref, files, mt, regOpts.GetRegistryClientOpts(ctx)...)
+	remoteOpts, err := regOpts.RegistryClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+	dgstAddr, err := cremote.UploadFiles(ref, files, mt, remoteOpts...)
 	if err != nil {
 		return err
 	}
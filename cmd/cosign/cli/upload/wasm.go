@@ -42,5 +42,9 @@ func WasmCmd(ctx context.Context, regOpts options.RegistryOptions, wasmPath, ima
 	if err != nil {
 		return err
 	}
-	return remote.Write(ref, img, regOpts.GetRegistryClientOpts(ctx)...)
+	remoteOpts, err := regOpts.RegistryClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+	return remote.Write(ref, img, remoteOpts...)
 }
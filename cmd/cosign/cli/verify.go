@@ -22,6 +22,7 @@ import (
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
 )
 
 func Verify() *cobra.Command {
@@ -78,7 +79,10 @@ against the transparency log.`,
   cosign verify --key gitlab://[OWNER]/[PROJECT_NAME] <IMAGE>
 
   # verify image with public key stored in GitLab with project id
-  cosign verify --key gitlab://[PROJECT_ID] <IMAGE>`,
+  cosign verify --key gitlab://[PROJECT_ID] <IMAGE>
+
+  # verify image and validate its signatures based on Rego or CUE policies
+  cosign verify --policy <REGO_POLICY> --policy <CUE_POLICY> <IMAGE>`,
 
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -110,6 +114,8 @@ against the transparency log.`,
 				HashAlgorithm:   hashAlgorithm,
 				SignatureRef:    o.SignatureRef,
 				LocalImage:      o.LocalImage,
+				Policies:        o.Policies,
+				RegoOptions:     regoOptions(o.Rego),
 			}
 
 			return v.Exec(cmd.Context(), args)
@@ -167,7 +173,16 @@ against the transparency log.`,
   cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <REGO_POLICY> <IMAGE>
 
   # verify image with public key and validate attestation based on CUE policy
-  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <CUE_POLICY> <IMAGE>`,
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <CUE_POLICY> <IMAGE>
+
+  # verify image with public key and explain why the attestation fails the Rego policy
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <REGO_POLICY> --explain <IMAGE>
+
+  # verify image with public key and print a summary of the decoded attestations
+  cosign verify-attestation --key cosign.pub --output text <IMAGE>
+
+  # verify image with public key and print only the predicates of the given type
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --output predicate <IMAGE>`,
 
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -186,7 +201,10 @@ against the transparency log.`,
 				RekorURL:        o.Rekor.URL,
 				PredicateType:   o.Predicate.Type,
 				Policies:        o.Policies,
+				RegoOptions:     regoOptions(o.Rego),
 				LocalImage:      o.LocalImage,
+				MaxAge:          o.MaxAge,
+				Explain:         o.Explain,
 			}
 			return v.Exec(cmd.Context(), args)
 		},
@@ -196,6 +214,59 @@ against the transparency log.`,
 	return cmd
 }
 
+func VerifySBOM() *cobra.Command {
+	o := &options.VerifySBOMOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-sbom",
+		Short: "Verify the signature on the SBOM attached to the supplied container image and output the SBOM",
+		Long: `Verify the signature on the SBOM attached to an image, then write the SBOM
+to stdout. Nothing is written if the signature cannot be verified.`,
+		Example: `  cosign verify-sbom --key <key path>|<key url>|<kms uri> <image uri>
+
+  # verify the SBOM of an image with a public key and save it
+  cosign verify-sbom --key cosign.pub <IMAGE> > sbom.spdx
+
+  # (experimental) verify the SBOM of an image signed with Google sign-in
+  COSIGN_EXPERIMENTAL=1 cosign verify-sbom --certificate-email user@example.com --certificate-oidc-issuer https://accounts.google.com <IMAGE>`,
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			annotations, err := o.AnnotationsMap()
+			if err != nil {
+				return err
+			}
+
+			hashAlgorithm, err := o.SignatureDigest.HashAlgorithm()
+			if err != nil {
+				return err
+			}
+
+			v := verify.VerifySBOMCommand{
+				VerifyCommand: verify.VerifyCommand{
+					RegistryOptions: o.Registry,
+					CheckClaims:     o.CheckClaims,
+					KeyRef:          o.Key,
+					CertRef:         o.CertVerify.Cert,
+					CertEmail:       o.CertVerify.CertEmail,
+					CertOidcIssuer:  o.CertVerify.CertOidcIssuer,
+					CertChain:       o.CertVerify.CertChain,
+					EnforceSCT:      o.CertVerify.EnforceSCT,
+					Sk:              o.SecurityKey.Use,
+					Slot:            o.SecurityKey.Slot,
+					RekorURL:        o.Rekor.URL,
+					Annotations:     annotations,
+					HashAlgorithm:   hashAlgorithm,
+				},
+			}
+			return v.Exec(cmd.Context(), args[0], cmd.OutOrStdout())
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
 func VerifyBlob() *cobra.Command {
 	o := &options.VerifyBlobOptions{}
 
@@ -268,3 +339,11 @@ The blob may be specified as a path to a file or - for stdin.`,
 	o.AddFlags(cmd)
 	return cmd
 }
+
+func regoOptions(o options.RegoOptions) rego.Options {
+	return rego.Options{
+		Query:   o.Query,
+		Data:    o.Data,
+		Bundles: o.Bundles,
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	sigs "github.com/sigstore/cosign/pkg/signature"
+)
+
+// The output formats of verify-attestation.
+const (
+	// AttestationOutputJSON prints the DSSE envelopes as they are stored.
+	AttestationOutputJSON = "json"
+	// AttestationOutputText prints a human-readable summary of each attestation.
+	AttestationOutputText = "text"
+	// AttestationOutputDecoded prints the attestations as JSON, with their
+	// in-toto statement decoded.
+	AttestationOutputDecoded = "decoded"
+	// AttestationOutputPredicate prints only the predicates of the
+	// attestations of the requested type.
+	AttestationOutputPredicate = "predicate"
+)
+
+// AttestationOutputs lists the supported output formats of verify-attestation.
+var AttestationOutputs = []string{AttestationOutputJSON, AttestationOutputText, AttestationOutputDecoded, AttestationOutputPredicate}
+
+func validateAttestationOutput(output string) error {
+	for _, o := range AttestationOutputs {
+		if o == output {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q, expected one of %s", output, strings.Join(AttestationOutputs, ", "))
+}
+
+// DecodedAttestation is a verified attestation with its in-toto statement
+// decoded from the DSSE envelope.
+type DecodedAttestation struct {
+	PayloadType string             `json:"payloadType"`
+	Statement   in_toto.Statement  `json:"statement"`
+	Signer      *AttestationSigner `json:"signer,omitempty"`
+	Tlog        *AttestationTlog   `json:"tlog,omitempty"`
+}
+
+// AttestationSigner is the identity in the certificate of an attestation.
+type AttestationSigner struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer,omitempty"`
+}
+
+// AttestationTlog describes the transparency log entry of an attestation.
+type AttestationTlog struct {
+	LogID          string    `json:"logID"`
+	LogIndex       int64     `json:"logIndex"`
+	IntegratedTime time.Time `json:"integratedTime"`
+}
+
+// DecodeAttestation decodes the in-toto statement of the attestation, along
+// with its signer and transparency log entry when it has them.
+func DecodeAttestation(att oci.Signature) (*DecodedAttestation, error) {
+	p, err := att.Payload()
+	if err != nil {
+		return nil, fmt.Errorf("getting payload: %w", err)
+	}
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}
+	if err := json.Unmarshal(p, &envelope); err != nil {
+		return nil, fmt.Errorf("unmarshaling DSSE envelope: %w", err)
+	}
+	statement, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+	decoded := &DecodedAttestation{PayloadType: envelope.PayloadType}
+	if err := json.Unmarshal(statement, &decoded.Statement); err != nil {
+		return nil, fmt.Errorf("unmarshaling in-toto statement: %w", err)
+	}
+
+	if cert, err := att.Cert(); err == nil && cert != nil {
+		decoded.Signer = &AttestationSigner{
+			Subject: sigs.CertSubject(cert),
+			Issuer:  sigs.CertIssuerExtension(cert),
+		}
+	}
+	if b, err := att.Bundle(); err == nil && b != nil {
+		decoded.Tlog = &AttestationTlog{
+			LogID:          b.Payload.LogID,
+			LogIndex:       b.Payload.LogIndex,
+			IntegratedTime: time.Unix(b.Payload.IntegratedTime, 0).UTC(),
+		}
+	}
+	return decoded, nil
+}
+
+// PrintAttestations writes the verified attestations to w in the given output
+// format. In the predicate format, only the predicates of the attestations
+// whose type is predicateURI are written, one JSON document per line. In the
+// JSON format, the certificate identities are printed to stderr.
+func PrintAttestations(w io.Writer, verified []oci.Signature, output string, predicateURI string) error {
+	if output == AttestationOutputJSON {
+		for _, att := range verified {
+			// The identity of keyless signers goes to stderr, as it always
+			// did, so that stdout only holds the envelopes.
+			if cert, err := att.Cert(); err == nil && cert != nil {
+				fmt.Fprintln(os.Stderr, "Certificate subject: ", sigs.CertSubject(cert))
+				if issuerURL := sigs.CertIssuerExtension(cert); issuerURL != "" {
+					fmt.Fprintln(os.Stderr, "Certificate issuer URL: ", issuerURL)
+				}
+			}
+			p, err := att.Payload()
+			if err != nil {
+				return fmt.Errorf("getting payload: %w", err)
+			}
+			fmt.Fprintln(w, string(p))
+		}
+		return nil
+	}
+
+	decoded := make([]*DecodedAttestation, 0, len(verified))
+	for _, att := range verified {
+		d, err := DecodeAttestation(att)
+		if err != nil {
+			return err
+		}
+		decoded = append(decoded, d)
+	}
+
+	switch output {
+	case AttestationOutputDecoded:
+		b, err := json.MarshalIndent(decoded, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	case AttestationOutputPredicate:
+		for _, d := range decoded {
+			if d.Statement.PredicateType != predicateURI {
+				continue
+			}
+			b, err := json.Marshal(d.Statement.Predicate)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(b))
+		}
+	case AttestationOutputText:
+		for i, d := range decoded {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if err := printAttestationText(w, d); err != nil {
+				return err
+			}
+		}
+	default:
+		return validateAttestationOutput(output)
+	}
+	return nil
+}
+
+func printAttestationText(w io.Writer, d *DecodedAttestation) error {
+	fmt.Fprintf(w, "Predicate type: %s\n", d.Statement.PredicateType)
+	fmt.Fprintln(w, "Subjects:")
+	for _, s := range d.Statement.Subject {
+		algs := make([]string, 0, len(s.Digest))
+		for alg := range s.Digest {
+			algs = append(algs, alg)
+		}
+		sort.Strings(algs)
+		for _, alg := range algs {
+			fmt.Fprintf(w, "  %s@%s:%s\n", s.Name, alg, s.Digest[alg])
+		}
+	}
+	if d.Signer != nil {
+		fmt.Fprintf(w, "Certificate subject: %s\n", d.Signer.Subject)
+		if d.Signer.Issuer != "" {
+			fmt.Fprintf(w, "Certificate issuer URL: %s\n", d.Signer.Issuer)
+		}
+	}
+	if d.Tlog != nil {
+		fmt.Fprintf(w, "Transparency log entry: %d (log %s), integrated at %s\n",
+			d.Tlog.LogIndex, d.Tlog.LogID, d.Tlog.IntegratedTime.Format(time.RFC3339))
+	}
+
+	b, err := json.Marshal(d.Statement.Predicate)
+	if err != nil {
+		return err
+	}
+	var predicate bytes.Buffer
+	if err := json.Indent(&predicate, b, "  ", "  "); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Predicate:\n  %s\n", predicate.String())
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/cosign/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+const testDigest = "a1b2c3"
+
+func testAttestation(t *testing.T, predicateType, predicate string) oci.Signature {
+	t.Helper()
+	statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":%q,`+
+		`"subject":[{"name":"example.com/app","digest":{"sha256":%q}}],"predicate":%s}`, predicateType, testDigest, predicate)
+	envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+		base64.StdEncoding.EncodeToString([]byte(statement)))
+	att, err := static.NewAttestation([]byte(envelope), static.WithBundle(&bundle.RekorBundle{
+		Payload: bundle.RekorPayload{LogIndex: 42, LogID: "c0ffee", IntegratedTime: 1660000000},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return att
+}
+
+func TestPrintAttestations(t *testing.T) {
+	verified := []oci.Signature{
+		testAttestation(t, "https://slsa.dev/provenance/v0.2", `{"builder":{"id":"https://example.com/builder"}}`),
+		testAttestation(t, "cosign.sigstore.dev/attestation/v1", `{"Data":"hello"}`),
+	}
+
+	var out bytes.Buffer
+	if err := PrintAttestations(&out, verified, AttestationOutputDecoded, ""); err != nil {
+		t.Fatalf("PrintAttestations(decoded) = %v", err)
+	}
+	var decoded []DecodedAttestation
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoded output is not JSON: %v", err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("decoded %d attestations, wanted 2", len(decoded))
+	}
+	if got := decoded[0].Statement.PredicateType; got != "https://slsa.dev/provenance/v0.2" {
+		t.Errorf("predicate type = %s", got)
+	}
+	if got := decoded[0].Statement.Subject[0].Digest["sha256"]; got != testDigest {
+		t.Errorf("subject digest = %s, wanted %s", got, testDigest)
+	}
+	if decoded[0].Tlog == nil || decoded[0].Tlog.LogIndex != 42 {
+		t.Errorf("tlog = %+v, wanted log index 42", decoded[0].Tlog)
+	}
+
+	out.Reset()
+	if err := PrintAttestations(&out, verified, AttestationOutputPredicate, "cosign.sigstore.dev/attestation/v1"); err != nil {
+		t.Fatalf("PrintAttestations(predicate) = %v", err)
+	}
+	if got, want := out.String(), "{\"Data\":\"hello\"}\n"; got != want {
+		t.Errorf("predicate output = %q, wanted %q", got, want)
+	}
+
+	out.Reset()
+	if err := PrintAttestations(&out, verified, AttestationOutputText, ""); err != nil {
+		t.Fatalf("PrintAttestations(text) = %v", err)
+	}
+	for _, want := range []string{
+		"Predicate type: https://slsa.dev/provenance/v0.2",
+		"example.com/app@sha256:" + testDigest,
+		"Transparency log entry: 42 (log c0ffee), integrated at 2022-08-08T23:06:40Z",
+		`"id": "https://example.com/builder"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("text output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := PrintAttestations(&out, verified, AttestationOutputJSON, ""); err != nil {
+		t.Fatalf("PrintAttestations(json) = %v", err)
+	}
+	if !strings.Contains(out.String(), `"payloadType":"application/vnd.in-toto+json"`) {
+		t.Errorf("json output is not the envelopes:\n%s", out.String())
+	}
+
+	if err := PrintAttestations(&out, verified, "yaml", ""); err == nil {
+		t.Error("PrintAttestations() succeeded with an invalid output format")
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cueerrors "cuelang.org/go/cue/errors"
+
+	"github.com/sigstore/cosign/pkg/cosign/cel"
+	"github.com/sigstore/cosign/pkg/cosign/cue"
+	"github.com/sigstore/cosign/pkg/cosign/jsonschema"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/policy"
+)
+
+// policyFiles are the CUE, Rego, JSON schema and CEL policies passed with
+// --policy.
+type policyFiles struct {
+	cue        []string
+	rego       []string
+	jsonSchema []string
+	cel        []string
+	regoOpts   rego.Options
+}
+
+func newPolicyFiles(policies []string, regoOpts rego.Options) (*policyFiles, error) {
+	pf := &policyFiles{regoOpts: regoOpts}
+	for _, policy := range policies {
+		switch filepath.Ext(policy) {
+		case ".rego":
+			pf.rego = append(pf.rego, policy)
+		case ".cue":
+			pf.cue = append(pf.cue, policy)
+		case ".json":
+			pf.jsonSchema = append(pf.jsonSchema, policy)
+		case ".cel":
+			pf.cel = append(pf.cel, policy)
+		default:
+			return nil, errors.New("invalid policy format, expected .cue, .rego, .json or .cel")
+		}
+	}
+	return pf, nil
+}
+
+// hasRego reports whether there are Rego policies, either passed with
+// --policy or in bundles.
+func (pf *policyFiles) hasRego() bool {
+	return len(pf.rego) > 0 || len(pf.regoOpts.Bundles) > 0
+}
+
+// empty reports whether there are no policies to evaluate.
+func (pf *policyFiles) empty() bool {
+	return len(pf.cue) == 0 && !pf.hasRego() && len(pf.jsonSchema) == 0 && len(pf.cel) == 0
+}
+
+// validate evaluates the policies against payload, returning the errors of
+// those which failed. When they are explained, the explanations are printed.
+func (pf *policyFiles) validate(payload []byte) []error {
+	var validationErrors []error
+	if len(pf.cue) > 0 {
+		fmt.Fprintf(os.Stderr, "will be validating against CUE policies: %v\n", pf.cue)
+		cueValidationErr := cue.ValidateJSON(payload, pf.cue)
+		if cueValidationErr != nil {
+			validationErrors = append(validationErrors, cueValidationErr)
+			if pf.regoOpts.Explain != "" {
+				fmt.Fprintf(os.Stderr, "CUE policies failed with:\n%s", cueerrors.Details(cueValidationErr, nil))
+			}
+		}
+	}
+
+	if pf.hasRego() {
+		fmt.Fprintf(os.Stderr, "will be validating against Rego policies: %v\n", append(append([]string{}, pf.rego...), pf.regoOpts.Bundles...))
+		for _, err := range rego.ValidateJSONWithOptions(payload, pf.rego, pf.regoOpts) {
+			var explanation *rego.Explanation
+			if errors.As(err, &explanation) {
+				fmt.Fprintf(os.Stderr, "Rego policies failed with the %s evaluation trace:\n%s", pf.regoOpts.Explain, explanation.Trace)
+				continue
+			}
+			validationErrors = append(validationErrors, err)
+		}
+	}
+
+	if len(pf.jsonSchema) > 0 {
+		fmt.Fprintf(os.Stderr, "will be validating against JSON schemas: %v\n", pf.jsonSchema)
+		validationErrors = append(validationErrors, validateFiles(payload, pf.jsonSchema, jsonschema.ValidateJSON)...)
+	}
+
+	if len(pf.cel) > 0 {
+		fmt.Fprintf(os.Stderr, "will be validating against CEL expressions: %v\n", pf.cel)
+		validationErrors = append(validationErrors, validateFiles(payload, pf.cel, cel.ValidateJSON)...)
+	}
+	return validationErrors
+}
+
+// validateFiles validates payload against the policy in each of the files.
+func validateFiles(payload []byte, files []string, validateJSON func(jsonBody []byte, policy string) error) []error {
+	var validationErrors []error
+	for _, f := range files {
+		policy, err := os.ReadFile(f)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("reading policy: %w", err))
+			continue
+		}
+		if err := validateJSON(payload, string(policy)); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: %w", f, err))
+		}
+	}
+	return validationErrors
+}
+
+// validateSignaturePolicies evaluates the policies against each of the
+// verified signatures.
+func validateSignaturePolicies(pf *policyFiles, verified []oci.Signature) error {
+	if pf.empty() {
+		return nil
+	}
+	var validationErrors []error
+	for _, sig := range verified {
+		payload, err := policy.SignatureToPayloadJSON(sig)
+		if err != nil {
+			return fmt.Errorf("converting to consumable policy validation: %w", err)
+		}
+		validationErrors = append(validationErrors, pf.validate(payload)...)
+	}
+	return reportValidationErrors(validationErrors)
+}
+
+// reportValidationErrors prints the errors of the policies which failed, and
+// returns an error if there are any.
+func reportValidationErrors(validationErrors []error) error {
+	if len(validationErrors) == 0 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "There are %d number of errors occurred during the validation:\n", len(validationErrors))
+	for _, v := range validationErrors {
+		_, _ = fmt.Fprintf(os.Stderr, "- %v\n", v)
+	}
+	return fmt.Errorf("%d validation errors occurred", len(validationErrors))
+}
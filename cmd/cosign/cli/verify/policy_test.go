@@ -0,0 +1,64 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/cosign/pkg/cosign/rego"
+)
+
+func TestPolicyFilesValidate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	schema := write("vuln.json", `{"properties": {"predicateType": {"const": "cosign.sigstore.dev/attestation/vuln/v1"}}}`)
+	expression := write("vuln.cel", `input.predicate.scanner.result.criticals == 0`)
+
+	if _, err := newPolicyFiles([]string{"policy.yaml"}, rego.Options{}); err == nil {
+		t.Error("newPolicyFiles() accepted a .yaml policy")
+	}
+	pf, err := newPolicyFiles([]string{schema, expression}, rego.Options{})
+	if err != nil {
+		t.Fatalf("newPolicyFiles() = %v", err)
+	}
+	if pf.empty() {
+		t.Fatal("policy files are empty")
+	}
+
+	passing := `{"predicateType": "cosign.sigstore.dev/attestation/vuln/v1", "predicate": {"scanner": {"result": {"criticals": 0}}}}`
+	if errs := pf.validate([]byte(passing)); len(errs) != 0 {
+		t.Errorf("validate() = %v", errs)
+	}
+
+	failing := `{"predicateType": "cosign.sigstore.dev/attestation/v1", "predicate": {"scanner": {"result": {"criticals": 1}}}}`
+	errs := pf.validate([]byte(failing))
+	if len(errs) != 2 {
+		t.Fatalf("validate() = %v, want 2 errors", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), schema+": does not match the schema: predicateType") {
+		t.Errorf("unexpected JSON schema error %v", errs[0])
+	}
+	if !strings.HasPrefix(errs[1].Error(), expression+": expression") {
+		t.Errorf("unexpected CEL error %v", errs[1])
+	}
+}
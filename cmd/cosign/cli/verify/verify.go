@@ -37,6 +37,7 @@ import (
 	"github.com/sigstore/cosign/pkg/cosign"
 	"github.com/sigstore/cosign/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
 	"github.com/sigstore/cosign/pkg/oci"
 	sigs "github.com/sigstore/cosign/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
@@ -64,6 +65,8 @@ type VerifyCommand struct {
 	SignatureRef   string
 	HashAlgorithm  crypto.Hash
 	LocalImage     bool
+	Policies       []string
+	RegoOptions    rego.Options
 }
 
 // Exec runs the verification command
@@ -72,26 +75,79 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		return flag.ErrHelp
 	}
 
-	switch c.Attachment {
-	case "sbom", "":
-		break
-	default:
-		return flag.ErrHelp
-	}
-
 	// always default to sha256 if the algorithm hasn't been explicitly set
 	if c.HashAlgorithm == 0 {
 		c.HashAlgorithm = crypto.SHA256
 	}
 
+	policies, err := newPolicyFiles(c.Policies, c.RegoOptions)
+	if err != nil {
+		return err
+	}
+
+	co, closeKey, err := c.checkOpts(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeKey()
+	ociremoteOpts := co.RegistryClientOpts
+
+	// NB: There are only 2 kinds of verification right now:
+	// 1. You gave us the public key explicitly to verify against so co.SigVerifier is non-nil or,
+	// 2. We're going to find an x509 certificate on the signature and verify against Fulcio root trust
+	// TODO(nsmith5): Refactor this verification logic to pass back _how_ verification
+	// was performed so we don't need to use this fragile logic here.
+	fulcioVerified := (co.SigVerifier == nil)
+
+	for _, img := range images {
+		if c.LocalImage {
+			verified, bundleVerified, err := cosign.VerifyLocalImageSignatures(ctx, img, co)
+			if err != nil {
+				return err
+			}
+			if err := validateSignaturePolicies(policies, verified); err != nil {
+				return err
+			}
+			PrintVerificationHeader(img, co, bundleVerified, fulcioVerified)
+			PrintVerification(img, verified, c.Output)
+		} else {
+			ref, err := name.ParseReference(img)
+			if err != nil {
+				return fmt.Errorf("parsing reference: %w", err)
+			}
+			ref, err = sign.GetAttachedImageRef(ref, c.Attachment, ociremoteOpts...)
+			if err != nil {
+				return fmt.Errorf("resolving attachment type %s for image %s: %w", c.Attachment, img, err)
+			}
+
+			verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, ref, co)
+			if err != nil {
+				return err
+			}
+			if err := validateSignaturePolicies(policies, verified); err != nil {
+				return err
+			}
+
+			PrintVerificationHeader(ref.Name(), co, bundleVerified, fulcioVerified)
+			PrintVerification(ref.Name(), verified, c.Output)
+		}
+	}
+
+	return nil
+}
+
+// checkOpts builds the options to verify signatures with. The returned
+// function releases the key (such as a hardware token) used to verify them.
+func (c *VerifyCommand) checkOpts(ctx context.Context) (co *cosign.CheckOpts, closeKey func(), err error) {
+	closeKey = func() {}
 	if !options.OneOf(c.KeyRef, c.CertRef, c.Sk) && !options.EnableExperimental() {
-		return &options.PubKeyParseError{}
+		return nil, nil, &options.PubKeyParseError{}
 	}
 	ociremoteOpts, err := c.ClientOpts(ctx)
 	if err != nil {
-		return fmt.Errorf("constructing client options: %w", err)
+		return nil, nil, fmt.Errorf("constructing client options: %w", err)
 	}
-	co := &cosign.CheckOpts{
+	co = &cosign.CheckOpts{
 		Annotations:        c.Annotations.Annotations,
 		RegistryClientOpts: ociremoteOpts,
 		CertEmail:          c.CertEmail,
@@ -106,7 +162,7 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		if c.RekorURL != "" {
 			rekorClient, err := rekor.NewClient(c.RekorURL)
 			if err != nil {
-				return fmt.Errorf("creating Rekor client: %w", err)
+				return nil, nil, fmt.Errorf("creating Rekor client: %w", err)
 			}
 			co.RekorClient = rekorClient
 		}
@@ -122,86 +178,51 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 	case keyRef != "":
 		pubKey, err = sigs.PublicKeyFromKeyRefWithHashAlgo(ctx, keyRef, c.HashAlgorithm)
 		if err != nil {
-			return fmt.Errorf("loading public key: %w", err)
+			return nil, nil, fmt.Errorf("loading public key: %w", err)
 		}
 		pkcs11Key, ok := pubKey.(*pkcs11key.Key)
 		if ok {
-			defer pkcs11Key.Close()
+			closeKey = pkcs11Key.Close
 		}
 	case c.Sk:
 		sk, err := pivkey.GetKeyWithSlot(c.Slot)
 		if err != nil {
-			return fmt.Errorf("opening piv token: %w", err)
+			return nil, nil, fmt.Errorf("opening piv token: %w", err)
 		}
-		defer sk.Close()
+		closeKey = sk.Close
 		pubKey, err = sk.Verifier()
 		if err != nil {
-			return fmt.Errorf("initializing piv token verifier: %w", err)
+			sk.Close()
+			return nil, nil, fmt.Errorf("initializing piv token verifier: %w", err)
 		}
 	case certRef != "":
 		cert, err := loadCertFromFileOrURL(c.CertRef)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		if c.CertChain == "" {
 			err = cosign.CheckCertificatePolicy(cert, co)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 			pubKey, err = signature.LoadVerifier(cert.PublicKey, crypto.SHA256)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 		} else {
 			// Verify certificate with chain
 			chain, err := loadCertChainFromFileOrURL(c.CertChain)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 			pubKey, err = cosign.ValidateAndUnpackCertWithChain(cert, chain, co)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 		}
 	}
 	co.SigVerifier = pubKey
-
-	// NB: There are only 2 kinds of verification right now:
-	// 1. You gave us the public key explicitly to verify against so co.SigVerifier is non-nil or,
-	// 2. We're going to find an x509 certificate on the signature and verify against Fulcio root trust
-	// TODO(nsmith5): Refactor this verification logic to pass back _how_ verification
-	// was performed so we don't need to use this fragile logic here.
-	fulcioVerified := (co.SigVerifier == nil)
-
-	for _, img := range images {
-		if c.LocalImage {
-			verified, bundleVerified, err := cosign.VerifyLocalImageSignatures(ctx, img, co)
-			if err != nil {
-				return err
-			}
-			PrintVerificationHeader(img, co, bundleVerified, fulcioVerified)
-			PrintVerification(img, verified, c.Output)
-		} else {
-			ref, err := name.ParseReference(img)
-			if err != nil {
-				return fmt.Errorf("parsing reference: %w", err)
-			}
-			ref, err = sign.GetAttachedImageRef(ref, c.Attachment, ociremoteOpts...)
-			if err != nil {
-				return fmt.Errorf("resolving attachment type %s for image %s: %w", c.Attachment, img, err)
-			}
-
-			verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, ref, co)
-			if err != nil {
-				return err
-			}
-
-			PrintVerificationHeader(ref.Name(), co, bundleVerified, fulcioVerified)
-			PrintVerification(ref.Name(), verified, c.Output)
-		}
-	}
-
-	return nil
+	return co, closeKey, nil
 }
 
 func PrintVerificationHeader(imgRef string, co *cosign.CheckOpts, bundleVerified, fulcioVerified bool) {
@@ -18,11 +18,10 @@ package verify
 import (
 	"context"
 	"crypto"
-	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
@@ -34,7 +33,6 @@ import (
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/pkg/cosign"
-	"github.com/sigstore/cosign/pkg/cosign/cue"
 	"github.com/sigstore/cosign/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/pkg/policy"
 	sigs "github.com/sigstore/cosign/pkg/signature"
@@ -57,7 +55,10 @@ type VerifyAttestationCommand struct {
 	RekorURL       string
 	PredicateType  string
 	Policies       []string
+	RegoOptions    rego.Options
 	LocalImage     bool
+	MaxAge         time.Duration
+	Explain        string
 }
 
 // Exec runs the verification command
@@ -70,6 +71,26 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		return &options.PubKeyParseError{}
 	}
 
+	if c.Explain != "" {
+		if err := rego.ValidateExplainMode(c.Explain); err != nil {
+			return err
+		}
+	}
+
+	output := c.Output
+	if output == "" {
+		output = AttestationOutputJSON
+	}
+	if err := validateAttestationOutput(output); err != nil {
+		return err
+	}
+	var predicateURI string
+	if output == AttestationOutputPredicate {
+		if predicateURI, err = options.ParsePredicateType(c.PredicateType); err != nil {
+			return err
+		}
+	}
+
 	ociremoteOpts, err := c.ClientOpts(ctx)
 	if err != nil {
 		return fmt.Errorf("constructing client options: %w", err)
@@ -79,6 +100,7 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		CertEmail:          c.CertEmail,
 		CertOidcIssuer:     c.CertOidcIssuer,
 		EnforceSCT:         c.EnforceSCT,
+		MaxAttestationAge:  c.MaxAge,
 	}
 	if c.CheckClaims {
 		co.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
@@ -172,20 +194,15 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 			}
 		}
 
-		var cuePolicies, regoPolicies []string
-
-		for _, policy := range c.Policies {
-			switch filepath.Ext(policy) {
-			case ".rego":
-				regoPolicies = append(regoPolicies, policy)
-			case ".cue":
-				cuePolicies = append(cuePolicies, policy)
-			default:
-				return errors.New("invalid policy format, expected .cue or .rego")
-			}
+		regoOpts := c.RegoOptions
+		regoOpts.Explain = c.Explain
+		policies, err := newPolicyFiles(c.Policies, regoOpts)
+		if err != nil {
+			return err
 		}
 
 		var validationErrors []error
+		var matched int
 		for _, vp := range verified {
 			payload, err := policy.AttestationToPayloadJSON(ctx, c.PredicateType, vp)
 			if err != nil {
@@ -195,36 +212,25 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 				// This is not the predicate type we're looking for.
 				continue
 			}
+			matched++
+			validationErrors = append(validationErrors, policies.validate(payload)...)
+		}
 
-			if len(cuePolicies) > 0 {
-				fmt.Fprintf(os.Stderr, "will be validating against CUE policies: %v\n", cuePolicies)
-				cueValidationErr := cue.ValidateJSON(payload, cuePolicies)
-				if cueValidationErr != nil {
-					validationErrors = append(validationErrors, cueValidationErr)
-				}
-			}
-
-			if len(regoPolicies) > 0 {
-				fmt.Fprintf(os.Stderr, "will be validating against Rego policies: %v\n", regoPolicies)
-				regoValidationErrs := rego.ValidateJSON(payload, regoPolicies)
-				if len(regoValidationErrs) > 0 {
-					validationErrors = append(validationErrors, regoValidationErrs...)
-				}
-			}
+		// The attestations too old were dropped, make sure one of the wanted
+		// type is left.
+		if c.MaxAge > 0 && matched == 0 {
+			return fmt.Errorf("no %s attestation produced within the last %s", c.PredicateType, c.MaxAge)
 		}
 
-		if len(validationErrors) > 0 {
-			fmt.Fprintf(os.Stderr, "There are %d number of errors occurred during the validation:\n", len(validationErrors))
-			for _, v := range validationErrors {
-				_, _ = fmt.Fprintf(os.Stderr, "- %v\n", v)
-			}
-			return fmt.Errorf("%d validation errors occurred", len(validationErrors))
+		if err := reportValidationErrors(validationErrors); err != nil {
+			return err
 		}
 
 		// TODO: add CUE validation report to `PrintVerificationHeader`.
 		PrintVerificationHeader(imageRef, co, bundleVerified, fulcioVerified)
-		// The attestations are always JSON, so use the raw "text" mode for outputting them instead of conversion
-		PrintVerification(imageRef, verified, "text")
+		if err := PrintAttestations(os.Stdout, verified, output, predicateURI); err != nil {
+			return err
+		}
 	}
 
 	return nil
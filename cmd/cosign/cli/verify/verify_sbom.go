@@ -0,0 +1,107 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/sbom"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	sigs "github.com/sigstore/cosign/pkg/signature"
+)
+
+// VerifySBOMCommand verifies the signatures on the SBOM attached to an image
+// and writes the SBOM out once they are verified.
+// nolint
+type VerifySBOMCommand struct {
+	VerifyCommand
+}
+
+// Exec runs the verification command, writing the verified SBOM to out.
+func (c *VerifySBOMCommand) Exec(ctx context.Context, imageRef string, out io.Writer) error {
+	// always default to sha256 if the algorithm hasn't been explicitly set
+	if c.HashAlgorithm == 0 {
+		c.HashAlgorithm = crypto.SHA256
+	}
+
+	co, closeKey, err := c.checkOpts(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeKey()
+	fulcioVerified := (co.SigVerifier == nil)
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return fmt.Errorf("parsing reference: %w", err)
+	}
+	se, err := ociremote.SignedEntity(ref, co.RegistryClientOpts...)
+	if err != nil {
+		return err
+	}
+	file, err := se.Attachment(ociremote.SBOMTagSuffix)
+	if err != nil {
+		return fmt.Errorf("fetching SBOM of %s: %w", imageRef, err)
+	}
+
+	// Verify the signatures of the SBOM we fetched, by digest, so the
+	// SBOM we write out is the one which was verified.
+	h, err := file.Digest()
+	if err != nil {
+		return err
+	}
+	sbomRef, err := ociremote.SBOMTag(ref, co.RegistryClientOpts...)
+	if err != nil {
+		return err
+	}
+	sbomDigest := sbomRef.Context().Digest(h.String())
+	verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, sbomDigest, co)
+	if err != nil {
+		return fmt.Errorf("verifying SBOM of %s: %w", imageRef, err)
+	}
+	PrintVerificationHeader(sbomDigest.Name(), co, bundleVerified, fulcioVerified)
+	for _, sig := range verified {
+		if cert, err := sig.Cert(); err == nil && cert != nil {
+			fmt.Fprintln(os.Stderr, "Certificate subject: ", sigs.CertSubject(cert))
+			if issuerURL := sigs.CertIssuerExtension(cert); issuerURL != "" {
+				fmt.Fprintln(os.Stderr, "Certificate issuer URL: ", issuerURL)
+			}
+		}
+	}
+
+	mt, err := file.FileMediaType()
+	if err != nil {
+		return err
+	}
+	payload, err := file.Payload()
+	if err != nil {
+		return err
+	}
+	// SBOMs signed with `cosign sign --attachment sbom` were not validated
+	// when they were attached, don't fail on them.
+	if err := sbom.Validate(payload, mt); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "Found SBOM of media type: %s\n", mt)
+	_, err = out.Write(payload)
+	return err
+}
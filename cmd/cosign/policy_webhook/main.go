@@ -67,8 +67,10 @@ var (
 var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
 	// v1alpha1
 	v1alpha1.SchemeGroupVersion.WithKind("ClusterImagePolicy"): &v1alpha1.ClusterImagePolicy{},
+	v1alpha1.SchemeGroupVersion.WithKind("ImagePolicy"):        &v1alpha1.ImagePolicy{},
 	// v1beta1
 	v1beta1.SchemeGroupVersion.WithKind("ClusterImagePolicy"): &v1beta1.ClusterImagePolicy{},
+	v1beta1.SchemeGroupVersion.WithKind("ImagePolicy"):        &v1beta1.ImagePolicy{},
 }
 
 func main() {
@@ -89,6 +91,7 @@ func main() {
 	sharedmain.MainWithContext(ctx, "clusterimagepolicy",
 		certificates.NewController,
 		clusterimagepolicy.NewController,
+		clusterimagepolicy.NewImagePolicyController,
 		NewPolicyValidatingAdmissionController,
 		NewPolicyMutatingAdmissionController,
 		newConversionController,
@@ -142,6 +145,14 @@ func newConversionController(ctx context.Context, cmw configmap.Watcher) *contro
 					v1beta1GroupVersion:  &v1beta1.ClusterImagePolicy{},
 				},
 			},
+			v1beta1.Kind("ImagePolicy"): {
+				DefinitionName: policy.ImagePolicyResource.String(),
+				HubVersion:     v1alpha1GroupVersion,
+				Zygotes: map[string]conversion.ConvertibleObject{
+					v1alpha1GroupVersion: &v1alpha1.ImagePolicy{},
+					v1beta1GroupVersion:  &v1beta1.ImagePolicy{},
+				},
+			},
 		},
 
 		// A function that infuses the context passed to ConvertTo/ConvertFrom/SetDefaults with custom metadata
@@ -19,6 +19,7 @@ import (
 	"context"
 	"flag"
 	"log"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -40,6 +41,7 @@ import (
 
 	"github.com/sigstore/cosign/pkg/apis/config"
 	cwebhook "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
 )
 
 var secretName = flag.String("secret-name", "", "The name of the secret in the webhook's namespace that holds the public key for verification.")
@@ -54,6 +56,17 @@ var secretName = flag.String("secret-name", "", "The name of the secret in the w
 //    https://github.com/sigstore/helm-charts/blob/main/charts/policy-controller/templates/webhook/webhook_validating.yaml
 var webhookName = flag.String("webhook-name", "policy.sigstore.dev", "The name of the validating and mutating webhook configurations as well as the webhook name that is automatically configured, if exists, with different rules and client settings setting how the admission requests to be dispatched to policy-controller.")
 
+// The results of the policies which passed are cached, so that the pods of a
+// workload, which run the same images, are not verified one by one.
+var (
+	policyCacheTTL  = flag.Duration("policy-cache-ttl", time.Minute, "How long the results of the policies which passed are cached for an image digest. Zero disables the cache.")
+	policyCacheSize = flag.Int("policy-cache-size", 1000, "The maximum number of cached results of policies. Zero disables the cache.")
+)
+
+// policyExplain explains the failures of the policies in the details of the
+// denials, at the cost of evaluating the failed rego policies again.
+var policyExplain = flag.String("policy-explain", "", "Explain why the policies fail in the details of the denials, one of notes, fails or full. Empty disables the explanations.")
+
 func main() {
 	opts := webhook.Options{
 		ServiceName: "webhook",
@@ -90,12 +103,20 @@ var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
 }
 
 func NewValidatingAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
-	// Decorate contexts with the current state of the config.
-	store := config.NewStore(logging.FromContext(ctx).Named("config-store"))
+	if *policyExplain != "" {
+		if err := rego.ValidateExplainMode(*policyExplain); err != nil {
+			logging.FromContext(ctx).Fatalf("Invalid --policy-explain: %v", err)
+		}
+	}
+	validator := cwebhook.NewValidator(ctx, *secretName,
+		cwebhook.WithPolicyResultCache(*policyCacheTTL, *policyCacheSize),
+		cwebhook.WithExplain(*policyExplain))
+	// Decorate contexts with the current state of the config, and drop
+	// the cached results of the policies which change.
+	store := config.NewStore(logging.FromContext(ctx).Named("config-store"), validator.PoliciesChanged)
 	store.WatchConfigs(cmw)
-	validator := cwebhook.NewValidator(ctx, *secretName)
 
-	return validation.NewAdmissionController(ctx,
+	impl := validation.NewAdmissionController(ctx,
 		// Name of the resource webhook.
 		*webhookName,
 
@@ -121,12 +142,16 @@ func NewValidatingAdmissionController(ctx context.Context, cmw configmap.Watcher
 		// Extra validating callbacks to be applied to resources.
 		nil,
 	)
+	// The pods/ephemeralcontainers subresource is validated by the other
+	// webhooks of the configuration, and the failures of the policies in
+	// warn or audit mode are returned with the admission response.
+	return cwebhook.WithAdmissionWarnings(cwebhook.WithValidatingSubresourceWebhooks(ctx, impl, *webhookName))
 }
 
 func NewMutatingAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 	validator := cwebhook.NewValidator(ctx, *secretName)
 
-	return defaulting.NewAdmissionController(ctx,
+	impl := defaulting.NewAdmissionController(ctx,
 		// Name of the resource webhook.
 		*webhookName,
 
@@ -148,4 +173,7 @@ func NewMutatingAdmissionController(ctx context.Context, cmw configmap.Watcher)
 		// We pass false because we're using partial schemas.
 		false,
 	)
+	// The images of the pods/ephemeralcontainers subresource are resolved
+	// by the other webhooks of the configuration.
+	return cwebhook.WithMutatingSubresourceWebhooks(ctx, impl, *webhookName)
 }
@@ -23,11 +23,15 @@ import (
 	v1alpha1 "github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
 )
 
-// schema is a tool to dump the schema for Eventing resources.
+// schema is a tool to dump the schema for Eventing resources, and of the
+// attestation predicates.
 func main() {
 	registry.Register(&v1alpha1.ClusterImagePolicy{})
+	registry.Register(&v1alpha1.ImagePolicy{})
 
-	if err := commands.New("github.com/sigstore/cosign").Execute(); err != nil {
+	cmd := commands.New("github.com/sigstore/cosign")
+	addPredicatesCmd(cmd)
+	if err := cmd.Execute(); err != nil {
 		log.Fatal("Error during command execution: ", err)
 	}
 }
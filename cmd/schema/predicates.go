@@ -0,0 +1,157 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/spec"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	slsa "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/spf13/cobra"
+	"knative.dev/hack/schema/schema"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
+)
+
+// predicates are the Go types of the predicates whose JSON schema is checked
+// by `cosign attest`, keyed by their --type.
+var predicates = map[string]interface{}{
+	options.PredicateSLSA:    slsa.ProvenancePredicate{},
+	options.PredicateSLSA1:   attestation.SLSAProvenanceV1Predicate{},
+	options.PredicateLink:    in_toto.Link{},
+	options.PredicateSPDX:    attestation.SPDXDocument{},
+	options.PredicateVuln:    attestation.CosignVulnPredicate{},
+	options.PredicateOpenVEX: attestation.OpenVEXDocument{},
+}
+
+func addPredicatesCmd(root *cobra.Command) {
+	root.AddCommand(&cobra.Command{
+		Use:   "predicates <dir>",
+		Short: "Write the JSON schemas of the attestation predicates to dir.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for name, p := range predicates {
+				t := reflect.TypeOf(p)
+				s := withNullType(predicateSchema(t, schema.GenerateForType(t)))
+				b, err := json.MarshalIndent(s, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(filepath.Join(args[0], name+".json"), append(b, '\n'), 0644); err != nil { //nolint:gosec
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	numberType = reflect.TypeOf(json.Number(""))
+)
+
+// predicateSchema converts the schema generated for t to a JSON schema
+// matching how encoding/json (un)marshals t: fields without omitempty are
+// required, and nil maps, slices and pointers may be null.
+func predicateSchema(t reflect.Type, p schema.JSONSchemaProps) *spec.Schema {
+	s := &spec.Schema{}
+	if !strings.HasPrefix(p.Description, "not found") {
+		s.Description = p.Description
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		s.Nullable = true
+	}
+	switch {
+	case t == timeType:
+		return s.Typed("string", "date-time")
+	case t == numberType:
+		return s.Typed("number", "")
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// encoding/json encodes []byte in base64.
+		s.Nullable = true
+		return s.Typed("string", "byte")
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		// Anything goes.
+		return s
+	case reflect.Map:
+		s.Nullable = true
+	case reflect.Slice:
+		s.Nullable = true
+		if p.Items != nil && p.Items.Schema != nil {
+			s.Items = &spec.SchemaOrArray{Schema: predicateSchema(t.Elem(), *p.Items.Schema)}
+		}
+	case reflect.Struct:
+		s.Properties = map[string]spec.Schema{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := strings.SplitN(f.Tag.Get("json"), ",", 2)
+			if f.Anonymous {
+				embedded := predicateSchema(f.Type, p)
+				for name, fs := range embedded.Properties {
+					s.Properties[name] = fs
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+			name := f.Name
+			if tag[0] != "" {
+				name = tag[0]
+			}
+			s.Properties[name] = *predicateSchema(f.Type, p.Properties[name])
+			if len(tag) < 2 {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+	s.Typed(p.Type, p.Format)
+	if p.Minimum != nil {
+		s.WithMinimum(*p.Minimum, false)
+	}
+	if p.Maximum != nil {
+		s.WithMaximum(*p.Maximum, false)
+	}
+	return s
+}
+
+// withNullType replaces the OpenAPI nullable extension with the null type in
+// s and its subschemas, as the JSON schemas of the predicates are validated
+// with the engine of the jsonschema policies, which only knows the latter.
+func withNullType(s *spec.Schema) *spec.Schema {
+	if s.Nullable {
+		s.Nullable = false
+		if len(s.Type) > 0 {
+			s.AddType("null", "")
+		}
+	}
+	for name, ps := range s.Properties {
+		s.Properties[name] = *withNullType(&ps)
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		withNullType(s.Items.Schema)
+	}
+	return s
+}
@@ -62,6 +62,14 @@ go run $(dirname $0)/../cmd/schema/ dump ClusterImagePolicy \
   | yq eval-all --inplace 'select(fileIndex == 0).spec.versions[0].schema.openAPIV3Schema = select(fileIndex == 1) | select(fileIndex == 0)' \
   $(dirname $0)/../config/300-clusterimagepolicy.yaml -
 
+go run $(dirname $0)/../cmd/schema/ dump ImagePolicy \
+  | yq eval-all --inplace 'select(fileIndex == 0).spec.versions[0].schema.openAPIV3Schema = select(fileIndex == 1) | select(fileIndex == 0)' \
+  $(dirname $0)/../config/300-imagepolicy.yaml -
+
+group "Update predicate schemas"
+
+go run $(dirname $0)/../cmd/schema/ predicates $(dirname $0)/../pkg/cosign/attestation/schemas
+
 group "Update deps post-codegen"
 
 # Make sure our dependencies are up-to-date
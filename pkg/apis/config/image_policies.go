@@ -74,11 +74,19 @@ func parseEntry(entry string, out interface{}) error {
 	return json.Unmarshal(j, &out)
 }
 
+// ImagePolicyKey returns the key of the entry of the ImagePolicy named name in
+// namespace. Underscores are not valid in the names of ClusterImagePolicies,
+// nor in the ones of namespaces, so it collides with no other entry.
+func ImagePolicyKey(namespace, name string) string {
+	return namespace + "_" + name
+}
+
 // GetMatchingPolicies returns all matching Policies and their Authorities that
-// need to be matched for the given Image.
-// Returned map contains the name of the CIP as the key, and a normalized
-// ClusterImagePolicy for it.
-func (p *ImagePolicyConfig) GetMatchingPolicies(image string) (map[string]webhookcip.ClusterImagePolicy, error) {
+// need to be matched for the given Image, in the given namespace. These are
+// the ClusterImagePolicies, along with the ImagePolicies of the namespace.
+// Returned map contains the name of the CIP, or the key of the ImagePolicy,
+// as the key, and a normalized ClusterImagePolicy for it.
+func (p *ImagePolicyConfig) GetMatchingPolicies(image, namespace string) (map[string]webhookcip.ClusterImagePolicy, error) {
 	if p == nil {
 		return nil, errors.New("config is nil")
 	}
@@ -90,6 +98,9 @@ func (p *ImagePolicyConfig) GetMatchingPolicies(image string) (map[string]webhoo
 	// way to go from image to Authorities, but just seeing if this is even
 	// workable so fine for now.
 	for k, v := range p.Policies {
+		if v.Namespace != "" && v.Namespace != namespace {
+			continue
+		}
 		for _, pattern := range v.Images {
 			if pattern.Glob != "" {
 				if matched, err := GlobMatch(pattern.Glob, image); err != nil {
@@ -60,22 +60,40 @@ func TestGetAuthorities(t *testing.T) {
 	if err != nil {
 		t.Error("NewImagePoliciesConfigFromConfigMap(example) =", err)
 	}
-	c, err := defaults.GetMatchingPolicies("rando")
+	c, err := defaults.GetMatchingPolicies("rando", "default")
 	checkGetMatches(t, c, err)
 	matchedPolicy := "cluster-image-policy-0"
 	want := "inlinedata here"
 	if got := getAuthority(t, c, matchedPolicy).Key.Data; got != want {
 		t.Errorf("Did not get what I wanted %q, got %+v", want, got)
 	}
+	if len(c) != 1 {
+		t.Errorf("Wanted 1 match outside of the namespace of the ImagePolicy, got %d", len(c))
+	}
+	// ImagePolicies only match in their namespace, along with the
+	// ClusterImagePolicies.
+	c, err = defaults.GetMatchingPolicies("rando", "team-a")
+	checkGetMatches(t, c, err)
+	if len(c) != 2 {
+		t.Errorf("Wanted 2 matches in the namespace of the ImagePolicy, got %d", len(c))
+	}
+	matchedPolicy = ImagePolicyKey("team-a", "image-policy")
+	want = "team inlinedata here"
+	if got := getAuthority(t, c, matchedPolicy).Key.Data; got != want {
+		t.Errorf("Did not get what I wanted %q, got %+v", want, got)
+	}
+	if got := c[matchedPolicy].Namespace; got != "team-a" {
+		t.Errorf("Did not get what I wanted %q, got %+v", "team-a", got)
+	}
 	// Make sure glob matches 'randomstuff*'
-	c, err = defaults.GetMatchingPolicies("randomstuffhere")
+	c, err = defaults.GetMatchingPolicies("randomstuffhere", "default")
 	checkGetMatches(t, c, err)
 	matchedPolicy = "cluster-image-policy-1"
 	want = "otherinline here"
 	if got := getAuthority(t, c, matchedPolicy).Key.Data; got != want {
 		t.Errorf("Did not get what I wanted %q, got %+v", want, got)
 	}
-	c, err = defaults.GetMatchingPolicies("rando3")
+	c, err = defaults.GetMatchingPolicies("rando3", "default")
 	checkGetMatches(t, c, err)
 	matchedPolicy = "cluster-image-policy-2"
 	want = "cacert chilling here"
@@ -91,7 +109,7 @@ func TestGetAuthorities(t *testing.T) {
 		t.Errorf("Did not get what I wanted %q, got %+v", want, got)
 	}
 	// Make sure regex matches "regexstring*"
-	c, err = defaults.GetMatchingPolicies("regexstringstuff")
+	c, err = defaults.GetMatchingPolicies("regexstringstuff", "default")
 	checkGetMatches(t, c, err)
 	matchedPolicy = "cluster-image-policy-4"
 	want = inlineKeyData
@@ -101,7 +119,7 @@ func TestGetAuthorities(t *testing.T) {
 	checkPublicKey(t, getAuthority(t, c, matchedPolicy).Key.PublicKeys[0])
 
 	// Test multiline yaml cert
-	c, err = defaults.GetMatchingPolicies("inlinecert")
+	c, err = defaults.GetMatchingPolicies("inlinecert", "default")
 	checkGetMatches(t, c, err)
 	matchedPolicy = "cluster-image-policy-3"
 	want = inlineKeyData
@@ -111,7 +129,7 @@ func TestGetAuthorities(t *testing.T) {
 	checkPublicKey(t, getAuthority(t, c, matchedPolicy).Key.PublicKeys[0])
 
 	// Test multiline cert but json encoded
-	c, err = defaults.GetMatchingPolicies("ghcr.io/example/foo")
+	c, err = defaults.GetMatchingPolicies("ghcr.io/example/foo", "default")
 	checkGetMatches(t, c, err)
 	matchedPolicy = "cluster-image-policy-json"
 	want = inlineKeyData
@@ -121,7 +139,7 @@ func TestGetAuthorities(t *testing.T) {
 	checkPublicKey(t, getAuthority(t, c, matchedPolicy).Key.PublicKeys[0])
 
 	// Test multiple matches
-	c, err = defaults.GetMatchingPolicies("regexstringtoo")
+	c, err = defaults.GetMatchingPolicies("regexstringtoo", "default")
 	checkGetMatches(t, c, err)
 	if len(c) != 2 {
 		t.Errorf("Wanted two matches, got %d", len(c))
@@ -140,7 +158,7 @@ func TestGetAuthorities(t *testing.T) {
 	}
 
 	// Test attestations + top level policy
-	c, err = defaults.GetMatchingPolicies("withattestations")
+	c, err = defaults.GetMatchingPolicies("withattestations", "default")
 	checkGetMatches(t, c, err)
 	if len(c) != 1 {
 		t.Errorf("Wanted 1 match, got %d", len(c))
@@ -170,7 +188,7 @@ func TestGetAuthorities(t *testing.T) {
 
 	// Test source oci
 	matchedPolicy = "cluster-image-policy-source-oci"
-	c, err = defaults.GetMatchingPolicies("sourceocionly")
+	c, err = defaults.GetMatchingPolicies("sourceocionly", "default")
 	checkGetMatches(t, c, err)
 	if len(c) != 1 {
 		t.Errorf("Wanted 1 match, got %d", len(c))
@@ -184,7 +202,7 @@ func TestGetAuthorities(t *testing.T) {
 
 	// Test source signaturePullSecrets
 	matchedPolicy = "cluster-image-policy-source-oci-signature-pull-secrets"
-	c, err = defaults.GetMatchingPolicies("sourceocisignaturepullsecrets")
+	c, err = defaults.GetMatchingPolicies("sourceocisignaturepullsecrets", "default")
 	checkGetMatches(t, c, err)
 	if len(c) != 1 {
 		t.Errorf("Wanted 1 match, got %d", len(c))
@@ -27,4 +27,10 @@ var (
 		Group:    GroupName,
 		Resource: "clusterimagepolicies",
 	}
+
+	// ImagePolicyResource represents an ImagePolicy
+	ImagePolicyResource = schema.GroupResource{
+		Group:    GroupName,
+		Resource: "imagepolicies",
+	}
 )
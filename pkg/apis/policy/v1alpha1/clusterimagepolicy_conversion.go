@@ -59,6 +59,7 @@ func (spec *ClusterImagePolicySpec) ConvertTo(ctx context.Context, sink *v1beta1
 		}
 		sink.Authorities = append(sink.Authorities, v1beta1Authority)
 	}
+	sink.Mode = spec.Mode
 	return nil
 }
 
@@ -79,10 +80,20 @@ func (authority *Authority) ConvertTo(ctx context.Context, sink *v1beta1.Authori
 		v1beta1Att := v1beta1.Attestation{}
 		v1beta1Att.Name = att.Name
 		v1beta1Att.PredicateType = att.PredicateType
+		v1beta1Att.MaxAge = att.MaxAge.DeepCopy()
 		if att.Policy != nil {
 			v1beta1Att.Policy = &v1beta1.Policy{
-				Type: att.Policy.Type,
-				Data: att.Policy.Data,
+				Type:           att.Policy.Type,
+				Data:           att.Policy.Data,
+				Query:          att.Policy.Query,
+				IncludeContext: att.Policy.IncludeContext,
+				OCI:            att.Policy.OCI,
+			}
+			if att.Policy.Authority != nil {
+				v1beta1Att.Policy.Authority = &v1beta1.Authority{}
+				if err := att.Policy.Authority.ConvertTo(ctx, v1beta1Att.Policy.Authority); err != nil {
+					return err
+				}
 			}
 			v1beta1Att.Policy.URL = att.Policy.URL.DeepCopy()
 			if att.Policy.ConfigMapRef != nil {
@@ -131,6 +142,7 @@ func (spec *ClusterImagePolicySpec) ConvertFrom(ctx context.Context, source *v1b
 		}
 		spec.Authorities = append(spec.Authorities, authority)
 	}
+	spec.Mode = source.Mode
 	return nil
 }
 
@@ -151,10 +163,20 @@ func (authority *Authority) ConvertFrom(ctx context.Context, source *v1beta1.Aut
 		attestation := Attestation{}
 		attestation.Name = att.Name
 		attestation.PredicateType = att.PredicateType
+		attestation.MaxAge = att.MaxAge.DeepCopy()
 		if att.Policy != nil {
 			attestation.Policy = &Policy{
-				Type: att.Policy.Type,
-				Data: att.Policy.Data,
+				Type:           att.Policy.Type,
+				Data:           att.Policy.Data,
+				Query:          att.Policy.Query,
+				IncludeContext: att.Policy.IncludeContext,
+				OCI:            att.Policy.OCI,
+			}
+			if att.Policy.Authority != nil {
+				attestation.Policy.Authority = &Authority{}
+				if err := attestation.Policy.Authority.ConvertFrom(ctx, att.Policy.Authority); err != nil {
+					return err
+				}
 			}
 			attestation.Policy.URL = att.Policy.URL.DeepCopy()
 			if att.Policy.ConfigMapRef != nil {
@@ -17,10 +17,12 @@ package v1alpha1
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 
 	"github.com/sigstore/cosign/pkg/apis/policy/v1beta1"
 )
@@ -47,6 +49,20 @@ func TestConversionRoundTripV1alpha1(t *testing.T) {
 				},
 			},
 		},
+	}, {name: "warn mode",
+		in: &ClusterImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-cip",
+			},
+			Spec: ClusterImagePolicySpec{
+				Images: []ImagePattern{{Glob: "*"}},
+				Authorities: []Authority{
+					{Key: &KeyRef{
+						SecretRef: &v1.SecretReference{Name: "mysecret"}}},
+				},
+				Mode: "warn",
+			},
+		},
 	}, {name: "source and attestations",
 		in: &ClusterImagePolicy{
 			ObjectMeta: metav1.ObjectMeta{
@@ -63,6 +79,7 @@ func TestConversionRoundTripV1alpha1(t *testing.T) {
 					{Attestations: []Attestation{{
 						Name:          "attestation-0",
 						PredicateType: "vuln",
+						MaxAge:        &metav1.Duration{Duration: 24 * time.Hour},
 						Policy: &Policy{
 							Type: "cue",
 							Data: "cue language goes here",
@@ -71,6 +88,31 @@ func TestConversionRoundTripV1alpha1(t *testing.T) {
 				},
 			},
 		},
+	}, {name: "attestation with an OCI policy",
+		in: &ClusterImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-cip",
+			},
+			Spec: ClusterImagePolicySpec{
+				Images: []ImagePattern{{Glob: "*"}},
+				Authorities: []Authority{
+					{Attestations: []Attestation{{
+						Name:          "attestation-0",
+						PredicateType: "vuln",
+						Policy: &Policy{
+							Type: "rego",
+							OCI:  "registry.example.com/policies/vuln@sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+							Authority: &Authority{
+								Keyless: &KeylessRef{
+									Identities: []Identity{{Subject: "security@example.com", Issuer: "https://accounts.google.com"}},
+								},
+								CTLog: &TLog{URL: apis.HTTPS("rekor.example.com")},
+							},
+						},
+					}}},
+				},
+			},
+		},
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -104,6 +146,16 @@ func TestConversionRoundTripV1beta1(t *testing.T) {
 				Images: []v1beta1.ImagePattern{{Glob: "*"}},
 			},
 		},
+	}, {name: "audit mode",
+		in: &v1beta1.ClusterImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-cip",
+			},
+			Spec: v1beta1.ClusterImagePolicySpec{
+				Images: []v1beta1.ImagePattern{{Glob: "*"}},
+				Mode:   "audit",
+			},
+		},
 	}, {name: "another",
 		in: &v1beta1.ClusterImagePolicy{
 			ObjectMeta: metav1.ObjectMeta{
@@ -59,6 +59,11 @@ type ClusterImagePolicySpec struct {
 	// not even get evaluated, as the Policy is considered failed.
 	// +optional
 	Policy *Policy `json:"policy,omitempty"`
+	// Mode controls what happens to images failing this policy: "enforce"
+	// (the default) denies them, "warn" admits them with an admission
+	// warning and "audit" admits them and only logs the failure.
+	// +optional
+	Mode string `json:"mode,omitempty"`
 }
 
 // ImagePattern defines a pattern and its associated authorties
@@ -151,20 +156,46 @@ type Attestation struct {
 	PredicateType string `json:"predicateType"`
 	// +optional
 	Policy *Policy `json:"policy,omitempty"`
+	// MaxAge rejects the attestations produced longer ago, according to
+	// their transparency log entry or the timestamp of their predicate,
+	// such as the end of a vulnerability scan.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
 }
 
 // Policy specifies a policy to use for Attestation validation.
 // Exactly one of Data, URL, or ConfigMapReference must be specified.
 type Policy struct {
-	// Which kind of policy this is, currently only rego or cue are supported.
-	// Furthermore, only cue is tested :)
+	// Which kind of policy this is, currently only rego, cue, jsonschema or cel are supported.
 	Type string `json:"type"`
 	// +optional
 	Data string `json:"data,omitempty"`
+	// Query is the Rego query deciding whether the policy is satisfied,
+	// data.sigstore.isCompliant by default. The messages of the deny rule in
+	// the package of the query, if any, are reported when it is not.
+	// Only valid for rego policies.
+	// +optional
+	Query string `json:"query,omitempty"`
+	// IncludeContext adds the admission context, such as the namespace, the
+	// workload and the signer of the attestation, to the input of the policy
+	// under its context field. Off by default, so that the input of closed
+	// definitions doesn't change.
+	// +optional
+	IncludeContext bool `json:"includeContext,omitempty"`
 	// +optional
 	URL *apis.URL `json:"url,omitempty"`
 	// +optional
 	ConfigMapRef *ConfigMapReference `json:"configMapRef,omitempty"`
+	// OCI is the reference of a policy pushed by `cosign policy push`, which
+	// is fetched and inlined once its signature is verified with Authority.
+	// Tags are resolved when the ClusterImagePolicy is reconciled, pin the
+	// policy with a digest to keep it from changing afterwards.
+	// +optional
+	OCI string `json:"oci,omitempty"`
+	// Authority verifies the signature of the OCI policy, with its key or
+	// keyless identities and ctlog. Required along with OCI.
+	// +optional
+	Authority *Authority `json:"authority,omitempty"`
 }
 
 // ConfigMapReference is cut&paste from SecretReference, but for the life of me
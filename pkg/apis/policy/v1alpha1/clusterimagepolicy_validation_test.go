@@ -18,9 +18,11 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
 
@@ -561,6 +563,23 @@ func TestAttestationsValidation(t *testing.T) {
 	}{{
 		name:        "vuln",
 		attestation: Attestation{Name: "first", PredicateType: "vuln"},
+	}, {
+		name:        "slsaprovenance1",
+		attestation: Attestation{Name: "first", PredicateType: "slsaprovenance1"},
+	}, {
+		name:        "openvex",
+		attestation: Attestation{Name: "first", PredicateType: "openvex"},
+	}, {
+		name:        "cyclonedx",
+		attestation: Attestation{Name: "first", PredicateType: "cyclonedx"},
+	}, {
+		name:        "max age",
+		attestation: Attestation{Name: "first", PredicateType: "vuln", MaxAge: &metav1.Duration{Duration: 24 * time.Hour}},
+	}, {
+		name:        "negative max age",
+		attestation: Attestation{Name: "first", PredicateType: "vuln", MaxAge: &metav1.Duration{Duration: -time.Hour}},
+		expectErr:   true,
+		errorString: "invalid value: -1h0m0s: maxAge\nmust be positive",
 	}, {
 		name:        "missing name",
 		attestation: Attestation{PredicateType: "vuln"},
@@ -585,7 +604,7 @@ func TestAttestationsValidation(t *testing.T) {
 			},
 		},
 		expectErr:   true,
-		errorString: "invalid value: not-cue: policy.type\nonly cue is supported at the moment",
+		errorString: "invalid value: not-cue: policy.type\nonly cue, rego, jsonschema and cel are supported at the moment",
 	}, {
 		name: "custom with missing policy data",
 		attestation: Attestation{Name: "second", PredicateType: "custom",
@@ -594,7 +613,7 @@ func TestAttestationsValidation(t *testing.T) {
 			},
 		},
 		expectErr:   true,
-		errorString: "missing field(s): policy.data",
+		errorString: "expected exactly one, got neither: policy.data, policy.oci",
 	}, {
 		name: "custom with policy",
 		attestation: Attestation{Name: "second", PredicateType: "custom",
@@ -603,6 +622,151 @@ func TestAttestationsValidation(t *testing.T) {
 				Data: `predicateType: "cosign.sigstore.dev/attestation/vuln/v1"`,
 			},
 		},
+	}, {
+		name: "custom with rego policy and query",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type:  "rego",
+				Data:  "package vuln\nallow = true",
+				Query: "data.vuln.allow",
+			},
+		},
+	}, {
+		name: "custom with query on cue policy",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type:  "cue",
+				Data:  `predicateType: "cosign.sigstore.dev/attestation/vuln/v1"`,
+				Query: "data.vuln.allow",
+			},
+		},
+		expectErr:   true,
+		errorString: "must not set the field(s): policy.query",
+	}, {
+		name: "custom with invalid cue policy",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "cue",
+				Data: `predicateType: "cosign.sigstore.dev/attestation/vuln/v1`,
+			},
+		},
+		expectErr:   true,
+		errorString: "invalid cue policy: policy.data\nstring literal not terminated",
+	}, {
+		name: "custom with rego policy and invalid query",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type:  "rego",
+				Data:  "package vuln\nallow = true",
+				Query: "data.vuln.",
+			},
+		},
+		expectErr:   true,
+		errorString: "invalid rego policy: policy.data\n1 error occurred: 1:10: rego_parse_error: unexpected eof token: expected ident\n\tdata.vuln.\n\t         ^",
+	}, {
+		name: "custom with jsonschema policy",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "jsonschema",
+				Data: `{"properties": {"predicateType": {"const": "cosign.sigstore.dev/attestation/vuln/v1"}}}`,
+			},
+		},
+	}, {
+		name: "custom with invalid jsonschema policy",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "jsonschema",
+				Data: `{"properties": {"predicateType": {"const": "cosign.sigstore.dev/attestation/vuln/v1"}}`,
+			},
+		},
+		expectErr:   true,
+		errorString: "invalid jsonschema policy: policy.data\nunexpected EOF",
+	}, {
+		name: "custom with cel policy",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "cel",
+				Data: `input.predicateType == "cosign.sigstore.dev/attestation/vuln/v1"`,
+			},
+		},
+	}, {
+		name: "custom with cel policy not returning a bool",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "cel",
+				Data: `input.predicateType + "/v1"`,
+			},
+		},
+		expectErr:   true,
+		errorString: "invalid cel policy: policy.data\nexpression must evaluate to a bool, not string",
+	}, {
+		name: "custom with OCI policy",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "rego",
+				OCI:  "registry.example.com/policies/vuln@sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+				Authority: &Authority{
+					Keyless: &KeylessRef{
+						Identities: []Identity{{Subject: "security@example.com"}},
+					},
+				},
+			},
+		},
+	}, {
+		name: "custom with OCI policy and data",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "cue",
+				Data: `predicateType: "cosign.sigstore.dev/attestation/vuln/v1"`,
+				OCI:  "registry.example.com/policies/vuln:latest",
+				Authority: &Authority{
+					Key: &KeyRef{KMS: "gcpkms://projects/example/locations/global/keyRings/policies/cryptoKeys/security"},
+				},
+			},
+		},
+		expectErr:   true,
+		errorString: "expected exactly one, got both: policy.data, policy.oci",
+	}, {
+		name: "custom with invalid OCI policy reference without authority",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "cue",
+				OCI:  "registry.example.com/policies/Vuln",
+			},
+		},
+		expectErr:   true,
+		errorString: "invalid value: registry.example.com/policies/Vuln: policy.oci\ncould not parse reference: registry.example.com/policies/Vuln\nmissing field(s): policy.authority",
+	}, {
+		name: "custom with authority of OCI policy verifying attestations",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "cue",
+				OCI:  "registry.example.com/policies/vuln:latest",
+				Authority: &Authority{
+					Keyless: &KeylessRef{
+						Identities: []Identity{{Subject: "security@example.com"}},
+					},
+					Attestations: []Attestation{{Name: "first", PredicateType: "vuln"}},
+				},
+			},
+		},
+		expectErr:   true,
+		errorString: "must not set the field(s): policy.authority.attestations",
+	}, {
+		name: "custom with authority without OCI policy",
+		attestation: Attestation{Name: "second", PredicateType: "custom",
+			Policy: &Policy{
+				Type: "cue",
+				Data: `predicateType: "cosign.sigstore.dev/attestation/vuln/v1"`,
+				Authority: &Authority{
+					Keyless: &KeylessRef{
+						Identities: []Identity{{Subject: "security@example.com"}},
+					},
+				},
+			},
+		},
+		expectErr:   true,
+		errorString: "must not set the field(s): policy.authority",
 	},
 	}
 
@@ -824,3 +988,62 @@ func TestAWSKMSValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestModeValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		expectErr   bool
+		errorString string
+		mode        string
+	}{
+		{
+			name: "Should pass when mode is unset",
+		},
+		{
+			name: "Should pass when mode is enforce",
+			mode: "enforce",
+		},
+		{
+			name: "Should pass when mode is warn",
+			mode: "warn",
+		},
+		{
+			name: "Should pass when mode is audit",
+			mode: "audit",
+		},
+		{
+			name:        "Should fail when mode is unknown",
+			expectErr:   true,
+			errorString: "invalid value: dryrun: spec.mode\nonly enforce, warn and audit are supported",
+			mode:        "dryrun",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := ClusterImagePolicy{
+				Spec: ClusterImagePolicySpec{
+					Images: []ImagePattern{
+						{
+							Glob: "globbityglob",
+						},
+					},
+					Authorities: []Authority{
+						{
+							Keyless: &KeylessRef{
+								Identities: []Identity{{Issuer: "some issuer"}},
+							},
+						},
+					},
+					Mode: test.mode,
+				},
+			}
+			err := policy.Validate(context.TODO())
+			if test.expectErr {
+				require.NotNil(t, err)
+				require.EqualError(t, err, test.errorString)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
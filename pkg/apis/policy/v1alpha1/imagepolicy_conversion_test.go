@@ -0,0 +1,57 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sigstore/cosign/pkg/apis/policy/v1beta1"
+)
+
+// Test v1alpha1 -> v1beta1 -> v1alpha1
+func TestImagePolicyConversionRoundTrip(t *testing.T) {
+	in := &ImagePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-a",
+			Name:      "test-ip",
+		},
+		Spec: ClusterImagePolicySpec{
+			Images: []ImagePattern{{Glob: "ghcr.io/team-a/*"}},
+			Authorities: []Authority{
+				{Key: &KeyRef{
+					SecretRef: &v1.SecretReference{Name: "mysecret"}}},
+			},
+			Mode: "warn",
+		},
+	}
+
+	ver := &v1beta1.ImagePolicy{}
+	if err := in.ConvertTo(context.Background(), ver); err != nil {
+		t.Error("ConvertTo() =", err)
+	}
+	got := &ImagePolicy{}
+	if err := got.ConvertFrom(context.Background(), ver); err != nil {
+		t.Error("ConvertFrom() =", err)
+	}
+
+	if diff := cmp.Diff(in, got); diff != "" {
+		t.Error("roundtrip (-want, +got) =", diff)
+	}
+}
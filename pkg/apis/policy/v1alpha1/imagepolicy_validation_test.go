@@ -0,0 +1,87 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImagePolicyValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		expectErr   bool
+		errorString string
+		policy      ImagePolicy
+	}{
+		{
+			name: "Should pass when the spec is valid",
+			policy: ImagePolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "image-policy"},
+				Spec: ClusterImagePolicySpec{
+					Images: []ImagePattern{{Glob: "ghcr.io/team-a/*"}},
+					Authorities: []Authority{{
+						Keyless: &KeylessRef{
+							Identities: []Identity{{Issuer: "some issuer"}},
+						},
+					}},
+				},
+			},
+		},
+		{
+			name:        "Should fail when the spec is invalid, like the one of a ClusterImagePolicy",
+			expectErr:   true,
+			errorString: "invalid value: dryrun: spec.mode\nonly enforce, warn and audit are supported\nmissing field(s): spec.authorities",
+			policy: ImagePolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "image-policy"},
+				Spec: ClusterImagePolicySpec{
+					Images: []ImagePattern{{Glob: "ghcr.io/team-a/*"}},
+					Mode:   "dryrun",
+				},
+			},
+		},
+		{
+			name:        "Should fail when the mode is not enforce",
+			expectErr:   true,
+			errorString: "invalid value: warn: spec.mode\nonly enforce is supported by ImagePolicy",
+			policy: ImagePolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "image-policy"},
+				Spec: ClusterImagePolicySpec{
+					Images: []ImagePattern{{Glob: "ghcr.io/team-a/*"}},
+					Authorities: []Authority{{
+						Keyless: &KeylessRef{
+							Identities: []Identity{{Issuer: "some issuer"}},
+						},
+					}},
+					Mode: "warn",
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.Validate(context.TODO())
+			if test.expectErr {
+				require.NotNil(t, err)
+				require.EqualError(t, err, test.errorString)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
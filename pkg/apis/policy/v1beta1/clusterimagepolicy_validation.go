@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/sigstore/cosign/pkg/apis/utils"
 	"knative.dev/pkg/apis"
 )
@@ -48,10 +49,25 @@ func (spec *ClusterImagePolicySpec) Validate(ctx context.Context) (errors *apis.
 		errors = errors.Also(authority.Validate(ctx).ViaFieldIndex("authorities", i))
 	}
 	errors = errors.Also(spec.Policy.Validate(ctx))
+	errors = errors.Also(validateMode(spec.Mode).ViaField("mode"))
 
 	return
 }
 
+// validateMode checks that mode, when set, is one of the supported
+// ClusterImagePolicy modes.
+func validateMode(mode string) *apis.FieldError {
+	if mode == "" {
+		return nil
+	}
+	for _, m := range utils.PolicyModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return apis.ErrInvalidValue(mode, apis.CurrentField, "only enforce, warn and audit are supported")
+}
+
 func (image *ImagePattern) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
 	if image.Glob == "" {
@@ -165,7 +181,7 @@ func (a *Attestation) Validate(ctx context.Context) *apis.FieldError {
 	}
 	if a.PredicateType == "" {
 		errs = errs.Also(apis.ErrMissingField("predicateType"))
-	} else if a.PredicateType != "custom" && a.PredicateType != "slsaprovenance" && a.PredicateType != "spdx" && a.PredicateType != "link" && a.PredicateType != "vuln" {
+	} else if a.PredicateType != "custom" && a.PredicateType != "slsaprovenance" && a.PredicateType != "slsaprovenance1" && a.PredicateType != "spdx" && a.PredicateType != "link" && a.PredicateType != "vuln" && a.PredicateType != "openvex" && a.PredicateType != "cyclonedx" {
 		// TODO(vaikas): The above should be using something like:
 		// if _, ok := options.PredicateTypeMap[a.PrecicateType]; !ok {
 		// But it causes an import loop. That refactor can be part of
@@ -173,6 +189,9 @@ func (a *Attestation) Validate(ctx context.Context) *apis.FieldError {
 		errs = errs.Also(apis.ErrInvalidValue(a.PredicateType, "predicateType", "unsupported precicate type"))
 	}
 	errs = errs.Also(a.Policy.Validate(ctx).ViaField("policy"))
+	if a.MaxAge != nil && a.MaxAge.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(a.MaxAge.Duration.String(), "maxAge", "must be positive"))
+	}
 	return errs
 }
 
@@ -181,13 +200,53 @@ func (p *Policy) Validate(ctx context.Context) *apis.FieldError {
 		return nil
 	}
 	var errs *apis.FieldError
-	if p.Type != "cue" {
-		errs = errs.Also(apis.ErrInvalidValue(p.Type, "type", "only cue is supported at the moment"))
+	validType := false
+	for _, t := range utils.PolicyTypes {
+		if p.Type == t {
+			validType = true
+		}
+	}
+	if !validType {
+		errs = errs.Also(apis.ErrInvalidValue(p.Type, "type", "only cue, rego, jsonschema and cel are supported at the moment"))
 	}
-	if p.Data == "" {
-		errs = errs.Also(apis.ErrMissingField("data"))
+	switch {
+	case p.Data == "" && p.OCI == "":
+		errs = errs.Also(apis.ErrMissingOneOf("data", "oci"))
+	case p.Data != "" && p.OCI != "":
+		errs = errs.Also(apis.ErrMultipleOneOf("data", "oci"))
+	}
+	if p.OCI != "" {
+		if _, err := name.ParseReference(p.OCI); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(p.OCI, "oci", err.Error()))
+		}
+		if p.Authority == nil {
+			errs = errs.Also(apis.ErrMissingField("authority"))
+		}
+	} else if p.Authority != nil {
+		errs = errs.Also(apis.ErrDisallowedFields("authority"))
+	}
+	if p.Authority != nil {
+		errs = errs.Also(p.Authority.Validate(ctx).ViaField("authority"))
+		// The signature of the policy is all the authority verifies.
+		if len(p.Authority.Sources) > 0 {
+			errs = errs.Also(apis.ErrDisallowedFields("authority.source"))
+		}
+		if len(p.Authority.Attestations) > 0 {
+			errs = errs.Also(apis.ErrDisallowedFields("authority.attestations"))
+		}
+	}
+	if p.Query != "" && p.Type != "rego" {
+		errs = errs.Also(apis.ErrDisallowedFields("query"))
+	}
+	if validType && p.Data != "" {
+		if err := utils.ValidatePolicy(p.Type, p.Data, p.Query); err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("invalid %s policy", p.Type),
+				Paths:   []string{"data"},
+				Details: err.Error(),
+			})
+		}
 	}
-	// TODO(vaikas): How to validate the cue / rego bytes here (data).
 	return errs
 }
 
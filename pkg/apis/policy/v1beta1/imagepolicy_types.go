@@ -0,0 +1,63 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+)
+
+// ImagePolicy is the namespaced counterpart of ClusterImagePolicy. It defines
+// the images that go through verification and the authorities used for
+// verification, but only for the pods of its namespace, so that it can be
+// managed without cluster wide permissions.
+//
+// +genclient
+// +genclient:noStatus
+// +genreconciler:krshapedlogic=false
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ImagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the desired state of the ImagePolicy (from the client). It
+	// is the same as the one of ClusterImagePolicy, except that the secrets
+	// it references are read from the namespace of the ImagePolicy.
+	Spec ClusterImagePolicySpec `json:"spec"`
+}
+
+var (
+	_ apis.Validatable   = (*ImagePolicy)(nil)
+	_ apis.Defaultable   = (*ImagePolicy)(nil)
+	_ kmeta.OwnerRefable = (*ImagePolicy)(nil)
+)
+
+// GetGroupVersionKind implements kmeta.OwnerRefable
+func (p *ImagePolicy) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("ImagePolicy")
+}
+
+// ImagePolicyList is a list of ImagePolicy resources
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ImagePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ImagePolicy `json:"items"`
+}
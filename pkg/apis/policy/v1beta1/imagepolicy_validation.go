@@ -0,0 +1,34 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"context"
+
+	"github.com/sigstore/cosign/pkg/apis/utils"
+	"knative.dev/pkg/apis"
+)
+
+// Validate implements apis.Validatable
+func (p *ImagePolicy) Validate(ctx context.Context) *apis.FieldError {
+	errs := p.Spec.Validate(ctx)
+	// ImagePolicies only add requirements to the ones of the
+	// ClusterImagePolicies, so their failures are always enforced.
+	switch p.Spec.Mode {
+	case utils.ModeWarn, utils.ModeAudit:
+		errs = errs.Also(apis.ErrInvalidValue(p.Spec.Mode, "mode", "only enforce is supported by ImagePolicy"))
+	}
+	return errs.ViaField("spec")
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package utils
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue/cuecontext"
+
+	"github.com/sigstore/cosign/pkg/cosign/cel"
+	"github.com/sigstore/cosign/pkg/cosign/jsonschema"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
+)
+
+// PolicyTypes lists the languages policies can be written in.
+var PolicyTypes = []string{"cue", "rego", "jsonschema", "cel"}
+
+// Modes a ClusterImagePolicy can be applied in. Images failing an enforced
+// policy are denied, a warn policy admits them with an admission warning and
+// an audit policy only logs and records the failure.
+const (
+	ModeEnforce = "enforce"
+	ModeWarn    = "warn"
+	ModeAudit   = "audit"
+)
+
+// PolicyModes lists the modes a ClusterImagePolicy can be applied in.
+var PolicyModes = []string{ModeEnforce, ModeWarn, ModeAudit}
+
+// ValidatePolicy compiles the policy in the language of policyType, along with
+// its rego query if any, and returns the errors preventing its evaluation.
+func ValidatePolicy(policyType, policy, query string) error {
+	switch policyType {
+	case "cue":
+		return cuecontext.New().CompileString(policy).Err()
+	case "rego":
+		return rego.CompileModule(policy, query)
+	case "jsonschema":
+		_, err := jsonschema.Compile(policy)
+		return err
+	case "cel":
+		_, err := cel.Compile(policy)
+		return err
+	default:
+		return fmt.Errorf("unsupported policy type %q", policyType)
+	}
+}
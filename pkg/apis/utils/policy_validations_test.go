@@ -0,0 +1,84 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policyType string
+		policy     string
+		query      string
+		wantErr    string
+	}{{
+		name:       "cue",
+		policyType: "cue",
+		policy:     `predicateType: "cosign.sigstore.dev/attestation/vuln/v1"`,
+	}, {
+		name:       "invalid cue",
+		policyType: "cue",
+		policy:     `predicateType: "cosign.sigstore.dev/attestation/vuln/v1`,
+		wantErr:    "string literal not terminated",
+	}, {
+		name:       "rego with query",
+		policyType: "rego",
+		policy:     "package vuln\nallow = true",
+		query:      "data.vuln.allow",
+	}, {
+		name:       "invalid rego",
+		policyType: "rego",
+		policy:     "package vuln\nallow {",
+		wantErr:    "rego_parse_error",
+	}, {
+		name:       "jsonschema",
+		policyType: "jsonschema",
+		policy:     `{"required": ["predicate"]}`,
+	}, {
+		name:       "invalid jsonschema",
+		policyType: "jsonschema",
+		policy:     `{"required": "predicate"}`,
+		wantErr:    "required must be of an array",
+	}, {
+		name:       "cel",
+		policyType: "cel",
+		policy:     `input.predicateType == "cosign.sigstore.dev/attestation/vuln/v1"`,
+	}, {
+		name:       "invalid cel",
+		policyType: "cel",
+		policy:     `input.predicateType = "cosign.sigstore.dev/attestation/vuln/v1"`,
+		wantErr:    "Syntax error",
+	}, {
+		name:       "unsupported type",
+		policyType: "opa",
+		wantErr:    `unsupported policy type "opa"`,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePolicy(tc.policyType, tc.policy, tc.query)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidatePolicy() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("ValidatePolicy() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -30,6 +30,10 @@ func (c *FakePolicyV1alpha1) ClusterImagePolicies() v1alpha1.ClusterImagePolicyI
 	return &FakeClusterImagePolicies{c}
 }
 
+func (c *FakePolicyV1alpha1) ImagePolicies(namespace string) v1alpha1.ImagePolicyInterface {
+	return &FakeImagePolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakePolicyV1alpha1) RESTClient() rest.Interface {
@@ -27,6 +27,7 @@ import (
 type PolicyV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ClusterImagePoliciesGetter
+	ImagePoliciesGetter
 }
 
 // PolicyV1alpha1Client is used to interact with features provided by the policy.sigstore.dev group.
@@ -38,6 +39,10 @@ func (c *PolicyV1alpha1Client) ClusterImagePolicies() ClusterImagePolicyInterfac
 	return newClusterImagePolicies(c)
 }
 
+func (c *PolicyV1alpha1Client) ImagePolicies(namespace string) ImagePolicyInterface {
+	return newImagePolicies(c, namespace)
+}
+
 // NewForConfig creates a new PolicyV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
@@ -30,6 +30,10 @@ func (c *FakePolicyV1beta1) ClusterImagePolicies() v1beta1.ClusterImagePolicyInt
 	return &FakeClusterImagePolicies{c}
 }
 
+func (c *FakePolicyV1beta1) ImagePolicies(namespace string) v1beta1.ImagePolicyInterface {
+	return &FakeImagePolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakePolicyV1beta1) RESTClient() rest.Interface {
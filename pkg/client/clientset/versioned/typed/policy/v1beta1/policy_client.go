@@ -27,6 +27,7 @@ import (
 type PolicyV1beta1Interface interface {
 	RESTClient() rest.Interface
 	ClusterImagePoliciesGetter
+	ImagePoliciesGetter
 }
 
 // PolicyV1beta1Client is used to interact with features provided by the policy.sigstore.dev group.
@@ -38,6 +39,10 @@ func (c *PolicyV1beta1Client) ClusterImagePolicies() ClusterImagePolicyInterface
 	return newClusterImagePolicies(c)
 }
 
+func (c *PolicyV1beta1Client) ImagePolicies(namespace string) ImagePolicyInterface {
+	return newImagePolicies(c, namespace)
+}
+
 // NewForConfig creates a new PolicyV1beta1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
@@ -238,6 +238,137 @@ func (w *wrapPolicyV1alpha1ClusterImagePolicyImpl) Watch(ctx context.Context, op
 	return nil, errors.New("NYI: Watch")
 }
 
+func (w *wrapPolicyV1alpha1) ImagePolicies(namespace string) typedpolicyv1alpha1.ImagePolicyInterface {
+	return &wrapPolicyV1alpha1ImagePolicyImpl{
+		dyn: w.dyn.Resource(schema.GroupVersionResource{
+			Group:    "policy.sigstore.dev",
+			Version:  "v1alpha1",
+			Resource: "imagepolicies",
+		}),
+
+		namespace: namespace,
+	}
+}
+
+type wrapPolicyV1alpha1ImagePolicyImpl struct {
+	dyn dynamic.NamespaceableResourceInterface
+
+	namespace string
+}
+
+var _ typedpolicyv1alpha1.ImagePolicyInterface = (*wrapPolicyV1alpha1ImagePolicyImpl)(nil)
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) Create(ctx context.Context, in *v1alpha1.ImagePolicy, opts v1.CreateOptions) (*v1alpha1.ImagePolicy, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "policy.sigstore.dev",
+		Version: "v1alpha1",
+		Kind:    "ImagePolicy",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Namespace(w.namespace).Create(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return w.dyn.Namespace(w.namespace).Delete(ctx, name, opts)
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	return w.dyn.Namespace(w.namespace).DeleteCollection(ctx, opts, listOpts)
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ImagePolicy, error) {
+	uo, err := w.dyn.Namespace(w.namespace).Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.ImagePolicyList, error) {
+	uo, err := w.dyn.Namespace(w.namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.ImagePolicyList{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ImagePolicy, err error) {
+	uo, err := w.dyn.Namespace(w.namespace).Patch(ctx, name, pt, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) Update(ctx context.Context, in *v1alpha1.ImagePolicy, opts v1.UpdateOptions) (*v1alpha1.ImagePolicy, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "policy.sigstore.dev",
+		Version: "v1alpha1",
+		Kind:    "ImagePolicy",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Namespace(w.namespace).Update(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) UpdateStatus(ctx context.Context, in *v1alpha1.ImagePolicy, opts v1.UpdateOptions) (*v1alpha1.ImagePolicy, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "policy.sigstore.dev",
+		Version: "v1alpha1",
+		Kind:    "ImagePolicy",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Namespace(w.namespace).UpdateStatus(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1alpha1ImagePolicyImpl) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return nil, errors.New("NYI: Watch")
+}
+
 // PolicyV1beta1 retrieves the PolicyV1beta1Client
 func (w *wrapClient) PolicyV1beta1() typedpolicyv1beta1.PolicyV1beta1Interface {
 	return &wrapPolicyV1beta1{
@@ -379,3 +510,134 @@ func (w *wrapPolicyV1beta1ClusterImagePolicyImpl) UpdateStatus(ctx context.Conte
 func (w *wrapPolicyV1beta1ClusterImagePolicyImpl) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	return nil, errors.New("NYI: Watch")
 }
+
+func (w *wrapPolicyV1beta1) ImagePolicies(namespace string) typedpolicyv1beta1.ImagePolicyInterface {
+	return &wrapPolicyV1beta1ImagePolicyImpl{
+		dyn: w.dyn.Resource(schema.GroupVersionResource{
+			Group:    "policy.sigstore.dev",
+			Version:  "v1beta1",
+			Resource: "imagepolicies",
+		}),
+
+		namespace: namespace,
+	}
+}
+
+type wrapPolicyV1beta1ImagePolicyImpl struct {
+	dyn dynamic.NamespaceableResourceInterface
+
+	namespace string
+}
+
+var _ typedpolicyv1beta1.ImagePolicyInterface = (*wrapPolicyV1beta1ImagePolicyImpl)(nil)
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) Create(ctx context.Context, in *v1beta1.ImagePolicy, opts v1.CreateOptions) (*v1beta1.ImagePolicy, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "policy.sigstore.dev",
+		Version: "v1beta1",
+		Kind:    "ImagePolicy",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Namespace(w.namespace).Create(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1beta1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return w.dyn.Namespace(w.namespace).Delete(ctx, name, opts)
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	return w.dyn.Namespace(w.namespace).DeleteCollection(ctx, opts, listOpts)
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.ImagePolicy, error) {
+	uo, err := w.dyn.Namespace(w.namespace).Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1beta1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) List(ctx context.Context, opts v1.ListOptions) (*v1beta1.ImagePolicyList, error) {
+	uo, err := w.dyn.Namespace(w.namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1beta1.ImagePolicyList{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.ImagePolicy, err error) {
+	uo, err := w.dyn.Namespace(w.namespace).Patch(ctx, name, pt, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1beta1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) Update(ctx context.Context, in *v1beta1.ImagePolicy, opts v1.UpdateOptions) (*v1beta1.ImagePolicy, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "policy.sigstore.dev",
+		Version: "v1beta1",
+		Kind:    "ImagePolicy",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Namespace(w.namespace).Update(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1beta1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) UpdateStatus(ctx context.Context, in *v1beta1.ImagePolicy, opts v1.UpdateOptions) (*v1beta1.ImagePolicy, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "policy.sigstore.dev",
+		Version: "v1beta1",
+		Kind:    "ImagePolicy",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Namespace(w.namespace).UpdateStatus(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1beta1.ImagePolicy{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapPolicyV1beta1ImagePolicyImpl) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return nil, errors.New("NYI: Watch")
+}
@@ -19,3 +19,11 @@ package v1alpha1
 // ClusterImagePolicyListerExpansion allows custom methods to be added to
 // ClusterImagePolicyLister.
 type ClusterImagePolicyListerExpansion interface{}
+
+// ImagePolicyListerExpansion allows custom methods to be added to
+// ImagePolicyLister.
+type ImagePolicyListerExpansion interface{}
+
+// ImagePolicyNamespaceListerExpansion allows custom methods to be added to
+// ImagePolicyNamespaceLister.
+type ImagePolicyNamespaceListerExpansion interface{}
@@ -16,6 +16,7 @@
 package attestation
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -26,6 +27,11 @@ import (
 	slsa "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
 
 	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	"github.com/sigstore/cosign/pkg/cosign/sbom"
+	"github.com/sigstore/cosign/pkg/oci"
+	ctypes "github.com/sigstore/cosign/pkg/types"
 )
 
 const (
@@ -34,6 +40,9 @@ const (
 
 	// CosignVulnProvenanceV01 specifies the type of VulnerabilityScan Predicate
 	CosignVulnProvenanceV01 = "cosign.sigstore.dev/attestation/vuln/v1"
+
+	// PredicateCycloneDX is the predicate type of CycloneDX SBOMs.
+	PredicateCycloneDX = "https://cyclonedx.org/bom"
 )
 
 // CosignPredicate specifies the format of the Custom Predicate.
@@ -58,6 +67,13 @@ type CosignVulnStatement struct {
 	Predicate CosignVulnPredicate `json:"predicate"`
 }
 
+// CycloneDXStatement is an in-toto statement of a CycloneDX SBOM, which is
+// kept as is.
+type CycloneDXStatement struct {
+	in_toto.StatementHeader
+	Predicate interface{} `json:"predicate"`
+}
+
 type Invocation struct {
 	Parameters interface{} `json:"parameters"`
 	URI        string      `json:"uri"`
@@ -86,35 +102,80 @@ type Metadata struct {
 type GenerateOpts struct {
 	// Predicate is the source of bytes (e.g. a file) to use as the statement's predicate.
 	Predicate io.Reader
-	// Type is the pre-defined enums (provenance|link|spdx).
+	// Type is the pre-defined enums (slsaprovenance|slsaprovenance1|link|spdx|vuln|openvex|cyclonedx).
 	// default: custom
 	Type string
 	// Digest of the Image reference.
 	Digest string
 	// Repo context of the reference.
 	Repo string
+	// Format is the format of the predicate when it is a scanner report to
+	// convert into a vuln predicate (trivy|grype|sarif).
+	Format string
+	// Schema is an optional JSON schema, in JSON or YAML, the predicate must
+	// be valid against on top of the schema of its type.
+	Schema []byte
+	// ScanTime is the end of the scan of the scanner reports which don't
+	// record it.
+	ScanTime time.Time
 
 	// Function to return the time to set
 	Time func() time.Time
 }
 
 // GenerateStatement returns an in-toto statement based on the provided
-// predicate type (custom|slsaprovenance|spdx|link).
+// predicate type (custom|slsaprovenance|slsaprovenance1|spdx|link|vuln|openvex|cyclonedx).
+// The predicate is validated against the JSON schema of its type, and against
+// opts.Schema when set.
 func GenerateStatement(opts GenerateOpts) (interface{}, error) {
 	predicate, err := io.ReadAll(opts.Predicate)
 	if err != nil {
 		return nil, err
 	}
+	if opts.Format != "" {
+		if opts.Type != "vuln" {
+			return nil, fmt.Errorf("predicate format %s is only supported for vuln predicates", opts.Format)
+		}
+		vuln, err := VulnPredicateFromReport(opts.Format, predicate, opts.ScanTime)
+		if err != nil {
+			return nil, err
+		}
+		if predicate, err = json.Marshal(vuln); err != nil {
+			return nil, err
+		}
+	}
 
+	statement, err := generateStatement(predicate, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidatePredicate(opts.Type, predicate); err != nil {
+		return nil, err
+	}
+	if opts.Schema != nil {
+		if err := ValidatePredicateSchema(opts.Schema, predicate); err != nil {
+			return nil, err
+		}
+	}
+	return statement, nil
+}
+
+func generateStatement(predicate []byte, opts GenerateOpts) (interface{}, error) {
 	switch opts.Type {
 	case "slsaprovenance":
 		return generateSLSAProvenanceStatement(predicate, opts.Digest, opts.Repo)
+	case "slsaprovenance1":
+		return generateSLSAProvenanceV1Statement(predicate, opts.Digest, opts.Repo)
 	case "spdx":
 		return generateSPDXStatement(predicate, opts.Digest, opts.Repo)
 	case "link":
 		return generateLinkStatement(predicate, opts.Digest, opts.Repo)
 	case "vuln":
 		return generateVulnStatement(predicate, opts.Digest, opts.Repo)
+	case "openvex":
+		return generateOpenVEXStatement(predicate, opts.Digest, opts.Repo)
+	case "cyclonedx":
+		return generateCycloneDXStatement(predicate, opts.Digest, opts.Repo)
 	default:
 		stamp := timestamp(opts)
 		predicateType := customType(opts)
@@ -235,6 +296,20 @@ func generateSPDXStatement(rawPayload []byte, digest string, repo string) (inter
 	}, nil
 }
 
+func generateCycloneDXStatement(rawPayload []byte, digest string, repo string) (interface{}, error) {
+	if err := sbom.Validate(rawPayload, ctypes.CycloneDXJSONMediaType); err != nil {
+		return nil, fmt.Errorf("cyclonedx predicate: %w", err)
+	}
+	var bom interface{}
+	if err := json.Unmarshal(rawPayload, &bom); err != nil {
+		return nil, fmt.Errorf("unmarshal CycloneDX predicate: %w", err)
+	}
+	return CycloneDXStatement{
+		StatementHeader: generateStatementHeader(digest, repo, PredicateCycloneDX),
+		Predicate:       bom,
+	}, nil
+}
+
 func checkRequiredJSONFields(rawPayload []byte, typ reflect.Type) error {
 	var tmp map[string]interface{}
 	if err := json.Unmarshal(rawPayload, &tmp); err != nil {
@@ -258,3 +333,25 @@ func checkRequiredJSONFields(rawPayload []byte, typ reflect.Type) error {
 	}
 	return nil
 }
+
+// StatementFromAttestation returns the in-toto statement wrapped in the DSSE
+// envelope of an attestation. The signature of the envelope is not verified.
+func StatementFromAttestation(att oci.Signature) (*in_toto.Statement, error) {
+	p, err := att.Payload()
+	if err != nil {
+		return nil, fmt.Errorf("getting payload: %w", err)
+	}
+	e := dsse.Envelope{}
+	if err := json.Unmarshal(p, &e); err != nil {
+		return nil, fmt.Errorf("unmarshaling DSSE envelope: %w", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+	statement := &in_toto.Statement{}
+	if err := json.Unmarshal(decoded, statement); err != nil {
+		return nil, fmt.Errorf("unmarshaling in-toto statement: %w", err)
+	}
+	return statement, nil
+}
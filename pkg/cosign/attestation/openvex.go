@@ -0,0 +1,87 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+)
+
+// PredicateOpenVEX is the predicate type of OpenVEX documents.
+const PredicateOpenVEX = "https://openvex.dev/ns"
+
+// OpenVEXDocument is the predicate of an OpenVEX statement, as specified by
+// https://github.com/openvex/spec.
+type OpenVEXDocument struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Role       string             `json:"role,omitempty"`
+	Timestamp  string             `json:"timestamp"`
+	Version    json.Number        `json:"version"`
+	Tooling    string             `json:"tooling,omitempty"`
+	Statements []OpenVEXStatement `json:"statements"`
+}
+
+// OpenVEXStatement is the status of a vulnerability in some products.
+type OpenVEXStatement struct {
+	Vulnerability   interface{}   `json:"vulnerability"`
+	Products        []interface{} `json:"products,omitempty"`
+	Status          string        `json:"status"`
+	StatusNotes     string        `json:"status_notes,omitempty"`
+	Justification   string        `json:"justification,omitempty"`
+	ImpactStatement string        `json:"impact_statement,omitempty"`
+	ActionStatement string        `json:"action_statement,omitempty"`
+	Timestamp       string        `json:"timestamp,omitempty"`
+}
+
+// openVEXStatuses are the valid statuses of an OpenVEX statement.
+var openVEXStatuses = map[string]bool{
+	"not_affected":        true,
+	"affected":            true,
+	"fixed":               true,
+	"under_investigation": true,
+}
+
+// CosignOpenVEXStatement is an in-toto statement of an OpenVEX document.
+type CosignOpenVEXStatement struct {
+	in_toto.StatementHeader
+	Predicate OpenVEXDocument `json:"predicate"`
+}
+
+func generateOpenVEXStatement(rawPayload []byte, digest string, repo string) (interface{}, error) {
+	var doc OpenVEXDocument
+	if err := checkRequiredJSONFields(rawPayload, reflect.TypeOf(doc)); err != nil {
+		return nil, fmt.Errorf("openvex predicate: %w", err)
+	}
+	if err := json.Unmarshal(rawPayload, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal OpenVEX predicate: %w", err)
+	}
+	for i, s := range doc.Statements {
+		if s.Vulnerability == nil {
+			return nil, fmt.Errorf("openvex predicate: statement %d: required field vulnerability missing", i)
+		}
+		if !openVEXStatuses[s.Status] {
+			return nil, fmt.Errorf("openvex predicate: statement %d: invalid status %q", i, s.Status)
+		}
+	}
+	return CosignOpenVEXStatement{
+		StatementHeader: generateStatementHeader(digest, repo, PredicateOpenVEX),
+		Predicate:       doc,
+	}, nil
+}
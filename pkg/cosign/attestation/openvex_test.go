@@ -0,0 +1,93 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateOpenVEXAndCycloneDXStatements(t *testing.T) {
+	tests := []struct {
+		name          string
+		typ           string
+		predicate     string
+		wantErr       string
+		wantPredicate string
+	}{{
+		name: "valid openvex",
+		typ:  "openvex",
+		predicate: `{
+			"@context": "https://openvex.dev/ns", "@id": "https://example.com/vex-1",
+			"author": "someone", "timestamp": "2022-04-07T19:22:25Z", "version": 1,
+			"statements": [{"vulnerability": "CVE-2022-3294", "status": "not_affected", "justification": "component_not_present"}]
+		}`,
+		wantPredicate: PredicateOpenVEX,
+	}, {
+		name:      "openvex missing statements",
+		typ:       "openvex",
+		predicate: `{"@context": "https://openvex.dev/ns", "@id": "x", "author": "someone", "timestamp": "2022-04-07T19:22:25Z", "version": 1}`,
+		wantErr:   "required field statements missing",
+	}, {
+		name: "openvex invalid status",
+		typ:  "openvex",
+		predicate: `{
+			"@context": "https://openvex.dev/ns", "@id": "x", "author": "someone", "timestamp": "2022-04-07T19:22:25Z", "version": 1,
+			"statements": [{"vulnerability": "CVE-2022-3294", "status": "fine"}]
+		}`,
+		wantErr: `statement 0: invalid status "fine"`,
+	}, {
+		name:          "valid cyclonedx",
+		typ:           "cyclonedx",
+		predicate:     `{"bomFormat": "CycloneDX", "specVersion": "1.4", "version": 1, "components": []}`,
+		wantPredicate: PredicateCycloneDX,
+	}, {
+		name:      "cyclonedx wrong format",
+		typ:       "cyclonedx",
+		predicate: `{"spdxVersion": "SPDX-2.2"}`,
+		wantErr:   "CycloneDX",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := GenerateStatement(GenerateOpts{
+				Predicate: strings.NewReader(test.predicate),
+				Type:      test.typ,
+				Digest:    "deadbeef",
+				Repo:      "example.com/repo",
+			})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("GenerateStatement() = %v, wanted error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateStatement() = %v", err)
+			}
+			var predicateType string
+			switch s := got.(type) {
+			case CosignOpenVEXStatement:
+				predicateType = s.PredicateType
+			case CycloneDXStatement:
+				predicateType = s.PredicateType
+			default:
+				t.Fatalf("GenerateStatement() = %T", got)
+			}
+			if predicateType != test.wantPredicate {
+				t.Errorf("PredicateType = %q, wanted %q", predicateType, test.wantPredicate)
+			}
+		})
+	}
+}
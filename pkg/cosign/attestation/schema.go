@@ -0,0 +1,85 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sigstore/cosign/pkg/cosign/jsonschema"
+	"github.com/sigstore/cosign/pkg/cosign/sbom"
+	ctypes "github.com/sigstore/cosign/pkg/types"
+)
+
+// The schemas are generated from the predicate types by cmd/schema, run
+// ./hack/update-codegen.sh after changing them.
+//
+//go:embed schemas
+var schemas embed.FS
+
+// ValidatePredicate checks that predicate is valid against the JSON schema
+// of the given predicate type (slsaprovenance|slsaprovenance1|link|spdx|vuln|openvex).
+// Predicates of other types are not checked.
+func ValidatePredicate(predicateType string, predicate []byte) error {
+	if predicateType == "spdx" && !json.Valid(predicate) {
+		// Tag-value SPDX documents have no JSON schema.
+		return sbom.Validate(predicate, ctypes.SPDXMediaType)
+	}
+
+	b, err := schemas.ReadFile(path.Join("schemas", predicateType+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := validateAgainstSchema(b, predicate); err != nil {
+		return fmt.Errorf("%s predicate: %w", predicateType, err)
+	}
+	if predicateType == "spdx" {
+		return sbom.Validate(predicate, ctypes.SPDXJSONMediaType)
+	}
+	return nil
+}
+
+// ValidatePredicateSchema checks that predicate is valid against schema, a
+// JSON schema in JSON or YAML.
+func ValidatePredicateSchema(schema, predicate []byte) error {
+	b, err := yaml.YAMLToJSON(schema)
+	if err != nil {
+		return fmt.Errorf("parsing predicate schema: %w", err)
+	}
+	if _, err := jsonschema.Compile(string(b)); err != nil {
+		return fmt.Errorf("parsing predicate schema: %w", err)
+	}
+	if err := validateAgainstSchema(b, predicate); err != nil {
+		return fmt.Errorf("predicate: %w", err)
+	}
+	return nil
+}
+
+// validateAgainstSchema validates predicate against schema with the engine
+// of the jsonschema policies.
+func validateAgainstSchema(schema, predicate []byte) error {
+	if !json.Valid(predicate) {
+		return errors.New("invalid JSON")
+	}
+	return jsonschema.ValidateJSON(predicate, string(schema))
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"strings"
+	"testing"
+)
+
+const validVuln = `{
+	"invocation": {"parameters": null, "uri": "invocation.example.com", "event_id": "", "builder.id": ""},
+	"scanner": {"uri": "scanner.example.com", "version": "", "db": {"uri": "", "version": ""}, "result": null},
+	"metadata": {"scanStartedOn": "2022-04-12T00:00:00Z", "scanFinishedOn": "2022-04-12T00:10:00Z"}
+}`
+
+const validSPDX = `{
+	"spdxVersion": "SPDX-2.2", "dataLicense": "CC0-1.0", "SPDXID": "SPDXRef-DOCUMENT", "name": "demo",
+	"documentNamespace": "https://example.com/demo",
+	"creationInfo": {"created": "2022-04-12T00:00:00Z", "creators": ["Tool: syft"]},
+	"packages": [{"SPDXID": "SPDXRef-Package-x", "name": "x", "downloadLocation": "NOASSERTION"}]
+}`
+
+func TestValidatePredicate(t *testing.T) {
+	tests := []struct {
+		name          string
+		predicateType string
+		predicate     string
+		wantErr       string
+	}{{
+		name:          "valid vuln",
+		predicateType: "vuln",
+		predicate:     validVuln,
+	}, {
+		name:          "vuln with invalid date",
+		predicateType: "vuln",
+		predicate:     strings.Replace(validVuln, "2022-04-12T00:10:00Z", "yesterday", 1),
+		wantErr:       "scanFinishedOn",
+	}, {
+		name:          "vuln missing nested field",
+		predicateType: "vuln",
+		predicate:     strings.Replace(validVuln, `"db": {"uri": "", "version": ""}, `, "", 1),
+		wantErr:       "scanner: db is required",
+	}, {
+		name:          "slsaprovenance with wrong type",
+		predicateType: "slsaprovenance",
+		predicate:     `{"builder": {"id": "https://example.com/builder"}, "buildType": 42}`,
+		wantErr:       "buildType: Invalid type. Expected: string",
+	}, {
+		name:          "valid slsaprovenance",
+		predicateType: "slsaprovenance",
+		predicate:     `{"builder": {"id": "https://example.com/builder"}, "buildType": "https://example.com/build/v1", "metadata": null}`,
+	}, {
+		name:          "link with invalid command",
+		predicateType: "link",
+		predicate:     `{"_type": "link", "name": "build", "materials": {}, "products": {}, "byproducts": {}, "command": "make", "environment": {}}`,
+		wantErr:       "command: Invalid type. Expected: [array,null]",
+	}, {
+		name:          "valid json spdx",
+		predicateType: "spdx",
+		predicate:     validSPDX,
+	}, {
+		name:          "json spdx missing creators",
+		predicateType: "spdx",
+		predicate:     strings.Replace(validSPDX, `, "creators": ["Tool: syft"]`, "", 1),
+		wantErr:       "creationInfo: creators is required",
+	}, {
+		name:          "json spdx with wrong document id",
+		predicateType: "spdx",
+		predicate:     strings.Replace(validSPDX, "SPDXRef-DOCUMENT", "SPDXRef-demo", 1),
+		wantErr:       "unexpected document SPDXID",
+	}, {
+		name:          "tag-value spdx",
+		predicateType: "spdx",
+		predicate:     "SPDXVersion: SPDX-2.2\nSPDXID: SPDXRef-DOCUMENT\nDocumentName: demo\n",
+	}, {
+		name:          "invalid tag-value spdx",
+		predicateType: "spdx",
+		predicate:     "not an sbom",
+		wantErr:       "invalid SPDX SBOM",
+	}, {
+		name:          "custom is not checked",
+		predicateType: "custom",
+		predicate:     "anything",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePredicate(test.predicateType, []byte(test.predicate))
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidatePredicate() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("ValidatePredicate() = %v, wanted error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateStatementWithSchema(t *testing.T) {
+	schema := `
+type: object
+required: [team]
+properties:
+  team:
+    type: string
+    enum: [release, security]
+`
+	tests := []struct {
+		predicate string
+		wantErr   string
+	}{{
+		predicate: `{"team": "release"}`,
+	}, {
+		predicate: `{"team": "marketing"}`,
+		wantErr:   "team must be one of the following",
+	}, {
+		predicate: `{}`,
+		wantErr:   "team is required",
+	}, {
+		predicate: `not json`,
+		wantErr:   "invalid JSON",
+	}}
+	for _, test := range tests {
+		_, err := GenerateStatement(GenerateOpts{
+			Predicate: strings.NewReader(test.predicate),
+			Type:      "https://example.com/team/v1",
+			Digest:    "deadbeef",
+			Repo:      "example.com/repo",
+			Schema:    []byte(schema),
+		})
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("GenerateStatement(%s) = %v", test.predicate, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("GenerateStatement(%s) = %v, wanted error containing %q", test.predicate, err, test.wantErr)
+		}
+	}
+}
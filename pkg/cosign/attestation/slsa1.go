@@ -0,0 +1,129 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+)
+
+// PredicateSLSAProvenanceV1 is the predicate type of SLSA v1.0 provenance.
+const PredicateSLSAProvenanceV1 = "https://slsa.dev/provenance/v1"
+
+// The vendored in-toto library only knows SLSA provenance up to v0.2, these
+// types follow https://slsa.dev/spec/v1.0/provenance until it catches up.
+
+// SLSAProvenanceV1Predicate is the predicate of SLSA v1.0 provenance.
+type SLSAProvenanceV1Predicate struct {
+	BuildDefinition SLSABuildDefinition `json:"buildDefinition"`
+	RunDetails      SLSARunDetails      `json:"runDetails"`
+}
+
+// SLSAProvenanceV1Statement is an in-toto statement of SLSA v1.0 provenance.
+type SLSAProvenanceV1Statement struct {
+	in_toto.StatementHeader
+	Predicate SLSAProvenanceV1Predicate `json:"predicate"`
+}
+
+// SLSABuildDefinition describes the inputs of the build.
+type SLSABuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]interface{} `json:"externalParameters"`
+	InternalParameters   map[string]interface{} `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor   `json:"resolvedDependencies,omitempty"`
+}
+
+// SLSARunDetails describes this particular execution of the build.
+type SLSARunDetails struct {
+	Builder    SLSABuilder          `json:"builder"`
+	Metadata   *SLSABuildMetadata   `json:"metadata,omitempty"`
+	Byproducts []ResourceDescriptor `json:"byproducts,omitempty"`
+}
+
+// SLSABuilder identifies the entity that executed the build.
+type SLSABuilder struct {
+	ID                  string               `json:"id"`
+	Version             map[string]string    `json:"version,omitempty"`
+	BuilderDependencies []ResourceDescriptor `json:"builderDependencies,omitempty"`
+}
+
+// SLSABuildMetadata holds the metadata of the build.
+type SLSABuildMetadata struct {
+	InvocationID string     `json:"invocationId,omitempty"`
+	StartedOn    *time.Time `json:"startedOn,omitempty"`
+	FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+}
+
+// ResourceDescriptor describes an artifact or resource, such as a dependency
+// of the build.
+type ResourceDescriptor struct {
+	URI              string                 `json:"uri,omitempty"`
+	Digest           map[string]string      `json:"digest,omitempty"`
+	Name             string                 `json:"name,omitempty"`
+	DownloadLocation string                 `json:"downloadLocation,omitempty"`
+	MediaType        string                 `json:"mediaType,omitempty"`
+	Content          []byte                 `json:"content,omitempty"`
+	Annotations      map[string]interface{} `json:"annotations,omitempty"`
+}
+
+func generateSLSAProvenanceV1Statement(rawPayload []byte, digest string, repo string) (interface{}, error) {
+	if err := checkSLSAProvenanceV1Fields(rawPayload); err != nil {
+		return nil, fmt.Errorf("provenance v1 predicate: %w", err)
+	}
+	var predicate SLSAProvenanceV1Predicate
+	if err := json.Unmarshal(rawPayload, &predicate); err != nil {
+		return nil, fmt.Errorf("unmarshal Provenance v1 predicate: %w", err)
+	}
+	return SLSAProvenanceV1Statement{
+		StatementHeader: generateStatementHeader(digest, repo, PredicateSLSAProvenanceV1),
+		Predicate:       predicate,
+	}, nil
+}
+
+// checkSLSAProvenanceV1Fields checks the required fields of the predicate are
+// present, including those of its nested objects.
+func checkSLSAProvenanceV1Fields(rawPayload []byte) error {
+	var predicate SLSAProvenanceV1Predicate
+	if err := checkRequiredJSONFields(rawPayload, reflect.TypeOf(predicate)); err != nil {
+		return err
+	}
+	var fields struct {
+		BuildDefinition json.RawMessage `json:"buildDefinition"`
+		RunDetails      json.RawMessage `json:"runDetails"`
+	}
+	if err := json.Unmarshal(rawPayload, &fields); err != nil {
+		return err
+	}
+	if err := checkRequiredJSONFields(fields.BuildDefinition, reflect.TypeOf(predicate.BuildDefinition)); err != nil {
+		return fmt.Errorf("buildDefinition: %w", err)
+	}
+	if err := checkRequiredJSONFields(fields.RunDetails, reflect.TypeOf(predicate.RunDetails)); err != nil {
+		return fmt.Errorf("runDetails: %w", err)
+	}
+	var runDetails struct {
+		Builder json.RawMessage `json:"builder"`
+	}
+	if err := json.Unmarshal(fields.RunDetails, &runDetails); err != nil {
+		return err
+	}
+	if err := checkRequiredJSONFields(runDetails.Builder, reflect.TypeOf(predicate.RunDetails.Builder)); err != nil {
+		return fmt.Errorf("runDetails.builder: %w", err)
+	}
+	return nil
+}
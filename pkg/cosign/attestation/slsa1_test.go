@@ -0,0 +1,85 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSLSAProvenanceV1Statement(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate string
+		wantErr   string
+	}{{
+		name: "valid",
+		predicate: `{
+			"buildDefinition": {"buildType": "https://example.com/build/v1", "externalParameters": {"ref": "main"}},
+			"runDetails": {"builder": {"id": "https://example.com/builder"}}
+		}`,
+	}, {
+		name:      "missing runDetails",
+		predicate: `{"buildDefinition": {"buildType": "https://example.com/build/v1", "externalParameters": {}}}`,
+		wantErr:   "required field runDetails missing",
+	}, {
+		name: "missing buildType",
+		predicate: `{
+			"buildDefinition": {"externalParameters": {}},
+			"runDetails": {"builder": {"id": "https://example.com/builder"}}
+		}`,
+		wantErr: "buildDefinition: required field buildType missing",
+	}, {
+		name: "missing builder id",
+		predicate: `{
+			"buildDefinition": {"buildType": "https://example.com/build/v1", "externalParameters": {}},
+			"runDetails": {"builder": {}}
+		}`,
+		wantErr: "runDetails.builder: required field id missing",
+	}, {
+		name:      "v0.2 predicate",
+		predicate: `{"builder": {"id": "https://example.com/builder"}, "buildType": "https://example.com/build/v1"}`,
+		wantErr:   "required field buildDefinition missing",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := GenerateStatement(GenerateOpts{
+				Predicate: strings.NewReader(test.predicate),
+				Type:      "slsaprovenance1",
+				Digest:    "deadbeef",
+				Repo:      "example.com/repo",
+			})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("GenerateStatement() = %v, wanted error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateStatement() = %v", err)
+			}
+			statement, ok := got.(SLSAProvenanceV1Statement)
+			if !ok {
+				t.Fatalf("GenerateStatement() = %T, wanted SLSAProvenanceV1Statement", got)
+			}
+			if statement.PredicateType != PredicateSLSAProvenanceV1 {
+				t.Errorf("PredicateType = %q, wanted %q", statement.PredicateType, PredicateSLSAProvenanceV1)
+			}
+			if statement.Predicate.RunDetails.Builder.ID != "https://example.com/builder" {
+				t.Errorf("builder id = %q", statement.Predicate.RunDetails.Builder.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+// SPDXDocument is the subset of an SPDX 2.x JSON document that the schema of
+// spdx predicates checks. The predicate itself is attested as is.
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages,omitempty"`
+	Files             []interface{}      `json:"files,omitempty"`
+	Relationships     []SPDXRelationship `json:"relationships,omitempty"`
+}
+
+// SPDXCreationInfo tells who created an SPDX document and when.
+type SPDXCreationInfo struct {
+	Created            string   `json:"created"`
+	Creators           []string `json:"creators"`
+	LicenseListVersion string   `json:"licenseListVersion,omitempty"`
+	Comment            string   `json:"comment,omitempty"`
+}
+
+// SPDXPackage is a package described by an SPDX document.
+type SPDXPackage struct {
+	SPDXID           string        `json:"SPDXID"`
+	Name             string        `json:"name"`
+	DownloadLocation string        `json:"downloadLocation"`
+	VersionInfo      string        `json:"versionInfo,omitempty"`
+	Supplier         string        `json:"supplier,omitempty"`
+	LicenseConcluded string        `json:"licenseConcluded,omitempty"`
+	LicenseDeclared  string        `json:"licenseDeclared,omitempty"`
+	CopyrightText    string        `json:"copyrightText,omitempty"`
+	ExternalRefs     []interface{} `json:"externalRefs,omitempty"`
+	Checksums        []interface{} `json:"checksums,omitempty"`
+}
+
+// SPDXRelationship is a relationship between two elements of an SPDX document.
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
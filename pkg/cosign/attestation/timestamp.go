@@ -0,0 +1,90 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	slsa "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+)
+
+// PredicateTimestamp returns when the predicate of statement says it was
+// produced: the end of the scan of vuln predicates, the end of the build of
+// SLSA provenance, and the timestamp of custom and OpenVEX predicates.
+// ok is false when the predicate has no such timestamp.
+func PredicateTimestamp(statement *in_toto.Statement) (t time.Time, ok bool, err error) {
+	var timestamp string
+	switch statement.PredicateType {
+	case CosignVulnProvenanceV01:
+		var p struct {
+			Metadata struct {
+				ScanFinishedOn string `json:"scanFinishedOn"`
+			} `json:"metadata"`
+		}
+		err = convertPredicate(statement.Predicate, &p)
+		timestamp = p.Metadata.ScanFinishedOn
+	case slsa.PredicateSLSAProvenance:
+		var p struct {
+			Metadata struct {
+				BuildFinishedOn string `json:"buildFinishedOn"`
+			} `json:"metadata"`
+		}
+		err = convertPredicate(statement.Predicate, &p)
+		timestamp = p.Metadata.BuildFinishedOn
+	case PredicateSLSAProvenanceV1:
+		var p struct {
+			RunDetails struct {
+				Metadata struct {
+					FinishedOn string `json:"finishedOn"`
+				} `json:"metadata"`
+			} `json:"runDetails"`
+		}
+		err = convertPredicate(statement.Predicate, &p)
+		timestamp = p.RunDetails.Metadata.FinishedOn
+	case PredicateOpenVEX:
+		var p struct {
+			Timestamp string `json:"timestamp"`
+		}
+		err = convertPredicate(statement.Predicate, &p)
+		timestamp = p.Timestamp
+	case CosignCustomProvenanceV01:
+		var p CosignPredicate
+		err = convertPredicate(statement.Predicate, &p)
+		timestamp = p.Timestamp
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("reading %s predicate: %w", statement.PredicateType, err)
+	}
+	if timestamp == "" {
+		return time.Time{}, false, nil
+	}
+	t, err = time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid timestamp in %s predicate: %w", statement.PredicateType, err)
+	}
+	return t, true, nil
+}
+
+// convertPredicate converts the predicate of a decoded statement into out.
+func convertPredicate(predicate interface{}, out interface{}) error {
+	b, err := json.Marshal(predicate)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
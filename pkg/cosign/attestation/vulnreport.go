@@ -0,0 +1,220 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The formats of the scanner reports which can be converted into a vuln
+// predicate.
+const (
+	VulnReportTrivy = "trivy"
+	VulnReportGrype = "grype"
+	VulnReportSARIF = "sarif"
+)
+
+// VulnReportFormats are the supported scanner report formats.
+var VulnReportFormats = []string{VulnReportTrivy, VulnReportGrype, VulnReportSARIF}
+
+const (
+	trivyURI   = "https://github.com/aquasecurity/trivy"
+	trivyDBURI = "ghcr.io/aquasecurity/trivy-db"
+	grypeURI   = "https://github.com/anchore/grype"
+	grypeDBURI = "https://toolbox-data.anchore.io/grype/databases/listing.json"
+)
+
+// VulnPredicateFromReport converts a JSON scanner report of the given format
+// (trivy|grype|sarif) into a vuln predicate. The whole report is kept as the
+// result of the scan. scanTime is the end of the scan of the reports which
+// don't record it, which are rejected when it is zero: dating them when they
+// are converted would make old scans look recent.
+func VulnPredicateFromReport(format string, report []byte, scanTime time.Time) (*CosignVulnPredicate, error) {
+	var result map[string]interface{}
+	if err := json.Unmarshal(report, &result); err != nil {
+		return nil, fmt.Errorf("invalid %s report: %w", format, err)
+	}
+
+	var (
+		p   *CosignVulnPredicate
+		err error
+	)
+	switch format {
+	case VulnReportTrivy:
+		p, err = fromTrivy(report)
+	case VulnReportGrype:
+		p, err = fromGrype(report)
+	case VulnReportSARIF:
+		p, err = fromSARIF(report)
+	default:
+		return nil, fmt.Errorf("unsupported report format %q, expected one of %v", format, VulnReportFormats)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s report: %w", format, err)
+	}
+
+	p.Scanner.Result = result
+	if p.Metadata.ScanFinishedOn.IsZero() {
+		if scanTime.IsZero() {
+			return nil, fmt.Errorf("the %s report has no scan time, set it explicitly", format)
+		}
+		p.Metadata.ScanFinishedOn = scanTime.UTC()
+	}
+	if p.Metadata.ScanStartedOn.IsZero() {
+		p.Metadata.ScanStartedOn = p.Metadata.ScanFinishedOn
+	}
+	return p, nil
+}
+
+// parseTime parses an optional RFC 3339 timestamp.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+func fromTrivy(report []byte) (*CosignVulnPredicate, error) {
+	var r struct {
+		SchemaVersion int    `json:"SchemaVersion"`
+		CreatedAt     string `json:"CreatedAt"`
+		Trivy         struct {
+			Version string `json:"Version"`
+		} `json:"Trivy"`
+		Metadata struct {
+			DB struct {
+				Version   int    `json:"Version"`
+				UpdatedAt string `json:"UpdatedAt"`
+			} `json:"DB"`
+		} `json:"Metadata"`
+	}
+	if err := json.Unmarshal(report, &r); err != nil {
+		return nil, err
+	}
+	if r.SchemaVersion == 0 {
+		return nil, errors.New("missing SchemaVersion")
+	}
+	createdAt, err := parseTime(r.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("CreatedAt: %w", err)
+	}
+	// The database, when the report has its metadata, is identified by its
+	// schema and update time, like the grype one.
+	dbVersion := r.Metadata.DB.UpdatedAt
+	if r.Metadata.DB.Version != 0 {
+		dbVersion = fmt.Sprintf("v%d/%s", r.Metadata.DB.Version, r.Metadata.DB.UpdatedAt)
+	}
+	return &CosignVulnPredicate{
+		Scanner: Scanner{
+			URI:     trivyURI,
+			Version: r.Trivy.Version,
+			DB:      DB{URI: trivyDBURI, Version: dbVersion},
+		},
+		Metadata: Metadata{ScanStartedOn: createdAt, ScanFinishedOn: createdAt},
+	}, nil
+}
+
+func fromGrype(report []byte) (*CosignVulnPredicate, error) {
+	var r struct {
+		Matches    *json.RawMessage `json:"matches"`
+		Descriptor struct {
+			Name      string `json:"name"`
+			Version   string `json:"version"`
+			Timestamp string `json:"timestamp"`
+			DB        struct {
+				Built         string      `json:"built"`
+				SchemaVersion json.Number `json:"schemaVersion"`
+			} `json:"db"`
+		} `json:"descriptor"`
+	}
+	if err := json.Unmarshal(report, &r); err != nil {
+		return nil, err
+	}
+	if r.Matches == nil || r.Descriptor.Name == "" {
+		return nil, errors.New("missing matches or descriptor")
+	}
+	timestamp, err := parseTime(r.Descriptor.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("descriptor.timestamp: %w", err)
+	}
+	// The database is identified by its schema and build time.
+	dbVersion := r.Descriptor.DB.Built
+	if r.Descriptor.DB.SchemaVersion != "" {
+		dbVersion = fmt.Sprintf("v%s/%s", r.Descriptor.DB.SchemaVersion, r.Descriptor.DB.Built)
+	}
+	return &CosignVulnPredicate{
+		Scanner: Scanner{
+			URI:     grypeURI,
+			Version: r.Descriptor.Version,
+			DB:      DB{URI: grypeDBURI, Version: dbVersion},
+		},
+		Metadata: Metadata{ScanStartedOn: timestamp, ScanFinishedOn: timestamp},
+	}, nil
+}
+
+func fromSARIF(report []byte) (*CosignVulnPredicate, error) {
+	var r struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name            string `json:"name"`
+					Version         string `json:"version"`
+					SemanticVersion string `json:"semanticVersion"`
+					InformationURI  string `json:"informationUri"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Invocations []struct {
+				StartTimeUTC string `json:"startTimeUtc"`
+				EndTimeUTC   string `json:"endTimeUtc"`
+			} `json:"invocations"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(report, &r); err != nil {
+		return nil, err
+	}
+	if r.Version == "" || len(r.Runs) == 0 {
+		return nil, errors.New("missing version or runs")
+	}
+	// The scanner is the tool of the first run.
+	run := r.Runs[0]
+	driver := run.Tool.Driver
+	p := &CosignVulnPredicate{
+		Scanner: Scanner{URI: driver.InformationURI, Version: driver.SemanticVersion},
+	}
+	if p.Scanner.URI == "" {
+		p.Scanner.URI = driver.Name
+	}
+	if p.Scanner.Version == "" {
+		p.Scanner.Version = driver.Version
+	}
+	if len(run.Invocations) > 0 {
+		var err error
+		if p.Metadata.ScanStartedOn, err = parseTime(run.Invocations[0].StartTimeUTC); err != nil {
+			return nil, fmt.Errorf("startTimeUtc: %w", err)
+		}
+		if p.Metadata.ScanFinishedOn, err = parseTime(run.Invocations[0].EndTimeUTC); err != nil {
+			return nil, fmt.Errorf("endTimeUtc: %w", err)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+)
+
+func TestVulnPredicateFromReport(t *testing.T) {
+	tests := []struct {
+		format       string
+		wantScanner  string
+		wantVersion  string
+		wantDB       DB
+		wantStarted  time.Time
+		wantFinished time.Time
+		wantResult   string
+	}{{
+		format:       VulnReportTrivy,
+		wantScanner:  "https://github.com/aquasecurity/trivy",
+		wantVersion:  "0.27.1",
+		wantDB:       DB{URI: "ghcr.io/aquasecurity/trivy-db", Version: "v2/2022-04-12T06:08:41Z"},
+		wantStarted:  time.Date(2022, 4, 12, 9, 21, 45, 123456789, time.UTC),
+		wantFinished: time.Date(2022, 4, 12, 9, 21, 45, 123456789, time.UTC),
+		wantResult:   "Results",
+	}, {
+		format:       VulnReportGrype,
+		wantScanner:  "https://github.com/anchore/grype",
+		wantVersion:  "0.36.1",
+		wantDB:       DB{URI: "https://toolbox-data.anchore.io/grype/databases/listing.json", Version: "v3/2022-04-12T08:16:51Z"},
+		wantStarted:  time.Date(2022, 4, 12, 9, 21, 45, 123000000, time.UTC),
+		wantFinished: time.Date(2022, 4, 12, 9, 21, 45, 123000000, time.UTC),
+		wantResult:   "matches",
+	}, {
+		format:       VulnReportSARIF,
+		wantScanner:  "https://github.com/aquasecurity/trivy",
+		wantVersion:  "0.27.1",
+		wantStarted:  time.Date(2022, 4, 12, 9, 20, 0, 0, time.UTC),
+		wantFinished: time.Date(2022, 4, 12, 9, 21, 45, 0, time.UTC),
+		wantResult:   "runs",
+	}}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			report, err := os.ReadFile(filepath.Join("testdata", test.format+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := VulnPredicateFromReport(test.format, report, time.Time{})
+			if err != nil {
+				t.Fatalf("VulnPredicateFromReport() = %v", err)
+			}
+			if p.Scanner.URI != test.wantScanner || p.Scanner.Version != test.wantVersion {
+				t.Errorf("scanner = %s@%s, wanted %s@%s", p.Scanner.URI, p.Scanner.Version, test.wantScanner, test.wantVersion)
+			}
+			if p.Scanner.DB != test.wantDB {
+				t.Errorf("db = %+v, wanted %+v", p.Scanner.DB, test.wantDB)
+			}
+			if !p.Metadata.ScanStartedOn.Equal(test.wantStarted) || !p.Metadata.ScanFinishedOn.Equal(test.wantFinished) {
+				t.Errorf("scan from %v to %v, wanted from %v to %v", p.Metadata.ScanStartedOn, p.Metadata.ScanFinishedOn, test.wantStarted, test.wantFinished)
+			}
+			if _, ok := p.Scanner.Result[test.wantResult]; !ok {
+				t.Errorf("result is missing %q: %v", test.wantResult, p.Scanner.Result)
+			}
+		})
+	}
+}
+
+func TestVulnPredicateFromReportErrors(t *testing.T) {
+	now := time.Date(2022, 4, 13, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format  string
+		report  string
+		wantErr string
+	}{{
+		format:  "clair",
+		report:  `{}`,
+		wantErr: `unsupported report format "clair"`,
+	}, {
+		format:  VulnReportTrivy,
+		report:  `[]`,
+		wantErr: "invalid trivy report",
+	}, {
+		format:  VulnReportTrivy,
+		report:  `{"matches": [], "descriptor": {"name": "grype"}}`,
+		wantErr: "missing SchemaVersion",
+	}, {
+		format:  VulnReportGrype,
+		report:  `{"SchemaVersion": 2, "Results": []}`,
+		wantErr: "missing matches or descriptor",
+	}, {
+		format:  VulnReportSARIF,
+		report:  `{"version": "2.1.0", "runs": []}`,
+		wantErr: "missing version or runs",
+	}, {
+		format:  VulnReportTrivy,
+		report:  `{"SchemaVersion": 2, "CreatedAt": "yesterday"}`,
+		wantErr: "CreatedAt",
+	}, {
+		format:  VulnReportTrivy,
+		report:  `{"SchemaVersion": 2}`,
+		wantErr: "the trivy report has no scan time",
+	}}
+	for _, test := range tests {
+		_, err := VulnPredicateFromReport(test.format, []byte(test.report), time.Time{})
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("VulnPredicateFromReport(%s, %s) = %v, wanted error containing %q", test.format, test.report, err, test.wantErr)
+		}
+	}
+
+	// Reports without timestamps are dated with the given scan time.
+	p, err := VulnPredicateFromReport(VulnReportTrivy, []byte(`{"SchemaVersion": 2}`), now)
+	if err != nil {
+		t.Fatalf("VulnPredicateFromReport() = %v", err)
+	}
+	if !p.Metadata.ScanStartedOn.Equal(now) || !p.Metadata.ScanFinishedOn.Equal(now) {
+		t.Errorf("scan from %v to %v, wanted %v", p.Metadata.ScanStartedOn, p.Metadata.ScanFinishedOn, now)
+	}
+}
+
+func TestGenerateStatementFromReport(t *testing.T) {
+	report, err := os.ReadFile(filepath.Join("testdata", "grype.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := GenerateStatement(GenerateOpts{
+		Predicate: bytes.NewReader(report),
+		Type:      "vuln",
+		Format:    VulnReportGrype,
+		Digest:    "deadbeef",
+		Repo:      "example.com/repo",
+	})
+	if err != nil {
+		t.Fatalf("GenerateStatement() = %v", err)
+	}
+	statement, ok := got.(in_toto.Statement)
+	if !ok {
+		t.Fatalf("GenerateStatement() = %T, wanted in_toto.Statement", got)
+	}
+	if statement.PredicateType != CosignVulnProvenanceV01 {
+		t.Errorf("PredicateType = %q, wanted %q", statement.PredicateType, CosignVulnProvenanceV01)
+	}
+	if vuln, ok := statement.Predicate.(CosignVulnPredicate); !ok || vuln.Scanner.Version != "0.36.1" {
+		t.Errorf("Predicate = %+v, wanted the grype scan", statement.Predicate)
+	}
+
+	if _, err := GenerateStatement(GenerateOpts{
+		Predicate: bytes.NewReader(report),
+		Type:      "slsaprovenance",
+		Format:    VulnReportGrype,
+	}); err == nil || !strings.Contains(err.Error(), "only supported for vuln predicates") {
+		t.Errorf("GenerateStatement() = %v, wanted an error for a non vuln predicate", err)
+	}
+}
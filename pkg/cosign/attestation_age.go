@@ -0,0 +1,87 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/pkg/oci"
+)
+
+// maxClockSkew is how far in the future the timestamp of an attestation may
+// be, to allow for the clock of its producer being ahead.
+const maxClockSkew = 5 * time.Minute
+
+// CheckAttestationAge returns an error when att was produced more than maxAge
+// ago. It was produced at the earliest of the timestamp of its predicate, such
+// as the end of a vulnerability scan, and of its integration in the
+// transparency log, which is integratedTime when set or else the one of its
+// bundle. Attestations with none of them, or produced in the future, are
+// rejected.
+func CheckAttestationAge(att oci.Signature, integratedTime time.Time, maxAge time.Duration) error {
+	produced, err := attestationTime(att, integratedTime)
+	if err != nil {
+		return err
+	}
+	age := time.Since(produced)
+	if age < -maxClockSkew {
+		return fmt.Errorf("attestation is from the future: produced at %s", produced.UTC().Format(time.RFC3339))
+	}
+	if age > maxAge {
+		return fmt.Errorf("attestation is too old: produced at %s, more than %s ago", produced.UTC().Format(time.RFC3339), maxAge)
+	}
+	return nil
+}
+
+func attestationTime(att oci.Signature, integratedTime time.Time) (time.Time, error) {
+	var times []time.Time
+	if !integratedTime.IsZero() {
+		times = append(times, integratedTime)
+	} else {
+		bundle, err := att.Bundle()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if bundle != nil {
+			times = append(times, time.Unix(bundle.Payload.IntegratedTime, 0))
+		}
+	}
+
+	statement, err := attestation.StatementFromAttestation(att)
+	if err != nil {
+		return time.Time{}, err
+	}
+	predicateTime, ok, err := attestation.PredicateTimestamp(statement)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ok {
+		times = append(times, predicateTime)
+	}
+
+	if len(times) == 0 {
+		return time.Time{}, errors.New("unable to tell the age of the attestation: its predicate has no timestamp and it has no transparency log entry")
+	}
+	produced := times[0]
+	for _, t := range times[1:] {
+		if t.Before(produced) {
+			produced = t
+		}
+	}
+	return produced, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+// testAttestation returns an attestation of the given predicate, integrated
+// in the transparency log at integratedTime when set.
+func testAttestation(t *testing.T, predicateType string, predicate interface{}, integratedTime time.Time) oci.Signature {
+	t.Helper()
+	statement, err := json.Marshal(map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v0.1",
+		"predicateType": predicateType,
+		"subject":       []interface{}{},
+		"predicate":     predicate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := json.Marshal(map[string]interface{}{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures":  []interface{}{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opts []static.Option
+	if !integratedTime.IsZero() {
+		opts = append(opts, static.WithBundle(&bundle.RekorBundle{
+			Payload: bundle.RekorPayload{IntegratedTime: integratedTime.Unix()},
+		}))
+	}
+	att, err := static.NewAttestation(envelope, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return att
+}
+
+func TestCheckAttestationAge(t *testing.T) {
+	now := time.Now()
+	hourAgo := now.Add(-time.Hour).UTC().Format(time.RFC3339)
+	weekAgo := now.Add(-7 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	minuteAhead := now.Add(time.Minute).UTC().Format(time.RFC3339)
+	dayAhead := now.Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	vuln := func(finished string) interface{} {
+		return map[string]interface{}{"metadata": map[string]interface{}{"scanStartedOn": finished, "scanFinishedOn": finished}}
+	}
+
+	tests := []struct {
+		name           string
+		att            oci.Signature
+		integratedTime time.Time
+		wantErr        string
+	}{{
+		name: "recent scan",
+		att:  testAttestation(t, attestation.CosignVulnProvenanceV01, vuln(hourAgo), now),
+	}, {
+		name:    "old scan signed recently",
+		att:     testAttestation(t, attestation.CosignVulnProvenanceV01, vuln(weekAgo), now),
+		wantErr: "attestation is too old",
+	}, {
+		name:    "old integration",
+		att:     testAttestation(t, "https://example.com/custom", map[string]interface{}{}, now.Add(-48*time.Hour)),
+		wantErr: "attestation is too old",
+	}, {
+		name:           "recent integration from the log",
+		att:            testAttestation(t, "https://example.com/custom", map[string]interface{}{}, time.Time{}),
+		integratedTime: now.Add(-time.Minute),
+	}, {
+		name: "recent custom predicate",
+		att:  testAttestation(t, attestation.CosignCustomProvenanceV01, map[string]interface{}{"Data": "", "Timestamp": hourAgo}, time.Time{}),
+	}, {
+		name: "recent SLSA v1 provenance",
+		att: testAttestation(t, attestation.PredicateSLSAProvenanceV1, map[string]interface{}{
+			"runDetails": map[string]interface{}{"metadata": map[string]interface{}{"finishedOn": hourAgo}},
+		}, time.Time{}),
+	}, {
+		name: "old SLSA v0.2 provenance",
+		att: testAttestation(t, "https://slsa.dev/provenance/v0.2", map[string]interface{}{
+			"metadata": map[string]interface{}{"buildFinishedOn": weekAgo},
+		}, time.Time{}),
+		wantErr: "attestation is too old",
+	}, {
+		name: "scan within the clock skew",
+		att:  testAttestation(t, attestation.CosignVulnProvenanceV01, vuln(minuteAhead), time.Time{}),
+	}, {
+		name:    "scan in the future",
+		att:     testAttestation(t, attestation.CosignVulnProvenanceV01, vuln(dayAhead), time.Time{}),
+		wantErr: "attestation is from the future",
+	}, {
+		name:    "no timestamp",
+		att:     testAttestation(t, "https://example.com/custom", map[string]interface{}{}, time.Time{}),
+		wantErr: "unable to tell the age of the attestation",
+	}, {
+		name:    "invalid timestamp",
+		att:     testAttestation(t, attestation.CosignVulnProvenanceV01, vuln("yesterday"), now),
+		wantErr: "invalid timestamp",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckAttestationAge(test.att, test.integratedTime, 24*time.Hour)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckAttestationAge() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("CheckAttestationAge() = %v, wanted error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
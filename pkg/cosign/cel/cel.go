@@ -0,0 +1,116 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+)
+
+// InputVariable is the name of the variable holding the JSON document
+// expressions are evaluated against, e.g. input.predicate.foo == "bar".
+const InputVariable = "input"
+
+// CostLimit bounds the cost of evaluating an expression, in the units of the
+// CEL cost model, so that expressions over large documents, such as nested
+// comprehensions over the items of a predicate, can't run for long in the
+// webhook. It also bounds the cost the expressions are estimated to have.
+const CostLimit = 1000000
+
+// maxSize is the size of the lists, maps and strings of the input the cost of
+// expressions is estimated with, as the input is only known when evaluated.
+// The estimate rejects the expressions whose cost grows too fast with their
+// input, CostLimit stops the evaluation of the others on larger inputs.
+const maxSize = 100
+
+// interruptCheckFrequency is the number of iterations of comprehensions after
+// which the evaluation checks if its context is done.
+const interruptCheckFrequency = 100
+
+// sizeEstimator estimates the cost of expressions with the values of the
+// input holding up to maxSize items.
+type sizeEstimator struct{}
+
+// EstimateSize implements checker.CostEstimator
+func (sizeEstimator) EstimateSize(checker.AstNode) *checker.SizeEstimate {
+	return &checker.SizeEstimate{Min: 0, Max: maxSize}
+}
+
+// EstimateCallCost implements checker.CostEstimator
+func (sizeEstimator) EstimateCallCost(string, string, *checker.AstNode, []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
+
+// Compile parses and type-checks the expression, which must evaluate to a
+// bool, and rejects it when it is estimated to cost more than CostLimit.
+func Compile(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(InputVariable, cel.DynType),
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !cel.BoolType.IsAssignableType(ast.OutputType()) {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+	cost, err := env.EstimateCost(ast, sizeEstimator{})
+	if err != nil {
+		return nil, fmt.Errorf("estimating the cost of the expression: %w", err)
+	}
+	if cost.Max > CostLimit {
+		return nil, fmt.Errorf("expression is too expensive: its estimated cost %d is over the limit %d, for inputs of up to %d items", cost.Max, CostLimit, maxSize)
+	}
+	return env.Program(ast, cel.CostLimit(CostLimit), cel.InterruptCheckFrequency(interruptCheckFrequency))
+}
+
+// ValidateJSON evaluates the expression against the JSON body, and returns an
+// error unless it evaluates to true.
+func ValidateJSON(jsonBody []byte, expression string) error {
+	return ValidateJSONWithContext(context.Background(), jsonBody, expression)
+}
+
+// ValidateJSONWithContext is ValidateJSON, whose evaluation is interrupted
+// once ctx is done.
+func ValidateJSONWithContext(ctx context.Context, jsonBody []byte, expression string) error {
+	prg, err := Compile(expression)
+	if err != nil {
+		return err
+	}
+	var input interface{}
+	if err := json.Unmarshal(jsonBody, &input); err != nil {
+		return err
+	}
+	out, _, err := prg.ContextEval(ctx, map[string]interface{}{InputVariable: input})
+	if err != nil {
+		return fmt.Errorf("evaluating expression: %w", err)
+	}
+	allowed, ok := out.Value().(bool)
+	if !ok {
+		return fmt.Errorf("expression must evaluate to a bool, got %v", out.Value())
+	}
+	if !allowed {
+		return fmt.Errorf("expression %q evaluated to false", expression)
+	}
+	return nil
+}
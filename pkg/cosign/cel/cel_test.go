@@ -0,0 +1,104 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cel
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const simpleJSONBody = `{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://cosign.sigstore.dev/attestation/vuln/v1",
+	"predicate": {"scanner": {"result": {"criticals": 0, "highs": 2}}}
+}`
+
+func TestValidateJSON(t *testing.T) {
+	cases := []struct {
+		name       string
+		expression string
+		err        string
+	}{{
+		name:       "passing expression",
+		expression: `input.predicateType.endsWith("/vuln/v1") && input.predicate.scanner.result.criticals == 0`,
+	}, {
+		name:       "numbers compare with ints",
+		expression: `input.predicate.scanner.result.highs < 3`,
+	}, {
+		name:       "failing expression",
+		expression: `input.predicate.scanner.result.highs == 0`,
+		err:        `expression "input.predicate.scanner.result.highs == 0" evaluated to false`,
+	}, {
+		name:       "missing field",
+		expression: `input.predicate.scanner.result.lows == 0`,
+		err:        "evaluating expression: no such key: lows",
+	}, {
+		name:       "not a bool",
+		expression: `input.predicateType + "!"`,
+		err:        "expression must evaluate to a bool, not string",
+	}, {
+		name:       "syntax error",
+		expression: `input.predicateType ==`,
+		err:        "Syntax error",
+	}, {
+		name:       "undeclared variable",
+		expression: `data.foo == 1`,
+		err:        "undeclared reference to 'data'",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateJSON([]byte(simpleJSONBody), tc.expression)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("ValidateJSON() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("ValidateJSON() = %v, want error containing %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestCostLimit(t *testing.T) {
+	// Three nested comprehensions over the input are rejected upfront.
+	if _, err := Compile(`input.a.all(x, input.a.all(y, input.a.all(z, x + y + z > 0)))`); err == nil || !strings.Contains(err.Error(), "too expensive") {
+		t.Errorf("Compile() = %v, wanted the expression to be too expensive", err)
+	}
+
+	// Two are accepted, but stopped once they cost too much on an input
+	// larger than estimated.
+	items := make([]int, 10*maxSize)
+	for i := range items {
+		items[i] = i + 1
+	}
+	body, err := json.Marshal(map[string]interface{}{"a": items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expression := `input.a.all(x, input.a.all(y, y != 0))`
+	if err := ValidateJSON(body, expression); err == nil || !strings.Contains(err.Error(), "cost limit exceeded") {
+		t.Errorf("ValidateJSON() = %v, wanted the cost limit to be exceeded", err)
+	}
+
+	// And when their context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ValidateJSONWithContext(ctx, body, `input.a.all(x, x > 0)`); err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Errorf("ValidateJSONWithContext() = %v, wanted the evaluation to be interrupted", err)
+	}
+}
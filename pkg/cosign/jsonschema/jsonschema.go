@@ -0,0 +1,79 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Compile parses the JSON schema. Its references to other documents are not
+// resolved: the schemas of the policies are compiled on admission, which
+// must not fetch them over the network nor read them from files.
+func Compile(schema string) (*gojsonschema.Schema, error) {
+	return gojsonschema.NewSchema(localLoader{gojsonschema.NewStringLoader(schema)})
+}
+
+// ValidateJSON validates the JSON body against the schema, and returns an
+// error listing where it does not match it, if it does not.
+func ValidateJSON(jsonBody []byte, schema string) error {
+	s, err := Compile(schema)
+	if err != nil {
+		return err
+	}
+	result, err := s.Validate(gojsonschema.NewBytesLoader(jsonBody))
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+	violations := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		violations = append(violations, e.String())
+	}
+	return fmt.Errorf("does not match the schema: %s", strings.Join(violations, "; "))
+}
+
+// localLoader loads a schema whose references to other documents fail to
+// load.
+type localLoader struct {
+	gojsonschema.JSONLoader
+}
+
+func (localLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return remoteLoaderFactory{}
+}
+
+type remoteLoaderFactory struct{}
+
+func (remoteLoaderFactory) New(source string) gojsonschema.JSONLoader {
+	return remoteLoader{gojsonschema.NewReferenceLoader(source)}
+}
+
+// remoteLoader fails to load the document it references.
+type remoteLoader struct {
+	gojsonschema.JSONLoader
+}
+
+func (l remoteLoader) LoadJSON() (interface{}, error) {
+	return nil, fmt.Errorf("references to other documents are not supported: %v", l.JsonSource())
+}
+
+func (remoteLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return remoteLoaderFactory{}
+}
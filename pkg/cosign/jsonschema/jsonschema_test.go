@@ -0,0 +1,102 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const simpleJSONBody = `{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://cosign.sigstore.dev/attestation/vuln/v1",
+	"predicate": {"scanner": {"result": {"criticals": 0, "highs": 2}}}
+}`
+
+const vulnSchema = `{
+	"type": "object",
+	"required": ["predicateType", "predicate"],
+	"properties": {
+		"predicateType": {"const": "https://cosign.sigstore.dev/attestation/vuln/v1"},
+		"predicate": {
+			"type": "object",
+			"required": ["scanner"],
+			"properties": {
+				"scanner": {
+					"properties": {
+						"result": {
+							"properties": {
+								"criticals": {"maximum": %d},
+								"highs": {"maximum": %d}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestValidateJSON(t *testing.T) {
+	cases := []struct {
+		name   string
+		schema string
+		err    string
+	}{{
+		name:   "matching schema",
+		schema: fmt.Sprintf(vulnSchema, 5, 5),
+	}, {
+		name:   "violations",
+		schema: fmt.Sprintf(vulnSchema, 1, 1),
+		err:    "does not match the schema: predicate.scanner.result.highs: Must be less than or equal to 1",
+	}, {
+		name:   "missing field",
+		schema: `{"required": ["subject"]}`,
+		err:    "does not match the schema: (root): subject is required",
+	}, {
+		name:   "invalid schema",
+		schema: `{"type": "objet"}`,
+		err:    "has a primitive type that is NOT VALID",
+	}, {
+		name:   "remote reference",
+		schema: `{"$ref": "https://example.com/schema.json"}`,
+		err:    "references to other documents are not supported: https://example.com/schema.json",
+	}, {
+		name:   "file reference",
+		schema: `{"properties": {"predicate": {"$ref": "file:///etc/passwd"}}}`,
+		err:    "references to other documents are not supported: file:///etc/passwd",
+	}, {
+		name:   "local reference",
+		schema: `{"definitions": {"typed": {"required": ["_type"]}}, "$ref": "#/definitions/typed"}`,
+	}, {
+		name:   "invalid JSON",
+		schema: `{"type": `,
+		err:    "unexpected EOF",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateJSON([]byte(simpleJSONBody), tc.schema)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("ValidateJSON() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("ValidateJSON() = %v, want error containing %q", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/pkg/apis/config"
+	webhookcip "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook/clusterimagepolicy"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/policy"
+	"knative.dev/pkg/logging"
+)
+
+// For testing
+var cacheNow = time.Now
+
+// Verifying an image against a policy fetches its signatures and
+// attestations, looks them up in Rekor and evaluates the policies, for every
+// pod admitted. The pods of a workload run the same images, so the policies
+// they passed are cached for a while, keyed by the image digest and the
+// generation of the policy. The generation changes along with the policy, or
+// anything it inlines, so that changing a policy invalidates its results.
+// Failures are not cached, so that newly signed images are admitted right
+// away.
+
+// policyResultKey identifies the result of a policy for an image.
+type policyResultKey struct {
+	// image is the reference of the image, by digest.
+	image string
+	// namespace is the one of the admission, which the signature pull
+	// secrets of the policy are read from.
+	namespace  string
+	policy     string
+	generation string
+}
+
+type policyResultEntry struct {
+	key     policyResultKey
+	result  *PolicyResult
+	expires time.Time
+}
+
+// policyResultCache is a LRU cache of the results of the policies which
+// passed, which expire after ttl.
+type policyResultCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	size    int
+	lru     *list.List
+	entries map[policyResultKey]*list.Element
+}
+
+func newPolicyResultCache(ttl time.Duration, size int) *policyResultCache {
+	return &policyResultCache{
+		ttl:     ttl,
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[policyResultKey]*list.Element, size),
+	}
+}
+
+// get returns the result cached for key, if it has not expired.
+func (c *policyResultCache) get(key policyResultKey) (*PolicyResult, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*policyResultEntry)
+	if cacheNow().After(entry.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return entry.result, true
+}
+
+// add caches result for key, evicting the least recently used result when
+// the cache is full.
+func (c *policyResultCache) add(key policyResultKey, result *PolicyResult) {
+	c.Lock()
+	defer c.Unlock()
+	entry := &policyResultEntry{key: key, result: result, expires: cacheNow().Add(c.ttl)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+// retain drops the results of the policies which no longer have the
+// generation they were cached with.
+func (c *policyResultCache) retain(generations map[string]string) {
+	c.Lock()
+	defer c.Unlock()
+	for key, e := range c.entries {
+		if generations[key.policy] != key.generation {
+			c.remove(e)
+		}
+	}
+}
+
+func (c *policyResultCache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*policyResultEntry).key)
+}
+
+// len returns the number of results in the cache.
+func (c *policyResultCache) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.lru.Len()
+}
+
+// ValidatorOption configures a Validator.
+type ValidatorOption func(*Validator)
+
+// WithPolicyResultCache caches the results of the policies which passed for
+// ttl, up to size results. The cache is disabled when either is not positive.
+func WithPolicyResultCache(ttl time.Duration, size int) ValidatorOption {
+	return func(v *Validator) {
+		if ttl <= 0 || size <= 0 {
+			v.cache = nil
+			return
+		}
+		v.cache = newPolicyResultCache(ttl, size)
+	}
+}
+
+// policyGeneration identifies the content of cip, with what it inlines.
+func policyGeneration(cip webhookcip.ClusterImagePolicy) (string, error) {
+	b, err := json.Marshal(cip)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheable returns whether the result of cip only depends on the image, and
+// not on the admission: its policies don't include the admission context, nor
+// require recent attestations.
+func cacheable(cip webhookcip.ClusterImagePolicy) bool {
+	if cip.Policy != nil && cip.Policy.IncludeContext {
+		return false
+	}
+	for _, authority := range cip.Authorities {
+		for _, attestation := range authority.Attestations {
+			if attestation.MaxAge != nil || attestation.IncludeContext {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validatePolicies validates ref against policies like validatePolicies, but
+// only the policies which it did not recently pass.
+func (v *Validator) validatePolicies(ctx context.Context, namespace string, ref name.Reference, policies map[string]webhookcip.ClusterImagePolicy, remoteOpts ...ociremote.Option) (map[string]*PolicyResult, map[string][]error) {
+	if v.explain != "" {
+		// The failures are only explained once they happen, so this
+		// costs nothing to the images which are admitted.
+		ctx = policy.WithExplain(ctx, v.explain)
+	}
+	if v.cache == nil {
+		return validatePolicies(ctx, namespace, ref, policies, remoteOpts...)
+	}
+
+	cached := make(map[string]*PolicyResult, len(policies))
+	uncached := make(map[string]webhookcip.ClusterImagePolicy, len(policies))
+	keys := make(map[string]policyResultKey, len(policies))
+	for cipName, cip := range policies {
+		if !cacheable(cip) {
+			uncached[cipName] = cip
+			continue
+		}
+		generation, err := policyGeneration(cip)
+		if err != nil {
+			logging.FromContext(ctx).Warnf("Not caching the result of policy %s: %v", cipName, err)
+			uncached[cipName] = cip
+			continue
+		}
+		key := policyResultKey{image: ref.Name(), namespace: namespace, policy: cipName, generation: generation}
+		if result, ok := v.cache.get(key); ok {
+			logging.FromContext(ctx).Debugf("Using the cached result of policy %s for %s", cipName, ref.Name())
+			cached[cipName] = result
+			continue
+		}
+		keys[cipName] = key
+		uncached[cipName] = cip
+	}
+	if len(uncached) == 0 {
+		return cached, map[string][]error{}
+	}
+
+	results, errs := validatePolicies(ctx, namespace, ref, uncached, remoteOpts...)
+	for cipName, result := range results {
+		if key, ok := keys[cipName]; ok {
+			v.cache.add(key, result)
+		}
+	}
+	for cipName, result := range cached {
+		results[cipName] = result
+	}
+	return results, errs
+}
+
+// PoliciesChanged drops the cached results of the policies which changed or
+// were removed. It is called by the config store when the policies change.
+func (v *Validator) PoliciesChanged(name string, value interface{}) {
+	if v.cache == nil || name != config.ImagePoliciesConfigName {
+		return
+	}
+	generations := map[string]string{}
+	if ipc, ok := value.(*config.ImagePolicyConfig); ok && ipc != nil {
+		for cipName, cip := range ipc.Policies {
+			if generation, err := policyGeneration(cip); err == nil {
+				generations[cipName] = generation
+			}
+		}
+	}
+	v.cache.retain(generations)
+}
@@ -0,0 +1,166 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/pkg/apis/config"
+	webhookcip "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook/clusterimagepolicy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyResultCache(t *testing.T) {
+	now := time.Now()
+	cacheNow = func() time.Time { return now }
+	t.Cleanup(func() { cacheNow = time.Now })
+
+	c := newPolicyResultCache(time.Minute, 2)
+	key := func(image string) policyResultKey {
+		return policyResultKey{image: image, namespace: "default", policy: "cip", generation: "1"}
+	}
+	result := &PolicyResult{AuthorityMatches: map[string]AuthorityMatch{"authority-0": {}}}
+
+	c.add(key("a"), result)
+	if got, ok := c.get(key("a")); !ok || got != result {
+		t.Errorf("get(a) = %v, %v, wanted the cached result", got, ok)
+	}
+	if _, ok := c.get(policyResultKey{image: "a", namespace: "other", policy: "cip", generation: "1"}); ok {
+		t.Error("get(a) in another namespace was cached")
+	}
+
+	// The least recently used result is evicted.
+	c.add(key("b"), result)
+	c.get(key("a"))
+	c.add(key("c"), result)
+	if _, ok := c.get(key("b")); ok {
+		t.Error("get(b) was not evicted")
+	}
+	if got := c.len(); got != 2 {
+		t.Errorf("len() = %d, wanted 2", got)
+	}
+
+	// Results expire after the TTL.
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get(key("a")); ok {
+		t.Error("get(a) did not expire")
+	}
+
+	// Results of policies of another generation are dropped.
+	c.add(key("d"), result)
+	c.retain(map[string]string{"cip": "2"})
+	if got := c.len(); got != 0 {
+		t.Errorf("len() = %d after retain, wanted 0", got)
+	}
+}
+
+func TestCacheable(t *testing.T) {
+	tests := []struct {
+		name string
+		cip  webhookcip.ClusterImagePolicy
+		want bool
+	}{{
+		name: "signatures only",
+		cip:  webhookcip.ClusterImagePolicy{Authorities: []webhookcip.Authority{{Name: "authority-0"}}},
+		want: true,
+	}, {
+		name: "policy on the image",
+		cip: webhookcip.ClusterImagePolicy{
+			Policy: &webhookcip.AttestationPolicy{Type: "cue", Data: `authorityMatches: "authority-0": signatures: [...]`},
+		},
+		want: true,
+	}, {
+		name: "policy mentioning a context it doesn't include",
+		cip: webhookcip.ClusterImagePolicy{
+			Policy: &webhookcip.AttestationPolicy{Type: "cue", Data: `predicate: context: "ci"`},
+		},
+		want: true,
+	}, {
+		name: "policy including the admission context",
+		cip: webhookcip.ClusterImagePolicy{
+			Policy: &webhookcip.AttestationPolicy{Type: "cel", Data: `input.context.namespace != "prod"`, IncludeContext: true},
+		},
+	}, {
+		name: "attestation policy including the admission context",
+		cip: webhookcip.ClusterImagePolicy{Authorities: []webhookcip.Authority{{
+			Attestations: []webhookcip.AttestationPolicy{{Type: "rego", Data: `isCompliant { input.context.signer.subject == "ci" }`, IncludeContext: true}},
+		}}},
+	}, {
+		name: "recent attestations",
+		cip: webhookcip.ClusterImagePolicy{Authorities: []webhookcip.Authority{{
+			Attestations: []webhookcip.AttestationPolicy{{Name: "vuln", MaxAge: &metav1.Duration{Duration: time.Hour}}},
+		}}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := cacheable(test.cip); got != test.want {
+				t.Errorf("cacheable() = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidatePoliciesCached(t *testing.T) {
+	ref := name.MustParseReference("gcr.io/distroless/static:nonroot@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4")
+	// This policy fails without fetching anything, unless its result
+	// is cached.
+	cip := webhookcip.ClusterImagePolicy{
+		Authorities: []webhookcip.Authority{{
+			Name: "authority-0",
+			Key:  &webhookcip.KeyRef{},
+		}},
+	}
+	generation, err := policyGeneration(cip)
+	if err != nil {
+		t.Fatalf("policyGeneration() = %v", err)
+	}
+	v := &Validator{cache: newPolicyResultCache(time.Minute, 10)}
+	result := &PolicyResult{AuthorityMatches: map[string]AuthorityMatch{"authority-0": {}}}
+	v.cache.add(policyResultKey{image: ref.Name(), namespace: "default", policy: "cip", generation: generation}, result)
+
+	got, errs := v.validatePolicies(context.Background(), "default", ref, map[string]webhookcip.ClusterImagePolicy{"cip": cip})
+	if len(errs) != 0 || got["cip"] != result {
+		t.Errorf("validatePolicies() = %v, %v, wanted the cached result", got, errs)
+	}
+
+	// The result is not used for the images of other namespaces.
+	if _, errs := v.validatePolicies(context.Background(), "other", ref, map[string]webhookcip.ClusterImagePolicy{"cip": cip}); len(errs["cip"]) == 0 {
+		t.Error("validatePolicies() in another namespace used the cached result")
+	}
+
+	// Nor once the policy changed.
+	changed := cip
+	changed.Mode = "warn"
+	if _, errs := v.validatePolicies(context.Background(), "default", ref, map[string]webhookcip.ClusterImagePolicy{"cip": changed}); len(errs["cip"]) == 0 {
+		t.Error("validatePolicies() of a changed policy used the cached result")
+	}
+
+	// Changing the policies drops the results of the changed ones.
+	v.PoliciesChanged(config.ImagePoliciesConfigName, &config.ImagePolicyConfig{
+		Policies: map[string]webhookcip.ClusterImagePolicy{"cip": cip},
+	})
+	if got := v.cache.len(); got != 1 {
+		t.Errorf("len() = %d after an unchanged policy, wanted 1", got)
+	}
+	v.PoliciesChanged(config.ImagePoliciesConfigName, &config.ImagePolicyConfig{
+		Policies: map[string]webhookcip.ClusterImagePolicy{"cip": changed},
+	})
+	if got := v.cache.len(); got != 0 {
+		t.Errorf("len() = %d after a changed policy, wanted 0", got)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/logging"
@@ -44,6 +45,13 @@ type ClusterImagePolicy struct {
 	// Authorities. Will not get evaluated unless at least one Authority
 	// succeeds.
 	Policy *AttestationPolicy `json:"policy,omitempty"`
+	// Mode is how images failing this policy are handled: enforce (the
+	// default), warn or audit.
+	Mode string `json:"mode,omitempty"`
+	// Namespace is the namespace of the ImagePolicy this policy comes from,
+	// which only applies to the pods of its namespace. It is empty for
+	// ClusterImagePolicies.
+	Namespace string `json:"namespace,omitempty"`
 }
 
 type Authority struct {
@@ -98,6 +106,12 @@ type AttestationPolicy struct {
 	// Data is the inlined version of the Policy used to evaluate the
 	// Attestation.
 	Data string `json:"data,omitempty"`
+	// Query is the Rego query deciding whether the policy is satisfied.
+	Query string `json:"query,omitempty"`
+	// IncludeContext adds the admission context to the input of the policy.
+	IncludeContext bool `json:"includeContext,omitempty"`
+	// MaxAge rejects the attestations produced longer ago.
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
 }
 
 // UnmarshalJSON populates the PublicKeys using Data because
@@ -187,7 +201,7 @@ func ConvertClusterImagePolicyV1alpha1ToWebhook(in *v1alpha1.ClusterImagePolicy)
 
 	outAuthorities := make([]Authority, 0)
 	for _, authority := range copyIn.Spec.Authorities {
-		outAuthority := convertAuthorityV1Alpha1ToWebhook(authority)
+		outAuthority := ConvertAuthorityV1Alpha1ToWebhook(authority)
 		outAuthorities = append(outAuthorities, *outAuthority)
 	}
 
@@ -195,18 +209,31 @@ func ConvertClusterImagePolicyV1alpha1ToWebhook(in *v1alpha1.ClusterImagePolicy)
 	var cipAttestationPolicy *AttestationPolicy
 	if in.Spec.Policy != nil {
 		cipAttestationPolicy = &AttestationPolicy{
-			Type: in.Spec.Policy.Type,
-			Data: in.Spec.Policy.Data,
+			Type:           in.Spec.Policy.Type,
+			Data:           in.Spec.Policy.Data,
+			Query:          in.Spec.Policy.Query,
+			IncludeContext: in.Spec.Policy.IncludeContext,
 		}
 	}
 	return &ClusterImagePolicy{
 		Images:      copyIn.Spec.Images,
 		Authorities: outAuthorities,
 		Policy:      cipAttestationPolicy,
+		Mode:        copyIn.Spec.Mode,
 	}
 }
 
-func convertAuthorityV1Alpha1ToWebhook(in v1alpha1.Authority) *Authority {
+// ConvertImagePolicyV1alpha1ToWebhook will convert the v1alpha1 ImagePolicy
+// into a ClusterImagePolicy that only applies to its namespace.
+func ConvertImagePolicyV1alpha1ToWebhook(in *v1alpha1.ImagePolicy) *ClusterImagePolicy {
+	ret := ConvertClusterImagePolicyV1alpha1ToWebhook(&v1alpha1.ClusterImagePolicy{Spec: in.Spec})
+	ret.Namespace = in.Namespace
+	return ret
+}
+
+// ConvertAuthorityV1Alpha1ToWebhook converts the authority without parsing
+// its public keys, which is done when unmarshaling it.
+func ConvertAuthorityV1Alpha1ToWebhook(in v1alpha1.Authority) *Authority {
 	keyRef := convertKeyRefV1Alpha1ToWebhook(in.Key)
 	keylessRef := convertKeylessRefV1Alpha1ToWebhook(in.Keyless)
 	attestations := convertAttestationsV1Alpha1ToWebhook(in.Attestations)
@@ -227,10 +254,13 @@ func convertAttestationsV1Alpha1ToWebhook(in []v1alpha1.Attestation) []Attestati
 		outAtt := AttestationPolicy{
 			Name:          inAtt.Name,
 			PredicateType: inAtt.PredicateType,
+			MaxAge:        inAtt.MaxAge.DeepCopy(),
 		}
 		if inAtt.Policy != nil {
 			outAtt.Type = inAtt.Policy.Type
 			outAtt.Data = inAtt.Policy.Data
+			outAtt.Query = inAtt.Policy.Query
+			outAtt.IncludeContext = inAtt.Policy.IncludeContext
 		}
 		ret = append(ret, outAtt)
 	}
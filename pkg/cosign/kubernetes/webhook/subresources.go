@@ -0,0 +1,159 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	mwhinformer "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1/mutatingwebhookconfiguration"
+	vwhinformer "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1/validatingwebhookconfiguration"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/webhook"
+)
+
+// The admission controllers of knative generate the rules of their webhook
+// from the kinds they handle, which only covers their main resource and its
+// status. Subresources such as pods/ephemeralcontainers, whose requests
+// carry the whole object and are admitted like updates of it, are handled by
+// the other webhooks of the same configuration, which declare their own rules
+// and get their CA bundle and path from the webhook managed by knative.
+
+// admissionReconciler is what knative expects of the reconcilers of admission
+// controllers.
+type admissionReconciler interface {
+	controller.Reconciler
+	pkgreconciler.LeaderAware
+	webhook.AdmissionController
+}
+
+type subresourceWebhooks struct {
+	admissionReconciler
+	// Like the knative admission controllers, Admit doesn't depend on
+	// informers.
+	webhook.StatelessAdmissionImpl
+
+	sync func(ctx context.Context) error
+}
+
+// Reconcile implements controller.Reconciler
+func (s *subresourceWebhooks) Reconcile(ctx context.Context, key string) error {
+	if err := s.admissionReconciler.Reconcile(ctx, key); err != nil {
+		return err
+	}
+	return s.sync(ctx)
+}
+
+// WithValidatingSubresourceWebhooks makes the validating admission controller
+// of impl, whose webhook configuration is named name, keep the CA bundle and
+// the path of the other webhooks of the configuration in sync with its own.
+func WithValidatingSubresourceWebhooks(ctx context.Context, impl *controller.Impl, name string) *controller.Impl {
+	client := kubeclient.Get(ctx)
+	lister := vwhinformer.Get(ctx).Lister()
+	return withSubresourceWebhooks(impl, func(ctx context.Context) error {
+		configured, err := lister.Get(name)
+		if err != nil {
+			return fmt.Errorf("error retrieving webhook: %w", err)
+		}
+		current := configured.DeepCopy()
+		names := make([]string, 0, len(current.Webhooks))
+		clientConfigs := make([]*admissionregistrationv1.WebhookClientConfig, 0, len(current.Webhooks))
+		for i := range current.Webhooks {
+			names = append(names, current.Webhooks[i].Name)
+			clientConfigs = append(clientConfigs, &current.Webhooks[i].ClientConfig)
+		}
+		if !syncClientConfigs(name, names, clientConfigs) {
+			return nil
+		}
+		logging.FromContext(ctx).Info("Updating subresource webhooks")
+		_, err = client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Update(ctx, current, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// WithMutatingSubresourceWebhooks is WithValidatingSubresourceWebhooks for
+// mutating admission controllers.
+func WithMutatingSubresourceWebhooks(ctx context.Context, impl *controller.Impl, name string) *controller.Impl {
+	client := kubeclient.Get(ctx)
+	lister := mwhinformer.Get(ctx).Lister()
+	return withSubresourceWebhooks(impl, func(ctx context.Context) error {
+		configured, err := lister.Get(name)
+		if err != nil {
+			return fmt.Errorf("error retrieving webhook: %w", err)
+		}
+		current := configured.DeepCopy()
+		names := make([]string, 0, len(current.Webhooks))
+		clientConfigs := make([]*admissionregistrationv1.WebhookClientConfig, 0, len(current.Webhooks))
+		for i := range current.Webhooks {
+			names = append(names, current.Webhooks[i].Name)
+			clientConfigs = append(clientConfigs, &current.Webhooks[i].ClientConfig)
+		}
+		if !syncClientConfigs(name, names, clientConfigs) {
+			return nil
+		}
+		logging.FromContext(ctx).Info("Updating subresource webhooks")
+		_, err = client.AdmissionregistrationV1().MutatingWebhookConfigurations().Update(ctx, current, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// syncClientConfigs copies the CA bundle and the path of the client config of
+// the webhook named after its configuration, which knative manages, to the
+// client configs of the other webhooks of the configuration, and returns
+// whether any of them changed.
+func syncClientConfigs(name string, names []string, clientConfigs []*admissionregistrationv1.WebhookClientConfig) bool {
+	var managed *admissionregistrationv1.WebhookClientConfig
+	for i, n := range names {
+		if n == name {
+			managed = clientConfigs[i]
+		}
+	}
+	if managed == nil || managed.Service == nil || managed.Service.Path == nil {
+		// Not reconciled by knative yet.
+		return false
+	}
+	changed := false
+	for i, cc := range clientConfigs {
+		if names[i] == name || cc.Service == nil {
+			continue
+		}
+		if !bytes.Equal(cc.CABundle, managed.CABundle) {
+			cc.CABundle = managed.CABundle
+			changed = true
+		}
+		if cc.Service.Path == nil || *cc.Service.Path != *managed.Service.Path {
+			path := *managed.Service.Path
+			cc.Service.Path = &path
+			changed = true
+		}
+	}
+	return changed
+}
+
+func withSubresourceWebhooks(impl *controller.Impl, sync func(ctx context.Context) error) *controller.Impl {
+	r, ok := impl.Reconciler.(admissionReconciler)
+	if !ok {
+		// Only the knative admission controllers are wrapped.
+		panic(fmt.Sprintf("%T is not an admission controller", impl.Reconciler))
+	}
+	impl.Reconciler = &subresourceWebhooks{admissionReconciler: r, sync: sync}
+	return impl
+}
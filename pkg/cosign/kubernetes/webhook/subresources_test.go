@@ -0,0 +1,133 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "knative.dev/pkg/client/injection/kube/client/fake"
+	fakevwhinformer "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1/validatingwebhookconfiguration/fake"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/ptr"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	rtesting "knative.dev/pkg/reconciler/testing"
+	"knative.dev/pkg/webhook"
+)
+
+type fakeAdmissionReconciler struct {
+	pkgreconciler.LeaderAwareFuncs
+	reconciled bool
+	admit      func(context.Context)
+}
+
+func (r *fakeAdmissionReconciler) Reconcile(context.Context, string) error {
+	r.reconciled = true
+	return nil
+}
+
+func (r *fakeAdmissionReconciler) Path() string {
+	return "/validations"
+}
+
+func (r *fakeAdmissionReconciler) Admit(ctx context.Context, _ *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if r.admit != nil {
+		r.admit(ctx)
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func TestWithValidatingSubresourceWebhooks(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	const webhookName = "policy.sigstore.dev"
+	service := func(path *string) *admissionregistrationv1.ServiceReference {
+		return &admissionregistrationv1.ServiceReference{Name: "webhook", Namespace: "cosign-system", Path: path}
+	}
+	vwh := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: webhookName},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			// Reconciled by knative.
+			Name: webhookName,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				CABundle: []byte("ca"),
+				Service:  service(ptr.String("/validations")),
+			},
+		}, {
+			Name:         "ephemeralcontainers.policy.sigstore.dev",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: service(nil)},
+		}, {
+			Name:         "url.policy.sigstore.dev",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{URL: ptr.String("https://example.com")},
+		}},
+	}
+	fakevwhinformer.Get(ctx).Informer().GetIndexer().Add(vwh)
+	client := fakekube.Get(ctx)
+	if _, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(ctx, vwh, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := &fakeAdmissionReconciler{}
+	impl := WithValidatingSubresourceWebhooks(ctx, &controller.Impl{Reconciler: inner}, webhookName)
+	if _, ok := impl.Reconciler.(webhook.AdmissionController); !ok {
+		t.Fatal("the wrapped reconciler is not an admission controller")
+	}
+	if _, ok := impl.Reconciler.(webhook.StatelessAdmissionController); !ok {
+		t.Error("the wrapped reconciler is not stateless")
+	}
+	if _, ok := impl.Reconciler.(pkgreconciler.LeaderAware); !ok {
+		t.Error("the wrapped reconciler is not leader aware")
+	}
+	if err := impl.Reconciler.Reconcile(ctx, webhookName); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if !inner.reconciled {
+		t.Error("the knative admission controller was not reconciled")
+	}
+
+	got, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, webhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ephemeral := got.Webhooks[1].ClientConfig
+	if string(ephemeral.CABundle) != "ca" || ephemeral.Service.Path == nil || *ephemeral.Service.Path != "/validations" {
+		t.Errorf("subresource webhook was not synced: %+v", ephemeral)
+	}
+	if url := got.Webhooks[2].ClientConfig; url.CABundle != nil {
+		t.Errorf("webhook without service was synced: %+v", url)
+	}
+}
+
+func TestSyncClientConfigs(t *testing.T) {
+	managed := &admissionregistrationv1.WebhookClientConfig{
+		CABundle: []byte("ca"),
+		Service:  &admissionregistrationv1.ServiceReference{Path: ptr.String("/mutations")},
+	}
+	synced := &admissionregistrationv1.WebhookClientConfig{
+		CABundle: []byte("ca"),
+		Service:  &admissionregistrationv1.ServiceReference{Path: ptr.String("/mutations")},
+	}
+	if syncClientConfigs("a", []string{"a", "b"}, []*admissionregistrationv1.WebhookClientConfig{managed, synced}) {
+		t.Error("syncClientConfigs() changed client configs which were in sync")
+	}
+	// Before knative reconciles its webhook, there is nothing to sync.
+	unreconciled := &admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{}}
+	other := &admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{}}
+	if syncClientConfigs("a", []string{"a", "b"}, []*admissionregistrationv1.WebhookClientConfig{unreconciled, other}) {
+		t.Error("syncClientConfigs() synced an unreconciled webhook")
+	}
+}
@@ -22,8 +22,10 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/logging"
 
@@ -103,19 +105,40 @@ func validSignaturesWithFulcio(ctx context.Context, ref name.Reference, fulcioRo
 	return sigs, err
 }
 
-func validAttestations(ctx context.Context, ref name.Reference, verifier signature.Verifier, rekorClient *client.Rekor, opts ...ociremote.Option) ([]oci.Signature, error) {
+// integratedTimes are the times the attestations verified online against
+// Rekor were integrated in it, by digest of the attestation.
+type integratedTimes map[v1.Hash]time.Time
+
+func (it integratedTimes) add(att oci.Signature, integratedTime time.Time) {
+	if digest, err := att.Digest(); err == nil {
+		it[digest] = integratedTime
+	}
+}
+
+// of returns the time att was integrated in Rekor, or the zero time when it
+// was not verified online against it.
+func (it integratedTimes) of(att oci.Signature) time.Time {
+	digest, err := att.Digest()
+	if err != nil {
+		return time.Time{}
+	}
+	return it[digest]
+}
+
+func validAttestations(ctx context.Context, ref name.Reference, verifier signature.Verifier, rekorClient *client.Rekor, times integratedTimes, opts ...ociremote.Option) ([]oci.Signature, error) {
 	attestations, _, err := cosignVerifyAttestations(ctx, ref, &cosign.CheckOpts{
 		RegistryClientOpts: opts,
 		SigVerifier:        verifier,
 		RekorClient:        rekorClient,
 		ClaimVerifier:      cosign.IntotoSubjectClaimVerifier,
+		OnTlogEntry:        times.add,
 	})
 	return attestations, err
 }
 
 // validAttestationsWithFulcio expects a Fulcio Cert to verify against. An
 // optional rekorClient can also be given, if nil passed, default is assumed.
-func validAttestationsWithFulcio(ctx context.Context, ref name.Reference, fulcioRoots *x509.CertPool, rekorClient *client.Rekor, identities []v1alpha1.Identity, opts ...ociremote.Option) ([]oci.Signature, error) {
+func validAttestationsWithFulcio(ctx context.Context, ref name.Reference, fulcioRoots *x509.CertPool, rekorClient *client.Rekor, identities []v1alpha1.Identity, times integratedTimes, opts ...ociremote.Option) ([]oci.Signature, error) {
 	ids := make([]cosign.Identity, len(identities))
 	for i, id := range identities {
 		ids[i] = cosign.Identity{Issuer: id.Issuer, Subject: id.Subject}
@@ -127,6 +150,7 @@ func validAttestationsWithFulcio(ctx context.Context, ref name.Reference, fulcio
 		RekorClient:        rekorClient,
 		ClaimVerifier:      cosign.IntotoSubjectClaimVerifier,
 		Identities:         ids,
+		OnTlogEntry:        times.add,
 	})
 	return attestations, err
 }
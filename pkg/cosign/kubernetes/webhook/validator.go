@@ -22,11 +22,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn/k8schain"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/sigstore/cosign/pkg/apis/config"
+	"github.com/sigstore/cosign/pkg/apis/utils"
+	"github.com/sigstore/cosign/pkg/cosign"
 	webhookcip "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook/clusterimagepolicy"
 	"github.com/sigstore/cosign/pkg/oci"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
@@ -50,14 +54,53 @@ type Validator struct {
 	client     kubernetes.Interface
 	lister     listersv1.SecretLister
 	secretName string
+	mirrors    ociremote.MirrorMapping
+	// cache holds the results of the policies which passed, when enabled.
+	cache *policyResultCache
+	// explain is the rego.ExplainModes the failures of the policies are
+	// explained with in the details of the denials, none when empty.
+	explain string
 }
 
-func NewValidator(ctx context.Context, secretName string) *Validator {
-	return &Validator{
+func NewValidator(ctx context.Context, secretName string, opts ...ValidatorOption) *Validator {
+	// Images pulled through a mirror have their signatures looked up
+	// according to $COSIGN_MIRROR_MAPPING.
+	mirrors, err := ociremote.GetEnvMirrorMapping()
+	if err != nil {
+		logging.FromContext(ctx).Errorf("Ignoring the mirror mapping: %v", err)
+	}
+	v := &Validator{
 		client:     kubeclient.Get(ctx),
 		lister:     secretinformer.Get(ctx).Lister(),
 		secretName: secretName,
+		mirrors:    mirrors,
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// WithExplain explains why the policies fail in the details of the denials,
+// in one of the rego.ExplainModes. Rego policies are evaluated again with a
+// tracer to explain their failures, so this is off by default.
+func WithExplain(mode string) ValidatorOption {
+	return func(v *Validator) {
+		v.explain = mode
+	}
+}
+
+// maxExplanationSize bounds the explanation of a failed policy in the details
+// of a denial, as the trace of a rego policy can be as large as its input.
+const maxExplanationSize = 4 << 10
+
+// truncateExplanation cuts explanation to maxExplanationSize.
+func truncateExplanation(explanation string) string {
+	explanation = strings.TrimSpace(explanation)
+	if len(explanation) <= maxExplanationSize {
+		return explanation
+	}
+	return explanation[:maxExplanationSize] + "\n... (truncated)"
 }
 
 // ValidatePodSpecable implements duckv1.PodSpecValidator
@@ -76,6 +119,11 @@ func (v *Validator) ValidatePodSpecable(ctx context.Context, wp *duckv1.WithPod)
 		ServiceAccountName: wp.Spec.Template.Spec.ServiceAccountName,
 		ImagePullSecrets:   imagePullSecrets,
 	}
+	ctx = withAdmissionContext(ctx, wp.Namespace, wp.Spec.Template.Labels, &policy.WorkloadContext{
+		Kind:   wp.Kind,
+		Name:   wp.Name,
+		Labels: wp.Labels,
+	})
 	return v.validatePodSpec(ctx, wp.Namespace, &wp.Spec.Template.Spec, opt).ViaField("spec.template.spec")
 }
 
@@ -94,6 +142,11 @@ func (v *Validator) ValidatePod(ctx context.Context, p *duckv1.Pod) *apis.FieldE
 		ServiceAccountName: p.Spec.ServiceAccountName,
 		ImagePullSecrets:   imagePullSecrets,
 	}
+	ctx = withAdmissionContext(ctx, p.Namespace, p.Labels, &policy.WorkloadContext{
+		Kind:   p.Kind,
+		Name:   p.Name,
+		Labels: p.Labels,
+	})
 	return v.validatePodSpec(ctx, p.Namespace, &p.Spec, opt).ViaField("spec")
 }
 
@@ -112,9 +165,34 @@ func (v *Validator) ValidateCronJob(ctx context.Context, c *duckv1.CronJob) *api
 		ServiceAccountName: c.Spec.JobTemplate.Spec.Template.Spec.ServiceAccountName,
 		ImagePullSecrets:   imagePullSecrets,
 	}
+	ctx = withAdmissionContext(ctx, c.Namespace, c.Spec.JobTemplate.Spec.Template.Labels, &policy.WorkloadContext{
+		Kind:   c.Kind,
+		Name:   c.Name,
+		Labels: c.Labels,
+	})
 	return v.validatePodSpec(ctx, c.Namespace, &c.Spec.JobTemplate.Spec.Template.Spec, opt).ViaField("spec.jobTemplate.spec.template.spec")
 }
 
+// withAdmissionContext attaches what is being admitted to the context, for
+// the policies to be evaluated against it along with the attestations.
+func withAdmissionContext(ctx context.Context, namespace string, labels map[string]string, workload *policy.WorkloadContext) context.Context {
+	now := time.Now().UTC()
+	return policy.WithInputContext(ctx, policy.InputContext{
+		Namespace:     namespace,
+		Labels:        labels,
+		Workload:      workload,
+		AdmissionTime: &now,
+	})
+}
+
+// policyInputContext returns the InputContext of the admission with the image
+// being validated.
+func policyInputContext(ctx context.Context, ref name.Reference) policy.InputContext {
+	ic := policy.InputContextFromContext(ctx)
+	ic.Image = policy.NewImageContext(ref)
+	return ic
+}
+
 func (v *Validator) validatePodSpec(ctx context.Context, namespace string, ps *corev1.PodSpec, opt k8schain.Options) (errs *apis.FieldError) {
 	kc, err := k8schain.New(ctx, v.client, opt)
 	if err != nil {
@@ -132,6 +210,15 @@ func (v *Validator) validatePodSpec(ctx context.Context, namespace string, ps *c
 		return kerr
 	}
 
+	// The containers of a pod often run the same image, such as the init
+	// container of a sidecar, which is only verified once.
+	type policiesResult struct {
+		signatures  map[string]*PolicyResult
+		fieldErrors map[string][]error
+	}
+	verifiedPolicies := map[string]policiesResult{}
+	verifiedKeys := map[string]error{}
+
 	checkContainers := func(cs []corev1.Container, field string) {
 		for i, c := range cs {
 			ref, err := name.ParseReference(c.Image)
@@ -160,7 +247,7 @@ func (v *Validator) validatePodSpec(ctx context.Context, namespace string, ps *c
 			// necessarily going to play nicely together.
 			passedPolicyChecks := false
 			if config != nil {
-				policies, err := config.ImagePolicyConfig.GetMatchingPolicies(ref.Name())
+				policies, err := config.ImagePolicyConfig.GetMatchingPolicies(ref.Name(), namespace)
 				if err != nil {
 					errorField := apis.ErrGeneric(err.Error(), "image").ViaFieldIndex(field, i)
 					errorField.Details = c.Image
@@ -171,36 +258,67 @@ func (v *Validator) validatePodSpec(ctx context.Context, namespace string, ps *c
 				// If there is at least one policy that matches, that means it
 				// has to be satisfied.
 				if len(policies) > 0 {
-					signatures, fieldErrors := validatePolicies(ctx, namespace, ref, policies, ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(kc)))
+					pr, ok := verifiedPolicies[ref.Name()]
+					if !ok {
+						pr.signatures, pr.fieldErrors = v.validatePolicies(ctx, namespace, ref, policies, ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(kc)), ociremote.WithMirrorMapping(v.mirrors))
+						verifiedPolicies[ref.Name()] = pr
+					}
+					signatures, fieldErrors := pr.signatures, pr.fieldErrors
 
 					if len(signatures) != len(policies) {
 						logging.FromContext(ctx).Warnf("Failed to validate at least one policy for %s", ref.Name())
+						enforced := false
 						// Do we really want to add all the error details here?
 						// Seems like we can just say which policy failed, so
 						// doing that for now.
 						for failingPolicy, policyErrs := range fieldErrors {
+							// Policies which are not enforced only report
+							// their failures, internal errors are always
+							// enforced. So are ImagePolicies, which only
+							// add requirements.
+							switch failed := policies[failingPolicy]; {
+							case failed.Namespace != "":
+							case failed.Mode == utils.ModeWarn:
+								warnAdmission(ctx, policyFailure(failingPolicy, c.Image, policyErrs))
+								continue
+							case failed.Mode == utils.ModeAudit:
+								auditAdmission(ctx, policyFailure(failingPolicy, c.Image, policyErrs))
+								continue
+							}
+							enforced = true
 							errorField := apis.ErrGeneric(fmt.Sprintf("failed policy: %s", failingPolicy), "image").ViaFieldIndex(field, i)
 							errDetails := c.Image
 							for _, policyErr := range policyErrs {
 								errDetails = errDetails + " " + policyErr.Error()
+								if explanation := policy.Explanation(policyErr); explanation != "" {
+									errDetails = errDetails + "\n" + truncateExplanation(explanation)
+								}
 							}
 							errorField.Details = errDetails
 							errs = errs.Also(errorField)
 						}
-						// Because there was at least one policy that was
-						// supposed to be validated, but it failed, then fail
-						// this image. It should not fall through to the
-						// traditional secret checking so it does not slip
-						// through the policy cracks, and also to reduce noise
-						// in the errors returned to the user.
-						continue
+						if !enforced {
+							// Only policies in warn or audit mode failed,
+							// which admit the image like passing ones.
+							passedPolicyChecks = matchesClusterPolicy(policies)
+						} else {
+							// Because there was at least one policy that was
+							// supposed to be validated, but it failed, then fail
+							// this image. It should not fall through to the
+							// traditional secret checking so it does not slip
+							// through the policy cracks, and also to reduce noise
+							// in the errors returned to the user.
+							continue
+						}
 					} else {
 						logging.FromContext(ctx).Warnf("Validated authorities for %s", ref.Name())
 						// Only say we passed (aka, we skip the traditidional check
 						// below) if more than one authority was validated, which
 						// means that there was a matching ClusterImagePolicy.
+						// ImagePolicies alone don't skip it, they only add
+						// requirements to the ones of the cluster.
 						if len(signatures) > 0 {
-							passedPolicyChecks = true
+							passedPolicyChecks = matchesClusterPolicy(policies)
 						}
 					}
 				}
@@ -214,7 +332,12 @@ func (v *Validator) validatePodSpec(ctx context.Context, namespace string, ps *c
 			logging.FromContext(ctx).Errorf("ref: for %v", ref)
 			logging.FromContext(ctx).Errorf("container Keys: for %v", containerKeys)
 
-			if _, err := valid(ctx, ref, nil, containerKeys, ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(kc))); err != nil {
+			err, ok := verifiedKeys[ref.Name()]
+			if !ok {
+				_, err = valid(ctx, ref, nil, containerKeys, ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(kc)), ociremote.WithMirrorMapping(v.mirrors))
+				verifiedKeys[ref.Name()] = err
+			}
+			if err != nil {
 				errorField := apis.ErrGeneric(err.Error(), "image").ViaFieldIndex(field, i)
 				errorField.Details = c.Image
 				errs = errs.Also(errorField)
@@ -225,16 +348,32 @@ func (v *Validator) validatePodSpec(ctx context.Context, namespace string, ps *c
 
 	checkContainers(ps.InitContainers, "initContainers")
 	checkContainers(ps.Containers, "containers")
+	// Ephemeral containers, such as the ones of kubectl debug, are added
+	// to running pods through the pods/ephemeralcontainers subresource.
+	checkContainers(ephemeralContainers(ps.EphemeralContainers), "ephemeralContainers")
 
 	return errs
 }
 
+// matchesClusterPolicy returns whether policies hold a ClusterImagePolicy,
+// rather than only ImagePolicies.
+func matchesClusterPolicy(policies map[string]webhookcip.ClusterImagePolicy) bool {
+	for _, cip := range policies {
+		if cip.Namespace == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // validatePolicies will go through all the matching Policies and their
 // Authorities for a given image. Returns the map of policy=>Validated
 // signatures. From the map you can see the number of matched policies along
 // with the signatures that were verified.
 // If there's a policy that did not match, it will be returned in the errors map
 // along with all the errors that caused it to fail.
+// Policies fail here regardless of their mode, the caller decides whether
+// their failures deny the image.
 // Note that if an image does not match any policies, it's perfectly
 // reasonable that the return value is 0, nil since there were no errors, but
 // the image was not validated against any matching policy and hence authority.
@@ -269,11 +408,14 @@ func validatePolicies(ctx context.Context, namespace string, ref name.Reference,
 				if cip.Policy != nil {
 					logging.FromContext(ctx).Infof("Validating CIP level policy for %s", cipName)
 					policyJSON, err := json.Marshal(result.policyResult)
+					if err == nil && cip.Policy.IncludeContext {
+						policyJSON, err = policy.AddInputContext(policyJSON, policyInputContext(ctx, ref))
+					}
 					if err != nil {
 						result.errors = append(result.errors, err)
 					} else {
 						logging.FromContext(ctx).Debugf("Validating CIP level policy against %s", string(policyJSON))
-						err = policy.EvaluatePolicyAgainstJSON(ctx, "ClusterImagePolicy", cip.Policy.Type, cip.Policy.Data, policyJSON)
+						err = policy.EvaluatePolicyAgainstJSONWithQuery(ctx, "ClusterImagePolicy", cip.Policy.Type, cip.Policy.Data, cip.Policy.Query, policyJSON)
 						if err != nil {
 							result.errors = append(result.errors, err)
 						}
@@ -480,6 +622,7 @@ func ValidatePolicyAttestationsForAuthority(ctx context.Context, ref name.Refere
 	}
 
 	verifiedAttestations := []oci.Signature{}
+	times := integratedTimes{}
 	switch {
 	case authority.Key != nil && len(authority.Key.PublicKeys) > 0:
 		for _, k := range authority.Key.PublicKeys {
@@ -488,7 +631,7 @@ func ValidatePolicyAttestationsForAuthority(ctx context.Context, ref name.Refere
 				logging.FromContext(ctx).Errorf("error creating verifier: %v", err)
 				return nil, fmt.Errorf("creating verifier: %w", err)
 			}
-			va, err := validAttestations(ctx, ref, verifier, rekorClient, remoteOpts...)
+			va, err := validAttestations(ctx, ref, verifier, rekorClient, times, remoteOpts...)
 			if err != nil {
 				logging.FromContext(ctx).Errorf("error validating attestations: %v", err)
 				return nil, fmt.Errorf("validating attestations: %w", err)
@@ -503,7 +646,7 @@ func ValidatePolicyAttestationsForAuthority(ctx context.Context, ref name.Refere
 			if err != nil {
 				return nil, fmt.Errorf("fetching FulcioRoot: %w", err)
 			}
-			va, err := validAttestationsWithFulcio(ctx, ref, fulcioroot, rekorClient, authority.Keyless.Identities, remoteOpts...)
+			va, err := validAttestationsWithFulcio(ctx, ref, fulcioroot, rekorClient, authority.Keyless.Identities, times, remoteOpts...)
 			if err != nil {
 				logging.FromContext(ctx).Errorf("failed validAttestationsWithFulcio for authority %s with fulcio for %s: %v", name, ref.Name(), err)
 				return nil, fmt.Errorf("validate signatures with fulcio: %w", err)
@@ -524,15 +667,22 @@ func ValidatePolicyAttestationsForAuthority(ctx context.Context, ref name.Refere
 	// possible.
 	ret := map[string][]PolicySignature{}
 	for _, wantedAttestation := range authority.Attestations {
+		attestations := verifiedAttestations
+		if wantedAttestation.MaxAge != nil {
+			attestations, err = freshAttestations(ctx, wantedAttestation, verifiedAttestations, times)
+			if err != nil {
+				return nil, err
+			}
+		}
 		// If there's no type / policy to do more checking against,
 		// then we're done here. It matches all the attestations
 		if wantedAttestation.Type == "" {
-			ret[wantedAttestation.Name] = ociSignatureToPolicySignature(ctx, verifiedAttestations)
+			ret[wantedAttestation.Name] = ociSignatureToPolicySignature(ctx, attestations)
 			continue
 		}
 		// There's a particular type, so we need to go through all the verified
 		// attestations and make sure that our particular one is satisfied.
-		for _, va := range verifiedAttestations {
+		for _, va := range attestations {
 			attBytes, err := policy.AttestationToPayloadJSON(ctx, wantedAttestation.PredicateType, va)
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert attestation payload to json: %w", err)
@@ -542,17 +692,53 @@ func ValidatePolicyAttestationsForAuthority(ctx context.Context, ref name.Refere
 				// attestation is not for. It's not an error, so we skip it.
 				continue
 			}
-			if err := policy.EvaluatePolicyAgainstJSON(ctx, wantedAttestation.Name, wantedAttestation.Type, wantedAttestation.Data, attBytes); err != nil {
+			if wantedAttestation.IncludeContext {
+				ic, err := policyInputContext(ctx, ref).WithSignature(authority.Name, va)
+				if err != nil {
+					return nil, err
+				}
+				if attBytes, err = policy.AddInputContext(attBytes, ic); err != nil {
+					return nil, err
+				}
+			}
+			if err := policy.EvaluatePolicyAgainstJSONWithQuery(ctx, wantedAttestation.Name, wantedAttestation.Type, wantedAttestation.Data, wantedAttestation.Query, attBytes); err != nil {
 				return nil, err
 			}
 			// Ok, so this passed aok, jot it down to our result set as
 			// verified attestation with the predicate type match
-			ret[wantedAttestation.Name] = ociSignatureToPolicySignature(ctx, verifiedAttestations)
+			ret[wantedAttestation.Name] = ociSignatureToPolicySignature(ctx, attestations)
 		}
 	}
 	return ret, nil
 }
 
+// freshAttestations returns the attestations of the predicate type of
+// wanted which were produced within its MaxAge, or an error if there are
+// none. times are the ones the attestations verified online were integrated
+// in Rekor.
+func freshAttestations(ctx context.Context, wanted webhookcip.AttestationPolicy, attestations []oci.Signature, times integratedTimes) ([]oci.Signature, error) {
+	maxAge := wanted.MaxAge.Duration
+	ret := []oci.Signature{}
+	for _, att := range attestations {
+		attBytes, err := policy.AttestationToPayloadJSON(ctx, wanted.PredicateType, att)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert attestation payload to json: %w", err)
+		}
+		if attBytes == nil {
+			continue
+		}
+		if err := cosign.CheckAttestationAge(att, times.of(att), maxAge); err != nil {
+			logging.FromContext(ctx).Debugf("Skipping %s attestation for %s: %v", wanted.PredicateType, wanted.Name, err)
+			continue
+		}
+		ret = append(ret, att)
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no %s attestation produced within the last %s for %s", wanted.PredicateType, maxAge, wanted.Name)
+	}
+	return ret, nil
+}
+
 // ResolvePodSpecable implements duckv1.PodSpecValidator
 func (v *Validator) ResolvePodSpecable(ctx context.Context, wp *duckv1.WithPod) {
 	if wp.DeletionTimestamp != nil {
@@ -641,6 +827,23 @@ func (v *Validator) resolvePodSpec(ctx context.Context, ps *corev1.PodSpec, opt
 
 	resolveContainers(ps.InitContainers)
 	resolveContainers(ps.Containers)
+
+	ecs := ephemeralContainers(ps.EphemeralContainers)
+	resolveContainers(ecs)
+	for i := range ecs {
+		ps.EphemeralContainers[i].Image = ecs[i].Image
+	}
+}
+
+// ephemeralContainers returns the ephemeral containers as containers, which
+// have the same fields, so that their images are validated and resolved the
+// same way.
+func ephemeralContainers(ecs []corev1.EphemeralContainer) []corev1.Container {
+	cs := make([]corev1.Container, 0, len(ecs))
+	for _, ec := range ecs {
+		cs = append(cs, corev1.Container(ec.EphemeralContainerCommon))
+	}
+	return cs
 }
 
 func getFulcioCert(u *apis.URL) (*x509.CertPool, error) {
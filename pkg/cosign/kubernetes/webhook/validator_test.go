@@ -21,7 +21,9 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
@@ -37,10 +39,13 @@ import (
 	"github.com/sigstore/cosign/pkg/apis/config"
 	"github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
 	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/bundle"
 	webhookcip "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook/clusterimagepolicy"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
 	"github.com/sigstore/cosign/pkg/oci"
 	"github.com/sigstore/cosign/pkg/oci/remote"
 	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/pkg/policy"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -140,7 +145,7 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 		}, metav1.CreateOptions{})
 	}
 
-	v := NewValidator(ctx, secretName)
+	v := NewValidator(ctx, secretName, WithExplain(rego.ExplainFails))
 
 	cvs := cosignVerifySignatures
 	defer func() {
@@ -180,6 +185,8 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 		want          *apis.FieldError
 		cvs           func(context.Context, name.Reference, *cosign.CheckOpts) ([]oci.Signature, bool, error)
 		customContext context.Context
+		wantWarnings  []string
+		wantAudits    []string
 	}{{
 		name: "simple, no error",
 		ps: &corev1.PodSpec{
@@ -233,6 +240,88 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 			Details: digest.String(),
 		},
 		cvs: fail,
+	}, {
+		name: "ephemeral container, no error",
+		ps: &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "user-container",
+				Image: digest.String(),
+			}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:  "debugger",
+					Image: digest.String(),
+				},
+			}},
+		},
+		cvs: pass,
+	}, {
+		name: "ephemeral container, not digest",
+		ps: &corev1.PodSpec{
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:  "debugger",
+					Image: tag.String(),
+				},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: `invalid value: gcr.io/distroless/static:nonroot must be an image digest`,
+			Paths:   []string{"ephemeralContainers[0].image"},
+		},
+		cvs: pass,
+	}, {
+		name: "ephemeral container, bad signature",
+		ps: &corev1.PodSpec{
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:  "debugger",
+					Image: digest.String(),
+				},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: `bad signature`,
+			Paths:   []string{"ephemeralContainers[0].image"},
+			Details: digest.String(),
+		},
+		cvs: fail,
+	}, {
+		name: "ephemeral container, error, authority key",
+		ps: &corev1.PodSpec{
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:  "debugger",
+					Image: digest.String(),
+				},
+			}},
+		},
+		customContext: config.ToContext(context.Background(),
+			&config.Config{
+				ImagePolicyConfig: &config.ImagePolicyConfig{
+					Policies: map[string]webhookcip.ClusterImagePolicy{
+						"cluster-image-policy": {
+							Images: []v1alpha1.ImagePattern{{
+								Glob: "gcr.io/*/*",
+							}},
+							Authorities: []webhookcip.Authority{{
+								Name: "authority-0",
+								Key: &webhookcip.KeyRef{
+									Data:       authorityKeyCosignPubString,
+									PublicKeys: []crypto.PublicKey{authorityKeyCosignPub},
+								},
+							}},
+						},
+					},
+				},
+			},
+		),
+		want: func() *apis.FieldError {
+			fe := apis.ErrGeneric("failed policy: cluster-image-policy", "image").ViaFieldIndex("ephemeralContainers", 0)
+			fe.Details = fmt.Sprintf("%s failed to validate public keys with authority authority-0 for %s: bad signature", digest.String(), digest.Name())
+			return fe
+		}(),
+		cvs: fail,
 	}, {
 		name: "simple, no error, authority key",
 		ps: &corev1.PodSpec{
@@ -267,6 +356,44 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 			},
 		),
 		cvs: authorityPublicKeyCVS,
+	}, {
+		name: "only an image policy matches and passes, secret still checked",
+		ps: &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "user-container",
+				Image: digest.String(),
+			}},
+		},
+		customContext: config.ToContext(context.Background(),
+			&config.Config{
+				ImagePolicyConfig: &config.ImagePolicyConfig{
+					Policies: map[string]webhookcip.ClusterImagePolicy{
+						config.ImagePolicyKey(system.Namespace(), "image-policy"): {
+							Images: []v1alpha1.ImagePattern{{
+								Glob: "**",
+							}},
+							Authorities: []webhookcip.Authority{
+								{
+									Key: &webhookcip.KeyRef{
+										Data:       authorityKeyCosignPubString,
+										PublicKeys: []crypto.PublicKey{authorityKeyCosignPub},
+									},
+								},
+							},
+							Namespace: system.Namespace(),
+						},
+					},
+				},
+			},
+		),
+		// The image policy key verifies the image, but the one of the
+		// secret does not.
+		want: &apis.FieldError{
+			Message: `bad signature`,
+			Paths:   []string{"containers[0].image"},
+			Details: digest.String(),
+		},
+		cvs: authorityPublicKeyCVS,
 	}, {
 		name: "simple, error, authority keyless, bad fulcio",
 		ps: &corev1.PodSpec{
@@ -310,6 +437,82 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 			return errs
 		}(),
 		cvs: fail,
+	}, {
+		name: "simple, authority keyless, bad fulcio, warn mode",
+		ps: &corev1.PodSpec{
+			InitContainers: []corev1.Container{{
+				Name:  "setup-stuff",
+				Image: digest.String(),
+			}},
+			Containers: []corev1.Container{{
+				Name:  "user-container",
+				Image: digest.String(),
+			}},
+		},
+		customContext: config.ToContext(context.Background(),
+			&config.Config{
+				ImagePolicyConfig: &config.ImagePolicyConfig{
+					Policies: map[string]webhookcip.ClusterImagePolicy{
+						"cluster-image-policy-keyless": {
+							Images: []v1alpha1.ImagePattern{{
+								Glob: "gcr.io/*/*",
+							}},
+							Authorities: []webhookcip.Authority{
+								{
+									Keyless: &webhookcip.KeylessRef{
+										URL: badURL,
+									},
+								},
+							},
+							Mode: "warn",
+						},
+					},
+				},
+			},
+		),
+		wantWarnings: []string{
+			"failed policy: cluster-image-policy-keyless: " + digest.String() + " " + `fetching FulcioRoot: getting root cert: parse "http://http:%2F%2Fexample.com%2F/api/v1/rootCert": invalid port ":%2F%2Fexample.com%2F" after host`,
+			"failed policy: cluster-image-policy-keyless: " + digest.String() + " " + `fetching FulcioRoot: getting root cert: parse "http://http:%2F%2Fexample.com%2F/api/v1/rootCert": invalid port ":%2F%2Fexample.com%2F" after host`,
+		},
+		cvs: fail,
+	}, {
+		name: "simple, authority keyless, bad fulcio, audit mode",
+		ps: &corev1.PodSpec{
+			InitContainers: []corev1.Container{{
+				Name:  "setup-stuff",
+				Image: digest.String(),
+			}},
+			Containers: []corev1.Container{{
+				Name:  "user-container",
+				Image: digest.String(),
+			}},
+		},
+		customContext: config.ToContext(context.Background(),
+			&config.Config{
+				ImagePolicyConfig: &config.ImagePolicyConfig{
+					Policies: map[string]webhookcip.ClusterImagePolicy{
+						"cluster-image-policy-keyless": {
+							Images: []v1alpha1.ImagePattern{{
+								Glob: "gcr.io/*/*",
+							}},
+							Authorities: []webhookcip.Authority{
+								{
+									Keyless: &webhookcip.KeylessRef{
+										URL: badURL,
+									},
+								},
+							},
+							Mode: "audit",
+						},
+					},
+				},
+			},
+		),
+		wantAudits: []string{
+			"failed policy: cluster-image-policy-keyless: " + digest.String() + " " + `fetching FulcioRoot: getting root cert: parse "http://http:%2F%2Fexample.com%2F/api/v1/rootCert": invalid port ":%2F%2Fexample.com%2F" after host`,
+			"failed policy: cluster-image-policy-keyless: " + digest.String() + " " + `fetching FulcioRoot: getting root cert: parse "http://http:%2F%2Fexample.com%2F/api/v1/rootCert": invalid port ":%2F%2Fexample.com%2F" after host`,
+		},
+		cvs: fail,
 	}, {
 		name: "simple, error, authority keyless, good fulcio, no rekor",
 		ps: &corev1.PodSpec{
@@ -393,10 +596,11 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 		want: func() *apis.FieldError {
 			var errs *apis.FieldError
 			fe := apis.ErrGeneric("failed policy: cluster-image-policy-keyless", "image").ViaFieldIndex("initContainers", 0)
-			fe.Details = fmt.Sprintf("%s failed evaluating cue policy for ClusterImagePolicy : failed to compile the cue policy with error: string literal not terminated", digest.String())
+			// The details explain where the policy failed.
+			fe.Details = fmt.Sprintf("%s failed evaluating cue policy for ClusterImagePolicy : failed to compile the cue policy with error: string literal not terminated\nstring literal not terminated:\n    1:2", digest.String())
 			errs = errs.Also(fe)
 			fe2 := apis.ErrGeneric("failed policy: cluster-image-policy-keyless", "image").ViaFieldIndex("containers", 0)
-			fe2.Details = fmt.Sprintf("%s failed evaluating cue policy for ClusterImagePolicy : failed to compile the cue policy with error: string literal not terminated", digest.String())
+			fe2.Details = fmt.Sprintf("%s failed evaluating cue policy for ClusterImagePolicy : failed to compile the cue policy with error: string literal not terminated\nstring literal not terminated:\n    1:2", digest.String())
 			errs = errs.Also(fe2)
 			return errs
 		}(),
@@ -584,12 +788,19 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 			}
 
 			// Check the core mechanics
-			got := v.validatePodSpec(testContext, system.Namespace(), test.ps, k8schain.Options{})
+			warningsContext, aw := withAdmissionWarnings(testContext)
+			got := v.validatePodSpec(warningsContext, system.Namespace(), test.ps, k8schain.Options{})
 			if (got != nil) != (test.want != nil) {
 				t.Errorf("validatePodSpec() = %v, wanted %v", got, test.want)
 			} else if got != nil && got.Error() != test.want.Error() {
 				t.Errorf("validatePodSpec() = %v, wanted %v", got, test.want)
 			}
+			if diff := cmp.Diff(test.wantWarnings, aw.warnings); diff != "" {
+				t.Errorf("validatePodSpec() warnings (-want, +got) = %s", diff)
+			}
+			if diff := cmp.Diff(test.wantAudits, aw.audits); diff != "" {
+				t.Errorf("validatePodSpec() audits (-want, +got) = %s", diff)
+			}
 
 			// Check wrapped in a Pod
 			pod := &duckv1.Pod{
@@ -958,6 +1169,36 @@ UoJou2P8sbDxpLiE/v3yLw1/jyOrCPWYHWFXnyyeGlkgSVefG54tNoK7Uw==
 		rrd: func(r name.Reference, o ...remote.Option) (name.Digest, error) {
 			return name.Digest{}, errors.New("boom")
 		},
+	}, {
+		name: "ephemeral container digests resolve (subresource update)",
+		ps: &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "user-container",
+				Image: digest.String(),
+			}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:  "debugger",
+					Image: tag.String(),
+				},
+			}},
+		},
+		want: &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "user-container",
+				Image: digest.String(),
+			}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:  "debugger",
+					Image: digest.String(),
+				},
+			}},
+		},
+		wc: func(ctx context.Context) context.Context {
+			return apis.WithinSubResourceUpdate(ctx, nil, "ephemeralcontainers")
+		},
+		rrd: resolve,
 	}, {
 		name: "digests resolve (in create)",
 		ps: &corev1.PodSpec{
@@ -1552,3 +1793,136 @@ func TestValidatePoliciesCancelled(t *testing.T) {
 	_, gotErrs := validatePolicies(testContext, system.Namespace(), digest, map[string]webhookcip.ClusterImagePolicy{"testcip": cip})
 	validateErrors(t, wantErrs, gotErrs["internalerror"])
 }
+
+func TestFreshAttestations(t *testing.T) {
+	vulnAttestation := func(finished time.Time) oci.Signature {
+		statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"cosign.sigstore.dev/attestation/vuln/v1","subject":[],`+
+			`"predicate":{"invocation":{},"scanner":{},"metadata":{"scanStartedOn":%q,"scanFinishedOn":%q}}}`,
+			finished.Format(time.RFC3339), finished.Format(time.RFC3339))
+		envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+			base64.StdEncoding.EncodeToString([]byte(statement)))
+		att, err := static.NewAttestation([]byte(envelope))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return att
+	}
+	recent := vulnAttestation(time.Now().Add(-time.Hour))
+	old := vulnAttestation(time.Now().Add(-30 * 24 * time.Hour))
+	wanted := webhookcip.AttestationPolicy{
+		Name:          "fresh-scan",
+		PredicateType: "vuln",
+		MaxAge:        &metav1.Duration{Duration: 24 * time.Hour},
+	}
+
+	got, err := freshAttestations(context.Background(), wanted, []oci.Signature{old, recent}, integratedTimes{})
+	if err != nil {
+		t.Fatalf("freshAttestations() = %v", err)
+	}
+	if len(got) != 1 || got[0] != recent {
+		t.Errorf("freshAttestations() = %v, wanted only the recent attestation", got)
+	}
+
+	if _, err := freshAttestations(context.Background(), wanted, []oci.Signature{old}, integratedTimes{}); err == nil ||
+		!strings.Contains(err.Error(), "no vuln attestation produced within the last 24h0m0s") {
+		t.Errorf("freshAttestations() = %v, wanted an error without recent attestations", err)
+	}
+
+	wanted.PredicateType = "slsaprovenance"
+	if _, err := freshAttestations(context.Background(), wanted, []oci.Signature{recent}, integratedTimes{}); err == nil {
+		t.Error("freshAttestations() succeeded without attestations of the predicate type")
+	}
+
+	// Attestations without a bundle nor a predicate timestamp are as old as
+	// their entry in Rekor, when they were verified online against it.
+	statement := `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2","subject":[],"predicate":{}}`
+	envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+		base64.StdEncoding.EncodeToString([]byte(statement)))
+	provenance, err := static.NewAttestation([]byte(envelope))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := freshAttestations(context.Background(), wanted, []oci.Signature{provenance}, integratedTimes{}); err == nil {
+		t.Error("freshAttestations() succeeded without telling the age of the attestation")
+	}
+	times := integratedTimes{}
+	times.add(provenance, time.Now().Add(-time.Hour))
+	if got, err := freshAttestations(context.Background(), wanted, []oci.Signature{provenance}, times); err != nil || len(got) != 1 {
+		t.Errorf("freshAttestations() = %v, %v, wanted the attestation integrated recently", got, err)
+	}
+}
+
+func TestValidatePolicyAttestationsInputContext(t *testing.T) {
+	digest := name.MustParseReference("gcr.io/distroless/static:nonroot@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4")
+	statement := `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"cosign.sigstore.dev/attestation/v1","subject":[],` +
+		`"predicate":{"Data":"foo","Timestamp":"2022-05-01T00:00:00Z"}}`
+	envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+		base64.StdEncoding.EncodeToString([]byte(statement)))
+	att, err := static.NewAttestation([]byte(envelope), static.WithBundle(&bundle.RekorBundle{
+		Payload: bundle.RekorPayload{LogIndex: 42, LogID: "log", IntegratedTime: 1651363200},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cva := cosignVerifyAttestations
+	t.Cleanup(func() { cosignVerifyAttestations = cva })
+	cosignVerifyAttestations = func(context.Context, name.Reference, *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+		return []oci.Signature{att}, true, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authority := webhookcip.Authority{
+		Name: "scanner",
+		Key:  &webhookcip.KeyRef{PublicKeys: []crypto.PublicKey{key.Public()}},
+		Attestations: []webhookcip.AttestationPolicy{{
+			Name:           "prod-only",
+			PredicateType:  "custom",
+			Type:           "rego",
+			IncludeContext: true,
+			Data: `package sigstore
+isCompliant {
+	input.context.namespace == "prod"
+	input.context.labels.app == "web"
+	input.context.workload.kind == "Deployment"
+	input.context.image.digest == "sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4"
+	input.context.signer.authority == "scanner"
+	input.context.rekor.logIndex == 42
+	input.context.rekor.integratedTime == "2022-05-01T00:00:00Z"
+	time.parse_rfc3339_ns(input.context.admissionTime) > time.parse_rfc3339_ns(input.context.rekor.integratedTime)
+}`,
+		}},
+	}
+
+	for _, namespace := range []string{"prod", "dev"} {
+		ctx := withAdmissionContext(context.Background(), namespace, map[string]string{"app": "web"},
+			&policy.WorkloadContext{Kind: "Deployment", Name: "web"})
+		_, err := ValidatePolicyAttestationsForAuthority(ctx, digest, authority)
+		if (err == nil) != (namespace == "prod") {
+			t.Errorf("ValidatePolicyAttestationsForAuthority() in namespace %s = %v", namespace, err)
+		}
+	}
+
+	// The policies not opting in get the statement alone.
+	authority.Attestations[0].IncludeContext = false
+	authority.Attestations[0].Data = `package sigstore
+isCompliant {
+	not input.context
+}`
+	ctx := withAdmissionContext(context.Background(), "prod", nil, nil)
+	if _, err := ValidatePolicyAttestationsForAuthority(ctx, digest, authority); err != nil {
+		t.Errorf("ValidatePolicyAttestationsForAuthority() without the context = %v", err)
+	}
+}
+
+func TestTruncateExplanation(t *testing.T) {
+	if got := truncateExplanation("  short\n"); got != "short" {
+		t.Errorf("truncateExplanation() = %q, wanted %q", got, "short")
+	}
+	got := truncateExplanation(strings.Repeat("x", 2*maxExplanationSize))
+	if want := strings.Repeat("x", maxExplanationSize) + "\n... (truncated)"; got != want {
+		t.Errorf("truncateExplanation() kept %d bytes, wanted %d", len(got), len(want))
+	}
+}
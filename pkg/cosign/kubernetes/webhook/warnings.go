@@ -0,0 +1,119 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/webhook"
+)
+
+// FailedPoliciesAnnotation is the audit annotation recording the failures of
+// the ClusterImagePolicies in audit mode.
+const FailedPoliciesAnnotation = "failed-policies"
+
+// The validation admission controller of knative only allows or denies
+// requests. The failures of the ClusterImagePolicies which are not enforced
+// are gathered in the context of the request instead, and added to its
+// response as warnings and audit annotations.
+
+type admissionWarningsKey struct{}
+
+type admissionWarnings struct {
+	sync.Mutex
+	warnings []string
+	audits   []string
+}
+
+// withAdmissionWarnings adds to ctx a collector of the warnings and audit
+// records of the admission.
+func withAdmissionWarnings(ctx context.Context) (context.Context, *admissionWarnings) {
+	aw := &admissionWarnings{}
+	return context.WithValue(ctx, admissionWarningsKey{}, aw), aw
+}
+
+// warnAdmission logs msg and returns it as a warning of the admission.
+func warnAdmission(ctx context.Context, msg string) {
+	logging.FromContext(ctx).Warn(msg)
+	if aw, ok := ctx.Value(admissionWarningsKey{}).(*admissionWarnings); ok {
+		aw.Lock()
+		defer aw.Unlock()
+		aw.warnings = append(aw.warnings, msg)
+	}
+}
+
+// auditAdmission logs msg and records it in the audit annotations of the
+// admission.
+func auditAdmission(ctx context.Context, msg string) {
+	logging.FromContext(ctx).Warn(msg)
+	if aw, ok := ctx.Value(admissionWarningsKey{}).(*admissionWarnings); ok {
+		aw.Lock()
+		defer aw.Unlock()
+		aw.audits = append(aw.audits, msg)
+	}
+}
+
+// policyFailure describes the failure of the policy named cipName for image,
+// in a single line.
+func policyFailure(cipName, image string, errs []error) string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("failed policy: %s: %s %s", cipName, image, strings.Join(msgs, "; "))
+}
+
+type warningAdmission struct {
+	admissionReconciler
+	// Like the knative admission controllers, Admit doesn't depend on
+	// informers.
+	webhook.StatelessAdmissionImpl
+}
+
+// Admit implements webhook.AdmissionController
+func (w *warningAdmission) Admit(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	ctx, aw := withAdmissionWarnings(ctx)
+	resp := w.admissionReconciler.Admit(ctx, request)
+
+	aw.Lock()
+	defer aw.Unlock()
+	resp.Warnings = append(resp.Warnings, aw.warnings...)
+	if len(aw.audits) > 0 {
+		if resp.AuditAnnotations == nil {
+			resp.AuditAnnotations = make(map[string]string, 1)
+		}
+		resp.AuditAnnotations[FailedPoliciesAnnotation] = strings.Join(aw.audits, "\n")
+	}
+	return resp
+}
+
+// WithAdmissionWarnings makes the admission controller of impl return the
+// failures of the ClusterImagePolicies in warn mode as admission warnings,
+// and record the ones in audit mode in the audit annotations of the request.
+func WithAdmissionWarnings(impl *controller.Impl) *controller.Impl {
+	r, ok := impl.Reconciler.(admissionReconciler)
+	if !ok {
+		// Only the knative admission controllers are wrapped.
+		panic(fmt.Sprintf("%T is not an admission controller", impl.Reconciler))
+	}
+	impl.Reconciler = &warningAdmission{admissionReconciler: r}
+	return impl
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/webhook"
+)
+
+func TestWithAdmissionWarnings(t *testing.T) {
+	tests := []struct {
+		name                 string
+		admit                func(context.Context)
+		wantWarnings         []string
+		wantAuditAnnotations map[string]string
+	}{{
+		name:  "nothing to report",
+		admit: func(context.Context) {},
+	}, {
+		name: "warnings",
+		admit: func(ctx context.Context) {
+			warnAdmission(ctx, policyFailure("warn-policy", "gcr.io/foo/bar@sha256:abc", []error{errors.New("bad signature")}))
+			warnAdmission(ctx, "second warning")
+		},
+		wantWarnings: []string{
+			"failed policy: warn-policy: gcr.io/foo/bar@sha256:abc bad signature",
+			"second warning",
+		},
+	}, {
+		name: "audits",
+		admit: func(ctx context.Context) {
+			auditAdmission(ctx, policyFailure("audit-policy", "gcr.io/foo/bar@sha256:abc", []error{errors.New("bad signature"), errors.New("no matching attestations")}))
+			auditAdmission(ctx, "second failure")
+		},
+		wantAuditAnnotations: map[string]string{
+			FailedPoliciesAnnotation: "failed policy: audit-policy: gcr.io/foo/bar@sha256:abc bad signature; no matching attestations\nsecond failure",
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			impl := WithAdmissionWarnings(&controller.Impl{Reconciler: &fakeAdmissionReconciler{admit: test.admit}})
+			ac, ok := impl.Reconciler.(webhook.AdmissionController)
+			if !ok {
+				t.Fatal("the wrapped reconciler is not an admission controller")
+			}
+			if _, ok := impl.Reconciler.(webhook.StatelessAdmissionController); !ok {
+				t.Error("the wrapped reconciler is not stateless")
+			}
+			resp := ac.Admit(context.Background(), &admissionv1.AdmissionRequest{})
+			if !resp.Allowed {
+				t.Error("Admit() denied the request")
+			}
+			if diff := cmp.Diff(test.wantWarnings, resp.Warnings); diff != "" {
+				t.Errorf("Admit() warnings (-want, +got) = %s", diff)
+			}
+			if diff := cmp.Diff(test.wantAuditAnnotations, resp.AuditAnnotations); diff != "" {
+				t.Errorf("Admit() audit annotations (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
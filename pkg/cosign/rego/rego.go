@@ -20,8 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/open-policy-agent/opa/topdown/lineage"
 	"knative.dev/pkg/logging"
 )
 
@@ -36,86 +41,386 @@ const CosignRegoPackageName = "sigstore"
 // CosignEvaluationRule defines the expected evaluation role of a provided rego module
 const CosignEvaluationRule = "isCompliant"
 
+// DenyRule is the name of the rule, in the package of the query, whose
+// messages explain why a policy rejects its input.
+const DenyRule = "deny"
+
+// Options configures how policies are evaluated.
+type Options struct {
+	// Query is the query deciding whether the input is allowed, following the
+	// requirements of QUERY, which is used when it is empty.
+	Query string
+	// Data are paths of JSON or YAML documents, or directories of them,
+	// loaded in the data document. They are merged at its root unless
+	// prefixed with "<path>:", such as "config.allowed:allowed.json".
+	Data []string
+	// Bundles are paths of OPA bundles, either tarballs or directories,
+	// whose modules and data are loaded along with the policies.
+	Bundles []string
+	// Explain is the explanation mode of the failed evaluations, one of
+	// ExplainModes. They are not explained when it is empty.
+	Explain string
+}
+
+// The explanation modes, as those of `opa eval --explain`.
+const (
+	// ExplainNotes keeps the notes of the trace, from calls to trace().
+	ExplainNotes = "notes"
+	// ExplainFails keeps the expressions which failed, and their lineage.
+	ExplainFails = "fails"
+	// ExplainFull keeps the whole trace.
+	ExplainFull = "full"
+)
+
+// ExplainModes lists the supported explanation modes.
+var ExplainModes = []string{ExplainNotes, ExplainFails, ExplainFull}
+
+// ValidateExplainMode returns an error if mode is not one of ExplainModes.
+func ValidateExplainMode(mode string) error {
+	for _, m := range ExplainModes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid explanation mode %q, expected one of %s", mode, strings.Join(ExplainModes, ", "))
+}
+
+// Explanation is the evaluation trace of a failed policy, returned along
+// with its errors when they are explained.
+type Explanation struct {
+	Trace string
+}
+
+// Error implements error
+func (e *Explanation) Error() string {
+	return fmt.Sprintf("evaluation trace:\n%s", e.Trace)
+}
+
+// Violation is a message of the deny rule of a policy.
+type Violation struct {
+	// Msg is the message, or the msg field of a structured message.
+	Msg string
+	// Details is the whole message when it is not a string.
+	Details interface{}
+}
+
+// Error implements error
+func (v *Violation) Error() string {
+	return v.Msg
+}
+
+// DeniedError is returned when the deny rule of a policy has messages.
+type DeniedError struct {
+	Violations []*Violation
+}
+
+// Error implements error
+func (e *DeniedError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Msg)
+	}
+	return fmt.Sprintf("policy denied: %s", strings.Join(msgs, "; "))
+}
+
 func ValidateJSON(jsonBody []byte, entrypoints []string) []error {
-	ctx := context.Background()
+	return ValidateJSONWithOptions(jsonBody, entrypoints, Options{})
+}
 
-	r := rego.New(
-		rego.Query(QUERY),
-		rego.Load(entrypoints, nil))
+// ValidateJSONWithOptions evaluates the policies in entrypoints against the
+// JSON body. The body is rejected when the query is not true, or when the
+// deny rule in the package of the query, if any, has messages. Each of them
+// is returned as a *Violation.
+func ValidateJSONWithOptions(jsonBody []byte, entrypoints []string, opts Options) []error {
+	ctx := context.Background()
+	query := opts.Query
+	if query == "" {
+		query = QUERY
+	}
 
-	query, err := r.PrepareForEval(ctx)
+	input, err := decodeInput(jsonBody)
 	if err != nil {
 		return []error{err}
 	}
-
-	var input interface{}
-	dec := json.NewDecoder(bytes.NewBuffer(jsonBody))
-	dec.UseNumber()
-	if err := dec.Decode(&input); err != nil {
-		return []error{err}
+	load := func(query string) []func(*rego.Rego) {
+		args := []func(*rego.Rego){
+			rego.Query(query),
+			rego.Load(append(append([]string{}, entrypoints...), opts.Data...), nil),
+		}
+		for _, b := range opts.Bundles {
+			args = append(args, rego.LoadBundle(b))
+		}
+		return args
 	}
 
-	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	// The compiler holds the modules of the policies, to tell which rules
+	// they define.
+	compiler := ast.NewCompiler()
+	rs, err := eval(ctx, input, append(load(query), rego.Compiler(compiler))...)
 	if err != nil {
 		return []error{err}
 	}
+	var violations []*Violation
+	allowed := rs.Allowed()
+	if denyQuery := denyQueryFor(query); denyQuery != "" {
+		denyRS, err := eval(ctx, input, load(denyQuery)...)
+		if err != nil {
+			return []error{err}
+		}
+		violations = violationsOf(denyRS)
+		// Policies which only define the deny rule allow what it
+		// doesn't deny. An undefined query rule still fails the
+		// policies which define it.
+		if len(rs) == 0 && definesRule(compiler, denyQuery) && !definesRule(compiler, query) {
+			allowed = true
+		}
+	}
 
 	// Ensure the resultset contains a single result where the Expression contains a single value
 	// which is true and there are no Bindings.
-	if rs.Allowed() {
+	if allowed && len(violations) == 0 {
 		return nil
 	}
+	explain := func(errs []error) []error {
+		if opts.Explain == "" {
+			return errs
+		}
+		// The deny rule is explained when it has messages, as they are
+		// what rejected the input.
+		q := query
+		if len(violations) > 0 {
+			q = denyQueryFor(query)
+		}
+		trace, err := explainQuery(ctx, input, opts.Explain, load(q)...)
+		if err != nil {
+			return append(errs, err)
+		}
+		return append(errs, &Explanation{Trace: trace})
+	}
 
 	var errs []error
-	for _, result := range rs {
-		for _, expression := range result.Expressions {
-			errs = append(errs, fmt.Errorf("expression value, %v, is not true", expression))
+	for _, v := range violations {
+		errs = append(errs, v)
+	}
+	if !rs.Allowed() {
+		for _, result := range rs {
+			for _, expression := range result.Expressions {
+				errs = append(errs, fmt.Errorf("expression value, %v, is not true", expression))
+			}
 		}
 	}
 
 	// When rs.Allowed() is not true and len(rs) is 0, the result is undefined. This is a policy
 	// check failure.
 	if len(errs) == 0 {
-		errs = append(errs, fmt.Errorf("result is undefined for query '%s'", QUERY))
+		errs = append(errs, fmt.Errorf("result is undefined for query '%s'", query))
 	}
-	return errs
+	return explain(errs)
 }
 
 // ValidateJSONWithModuleInput takes the body of the results to evaluate and the defined module
 // in a policy to validate against the input data
 func ValidateJSONWithModuleInput(jsonBody []byte, moduleInput string) error {
+	return ValidateJSONWithModuleInputAndQuery(jsonBody, moduleInput, "")
+}
+
+// ValidateJSONWithModuleInputAndQuery is ValidateJSONWithModuleInput with a
+// custom query, following the requirements of QUERY. When it is empty, the
+// module must define the CosignEvaluationRule of the CosignRegoPackageName
+// package. The deny rule of the package of the query, if any, has to be
+// empty, otherwise a *DeniedError with its messages is returned.
+func ValidateJSONWithModuleInputAndQuery(jsonBody []byte, moduleInput string, query string) error {
 	ctx := context.Background()
-	query := fmt.Sprintf("%s = data.%s.%s", CosignEvaluationRule, CosignRegoPackageName, CosignEvaluationRule)
+	allowQuery := query
+	if allowQuery == "" {
+		allowQuery = fmt.Sprintf("%s = data.%s.%s", CosignEvaluationRule, CosignRegoPackageName, CosignEvaluationRule)
+	}
 	module := fmt.Sprintf("%s.rego", CosignRegoPackageName)
 
-	r := rego.New(
-		rego.Query(query),
-		rego.Module(module, moduleInput))
+	input, err := decodeInput(jsonBody)
+	if err != nil {
+		return err
+	}
 
-	evalQuery, err := r.PrepareForEval(ctx)
+	denyQuery := fmt.Sprintf("data.%s.%s", CosignRegoPackageName, DenyRule)
+	if query != "" {
+		denyQuery = denyQueryFor(query)
+	}
+	if denyQuery != "" {
+		denyRS, err := eval(ctx, input, rego.Query(denyQuery), rego.Module(module, moduleInput))
+		if err != nil {
+			return err
+		}
+		if violations := violationsOf(denyRS); len(violations) > 0 {
+			return &DeniedError{Violations: violations}
+		}
+	}
+
+	rs, err := eval(ctx, input, rego.Query(allowQuery), rego.Module(module, moduleInput))
 	if err != nil {
 		return err
 	}
 
+	if query != "" {
+		if rs.Allowed() {
+			logging.FromContext(ctx).Info("Validated policy is compliant")
+			return nil
+		}
+	} else {
+		for _, result := range rs {
+			isCompliant, ok := result.Bindings[CosignEvaluationRule].(bool)
+			if ok && isCompliant {
+				logging.FromContext(ctx).Info("Validated policy is compliant")
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("policy is not compliant for query '%s'", allowQuery)
+}
+
+// CompileModule compiles the module along with the query, as done by
+// ValidateJSONWithModuleInputAndQuery, without evaluating it. It returns the
+// parse and type errors of the module and of the query.
+func CompileModule(moduleInput string, query string) error {
+	if query == "" {
+		query = fmt.Sprintf("data.%s.%s", CosignRegoPackageName, CosignEvaluationRule)
+	}
+	module := fmt.Sprintf("%s.rego", CosignRegoPackageName)
+	_, err := rego.New(rego.Query(query), rego.Module(module, moduleInput)).PrepareForEval(context.Background())
+	return err
+}
+
+// ExplainJSONWithModuleInput returns the trace of the evaluation of the
+// module against the JSON body, as done by ValidateJSONWithModuleInputAndQuery,
+// in the given explanation mode. When the deny rule of the package of the
+// query has messages, its evaluation is explained instead.
+func ExplainJSONWithModuleInput(jsonBody []byte, moduleInput string, query string, mode string) (string, error) {
+	ctx := context.Background()
+	module := fmt.Sprintf("%s.rego", CosignRegoPackageName)
+	allowQuery, denyQuery := query, denyQueryFor(query)
+	if query == "" {
+		allowQuery = fmt.Sprintf("data.%s.%s", CosignRegoPackageName, CosignEvaluationRule)
+		denyQuery = fmt.Sprintf("data.%s.%s", CosignRegoPackageName, DenyRule)
+	}
+
+	input, err := decodeInput(jsonBody)
+	if err != nil {
+		return "", err
+	}
+	if denyQuery != "" {
+		denyRS, err := eval(ctx, input, rego.Query(denyQuery), rego.Module(module, moduleInput))
+		if err != nil {
+			return "", err
+		}
+		if len(violationsOf(denyRS)) > 0 {
+			allowQuery = denyQuery
+		}
+	}
+	return explainQuery(ctx, input, mode, rego.Query(allowQuery), rego.Module(module, moduleInput))
+}
+
+// explainQuery evaluates a query with a tracer, and returns its trace
+// filtered according to the explanation mode.
+func explainQuery(ctx context.Context, input interface{}, mode string, args ...func(*rego.Rego)) (string, error) {
+	query, err := rego.New(args...).PrepareForEval(ctx)
+	if err != nil {
+		return "", err
+	}
+	// Rule indexing would skip the rules whose conditions don't match the
+	// input, which are the ones worth explaining.
+	tracer := topdown.NewBufferTracer()
+	if _, err := query.Eval(ctx, rego.EvalInput(input), rego.EvalQueryTracer(tracer), rego.EvalRuleIndexing(false)); err != nil {
+		return "", err
+	}
+	trace := []*topdown.Event(*tracer)
+	switch mode {
+	case ExplainNotes:
+		trace = lineage.Notes(trace)
+	case ExplainFails:
+		trace = lineage.Fails(trace)
+	case ExplainFull:
+	default:
+		return "", ValidateExplainMode(mode)
+	}
+	var b strings.Builder
+	topdown.PrettyTraceWithLocation(&b, trace)
+	return b.String(), nil
+}
+
+func decodeInput(jsonBody []byte) (interface{}, error) {
 	var input interface{}
 	dec := json.NewDecoder(bytes.NewBuffer(jsonBody))
 	dec.UseNumber()
 	if err := dec.Decode(&input); err != nil {
-		return err
+		return nil, err
 	}
+	return input, nil
+}
 
-	rs, err := evalQuery.Eval(ctx, rego.EvalInput(input))
+func eval(ctx context.Context, input interface{}, args ...func(*rego.Rego)) (rego.ResultSet, error) {
+	query, err := rego.New(args...).PrepareForEval(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return query.Eval(ctx, rego.EvalInput(input))
+}
+
+// denyQueryFor returns the query of the deny rule in the package of query,
+// when query is a reference to a rule such as data.signature.allow.
+func denyQueryFor(query string) string {
+	ref, err := ast.ParseRef(query)
+	if err != nil || len(ref) < 3 || !ref.HasPrefix(ast.DefaultRootRef) || !ref.IsGround() {
+		return ""
+	}
+	return ref[:len(ref)-1].Append(ast.StringTerm(DenyRule)).String()
+}
+
+// definesRule returns whether the modules compiled by compiler define the
+// rule referenced by query.
+func definesRule(compiler *ast.Compiler, query string) bool {
+	ref, err := ast.ParseRef(query)
+	if err != nil {
+		return false
+	}
+	return len(compiler.GetRulesExact(ref)) > 0
+}
 
+// violationsOf returns the messages of the result of a deny query, which may
+// be a set or an array of strings, or of objects with a msg field. A boolean
+// deny rule which is true is a violation too.
+func violationsOf(rs rego.ResultSet) []*Violation {
+	var violations []*Violation
 	for _, result := range rs {
-		isCompliant, ok := result.Bindings[CosignEvaluationRule].(bool)
-		if ok && isCompliant {
-			logging.FromContext(ctx).Info("Validated policy is compliant")
-			return nil
+		for _, expression := range result.Expressions {
+			var msgs []interface{}
+			switch value := expression.Value.(type) {
+			case []interface{}:
+				msgs = value
+			case bool:
+				if value {
+					violations = append(violations, &Violation{Msg: fmt.Sprintf("%s is true", expression.Text)})
+				}
+			}
+			for _, m := range msgs {
+				switch m := m.(type) {
+				case string:
+					violations = append(violations, &Violation{Msg: m})
+				case map[string]interface{}:
+					msg, ok := m["msg"].(string)
+					if !ok {
+						b, _ := json.Marshal(m)
+						msg = string(b)
+					}
+					violations = append(violations, &Violation{Msg: msg, Details: m})
+				default:
+					violations = append(violations, &Violation{Msg: fmt.Sprint(m), Details: m})
+				}
+			}
 		}
 	}
-
-	return fmt.Errorf("policy is not compliant for query '%s'", query)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Msg < violations[j].Msg
+	})
+	return violations
 }
@@ -16,8 +16,11 @@
 package rego
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -70,6 +73,78 @@ func TestValidationJSON(t *testing.T) {
 			pass:   false,
 			errors: []string{"expression value, false, is not true"},
 		},
+		{
+			name:     "undefined result with an empty deny set",
+			jsonBody: `{"approved": false}`,
+			policy: `
+				package signature
+
+				allow {
+					input.approved == true
+				}
+
+				deny[msg] {
+					input.revoked
+					msg := "revoked"
+				}
+			`,
+			pass:   false,
+			errors: []string{"result is undefined for query 'data.signature.allow'"},
+		},
+		{
+			name:     "deny rule only, without messages",
+			jsonBody: simpleJSONBody,
+			policy: `
+				package signature
+
+				deny[msg] {
+					input.predicateType != "https://slsa.dev/provenance/v0.2"
+					msg := "not slsa"
+				}
+			`,
+			pass: true,
+		},
+		{
+			name:     "deny rule only, with messages",
+			jsonBody: `{"predicateType": "https://example.com/review"}`,
+			policy: `
+				package signature
+
+				deny[msg] {
+					input.predicateType != "https://slsa.dev/provenance/v0.2"
+					msg := "not slsa"
+				}
+			`,
+			pass:   false,
+			errors: []string{"not slsa"},
+		},
+		{
+			name:     "boolean deny rule which is true",
+			jsonBody: simpleJSONBody,
+			policy: `
+				package signature
+
+				allow = true
+
+				deny {
+					input.predicateType == "https://slsa.dev/provenance/v0.2"
+				}
+			`,
+			pass:   false,
+			errors: []string{"data.signature.deny is true"},
+		},
+		{
+			name:     "boolean deny rule only, which is undefined",
+			jsonBody: simpleJSONBody,
+			policy: `
+				package signature
+
+				deny {
+					input.predicateType == "https://slsa.dev/provenance/v99.9"
+				}
+			`,
+			pass: true,
+		},
 	}
 
 	for _, tt := range cases {
@@ -213,3 +288,202 @@ func TestValidateJSONWithModuleInput(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateJSONWithOptions(t *testing.T) {
+	// Do not use t.TempDir(), see TestValidationJSON.
+	dir := "tmp-options"
+	if err := os.MkdirAll(filepath.Join(dir, "bundle", "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"policy.rego": `
+			package verify
+
+			import data.lib.provenance
+
+			default ok = false
+			ok {
+				provenance.is_slsa
+				input.predicateType == data.config.predicateTypes[_]
+			}
+
+			deny[msg] {
+				not input.builder
+				msg := "builder is missing"
+			}
+
+			deny[{"msg": "untrusted predicate type", "type": input.predicateType}] {
+				not provenance.is_slsa
+			}
+		`,
+		"config.yaml":                "predicateTypes:\n- https://slsa.dev/provenance/v0.2\n",
+		"bundle/.manifest":           `{"roots": ["lib"]}`,
+		"bundle/lib/provenance.rego": "package lib.provenance\n\nis_slsa {\n\tstartswith(input.predicateType, \"https://slsa.dev/provenance/\")\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := Options{
+		Query:   "data.verify.ok",
+		Data:    []string{"config:" + filepath.Join(dir, "config.yaml")},
+		Bundles: []string{filepath.Join(dir, "bundle")},
+	}
+	policies := []string{filepath.Join(dir, "policy.rego")}
+
+	if errs := ValidateJSONWithOptions([]byte(`{"predicateType": "https://slsa.dev/provenance/v0.2", "builder": "ci"}`), policies, opts); errs != nil {
+		t.Errorf("ValidateJSONWithOptions() = %v", errs)
+	}
+
+	errs := ValidateJSONWithOptions([]byte(simpleJSONBody), policies, opts)
+	if len(errs) != 1 || errs[0].Error() != "builder is missing" {
+		t.Fatalf("ValidateJSONWithOptions() = %v, wanted the deny message", errs)
+	}
+	var v *Violation
+	if !errors.As(errs[0], &v) {
+		t.Errorf("error %v is not a violation", errs[0])
+	}
+
+	errs = ValidateJSONWithOptions([]byte(`{"predicateType": "https://example.com/review", "builder": "ci"}`), policies, opts)
+	if len(errs) != 2 || errs[0].Error() != "untrusted predicate type" {
+		t.Fatalf("ValidateJSONWithOptions() = %v, wanted the deny message and the query failure", errs)
+	}
+	if !errors.As(errs[0], &v) || v.Details.(map[string]interface{})["type"] != "https://example.com/review" {
+		t.Errorf("violation details = %v", v.Details)
+	}
+}
+
+func TestValidateJSONWithModuleInputDeny(t *testing.T) {
+	policy := `
+		package sigstore
+
+		isCompliant = true
+
+		deny[msg] {
+			count(input.authorityMatches.keysignature.signatures) > 0
+			msg := "key signatures are not accepted"
+		}
+	`
+	err := ValidateJSONWithModuleInput([]byte(attestationsJSONBody), policy)
+	var denied *DeniedError
+	if !errors.As(err, &denied) || len(denied.Violations) != 1 {
+		t.Fatalf("ValidateJSONWithModuleInput() = %v, wanted a denial", err)
+	}
+	if got, want := err.Error(), "policy denied: key signatures are not accepted"; got != want {
+		t.Errorf("error = %q, wanted %q", got, want)
+	}
+
+	policy = `
+		package custom
+
+		allowed {
+			count(input.authorityMatches.keylessatt.attestations) == 1
+		}
+	`
+	if err := ValidateJSONWithModuleInputAndQuery([]byte(attestationsJSONBody), policy, "data.custom.allowed"); err != nil {
+		t.Errorf("ValidateJSONWithModuleInputAndQuery() = %v", err)
+	}
+	if err := ValidateJSONWithModuleInputAndQuery([]byte(attestationsJSONBody), policy, "data.custom.missing"); err == nil {
+		t.Error("ValidateJSONWithModuleInputAndQuery() succeeded with an undefined query")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	policy := `
+		package sigstore
+
+		default isCompliant = false
+
+		isCompliant {
+			trace("checking the key signatures")
+			count(input.authorityMatches.keysignature.signatures) == 2
+		}
+	`
+	for mode, want := range map[string][]string{
+		ExplainNotes: {`Note "checking the key signatures"`},
+		ExplainFails: {"sigstore.rego:8", "Fail __local0__ = 2"},
+		ExplainFull:  {`Note "checking the key signatures"`, "Exit data.sigstore.isCompliant early"},
+	} {
+		trace, err := ExplainJSONWithModuleInput([]byte(attestationsJSONBody), policy, "", mode)
+		if err != nil {
+			t.Fatalf("ExplainJSONWithModuleInput(%s) = %v", mode, err)
+		}
+		for _, w := range want {
+			if !strings.Contains(trace, w) {
+				t.Errorf("%s trace does not contain %q:\n%s", mode, w, trace)
+			}
+		}
+	}
+	if _, err := ExplainJSONWithModuleInput([]byte(attestationsJSONBody), policy, "", "everything"); err == nil {
+		t.Error("ExplainJSONWithModuleInput() succeeded with an invalid mode")
+	}
+
+	policyFileName := "tmp-explain.rego"
+	if err := os.WriteFile(policyFileName, []byte("package signature\n\nallow {\n\ttrace(\"checking the predicate type\")\n\tinput.predicateType == \"https://slsa.dev/provenance/v1\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(policyFileName)
+	errs := ValidateJSONWithOptions([]byte(simpleJSONBody), []string{policyFileName}, Options{Explain: ExplainNotes})
+	var explanation *Explanation
+	if len(errs) != 2 || !errors.As(errs[1], &explanation) || !strings.Contains(explanation.Trace, "checking the predicate type") {
+		t.Errorf("ValidateJSONWithOptions() = %v, wanted the failure and its explanation", errs)
+	}
+}
+
+func TestCompileModule(t *testing.T) {
+	cases := []struct {
+		name   string
+		module string
+		query  string
+		err    string
+	}{{
+		name:   "default query",
+		module: "package sigstore\nisCompliant { input.foo == \"bar\" }",
+	}, {
+		name:   "custom query",
+		module: "package vuln\nallow = true",
+		query:  "data.vuln.allow",
+	}, {
+		name:   "syntax error",
+		module: "package sigstore\nisCompliant { input.foo == }",
+		err:    "rego_parse_error",
+	}, {
+		name:   "type error",
+		module: "package sigstore\nisCompliant { count(1) }",
+		err:    "rego_type_error",
+	}, {
+		name:   "invalid query",
+		module: "package sigstore\nisCompliant = true",
+		query:  "data.sigstore.",
+		err:    "rego_parse_error",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CompileModule(tc.module, tc.query)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("CompileModule() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("CompileModule() = %v, want error containing %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestValidateExplainMode(t *testing.T) {
+	for _, mode := range ExplainModes {
+		if err := ValidateExplainMode(mode); err != nil {
+			t.Errorf("ValidateExplainMode(%q) = %v", mode, err)
+		}
+	}
+	for _, mode := range []string{"", "trace"} {
+		if err := ValidateExplainMode(mode); err == nil {
+			t.Errorf("ValidateExplainMode(%q) accepted an invalid mode", mode)
+		}
+	}
+}
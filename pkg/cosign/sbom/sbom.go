@@ -0,0 +1,134 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom checks that SBOM documents are well-formed before they are
+// attached to, or after they are retrieved from, an image.
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	ctypes "github.com/sigstore/cosign/pkg/types"
+)
+
+// cycloneDXNamespacePrefix is the prefix of the XML namespace of every
+// CycloneDX schema version.
+const cycloneDXNamespacePrefix = "http://cyclonedx.org/schema/bom/"
+
+// Validate checks that b is a well-formed SBOM of the given media type.
+// It only checks the fields identifying the document, it does not validate
+// the document against the full specification.
+func Validate(b []byte, mediaType types.MediaType) error {
+	switch mediaType {
+	case ctypes.SPDXJSONMediaType:
+		return validateSPDXJSON(b)
+	case ctypes.SPDXMediaType:
+		return validateSPDXTagValue(b)
+	case ctypes.CycloneDXJSONMediaType:
+		return validateCycloneDXJSON(b)
+	case ctypes.CycloneDXXMLMediaType:
+		return validateCycloneDXXML(b)
+	case ctypes.SyftMediaType:
+		if !json.Valid(b) {
+			return errors.New("invalid syft SBOM: not a JSON document")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported SBOM media type %q", mediaType)
+	}
+}
+
+func validateSPDXJSON(b []byte) error {
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		SPDXID      string `json:"SPDXID"`
+		Name        string `json:"name"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("invalid SPDX SBOM: %w", err)
+	}
+	return checkSPDX(doc.SPDXVersion, doc.SPDXID, doc.Name)
+}
+
+func validateSPDXTagValue(b []byte) error {
+	tags := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		tag := strings.TrimSpace(parts[0])
+		// The document creation information comes first, only keep
+		// the first occurrence of each tag.
+		if _, ok := tags[tag]; !ok {
+			tags[tag] = strings.TrimSpace(parts[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("invalid SPDX SBOM: %w", err)
+	}
+	return checkSPDX(tags["SPDXVersion"], tags["SPDXID"], tags["DocumentName"])
+}
+
+func checkSPDX(version, id, name string) error {
+	if !strings.HasPrefix(version, "SPDX-") {
+		return fmt.Errorf("invalid SPDX SBOM: unexpected SPDX version %q", version)
+	}
+	if id != "SPDXRef-DOCUMENT" {
+		return fmt.Errorf("invalid SPDX SBOM: unexpected document SPDXID %q, expected SPDXRef-DOCUMENT", id)
+	}
+	if name == "" {
+		return errors.New("invalid SPDX SBOM: missing document name")
+	}
+	return nil
+}
+
+func validateCycloneDXJSON(b []byte) error {
+	var doc struct {
+		BOMFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("invalid CycloneDX SBOM: %w", err)
+	}
+	if doc.BOMFormat != "CycloneDX" {
+		return fmt.Errorf("invalid CycloneDX SBOM: unexpected bomFormat %q, expected CycloneDX", doc.BOMFormat)
+	}
+	if doc.SpecVersion == "" {
+		return errors.New("invalid CycloneDX SBOM: missing specVersion")
+	}
+	return nil
+}
+
+func validateCycloneDXXML(b []byte) error {
+	var doc struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("invalid CycloneDX SBOM: %w", err)
+	}
+	if doc.XMLName.Local != "bom" || !strings.HasPrefix(doc.XMLName.Space, cycloneDXNamespacePrefix) {
+		return fmt.Errorf("invalid CycloneDX SBOM: unexpected root element {%s}%s", doc.XMLName.Space, doc.XMLName.Local)
+	}
+	return nil
+}
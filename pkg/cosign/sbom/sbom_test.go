@@ -0,0 +1,99 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	ctypes "github.com/sigstore/cosign/pkg/types"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType types.MediaType
+		doc       string
+		wantErr   bool
+	}{{
+		name:      "spdx json",
+		mediaType: ctypes.SPDXJSONMediaType,
+		doc:       `{"spdxVersion": "SPDX-2.2", "SPDXID": "SPDXRef-DOCUMENT", "name": "alpine"}`,
+	}, {
+		name:      "spdx json without name",
+		mediaType: ctypes.SPDXJSONMediaType,
+		doc:       `{"spdxVersion": "SPDX-2.2", "SPDXID": "SPDXRef-DOCUMENT"}`,
+		wantErr:   true,
+	}, {
+		name:      "spdx json with a wrong version",
+		mediaType: ctypes.SPDXJSONMediaType,
+		doc:       `{"spdxVersion": "2.2", "SPDXID": "SPDXRef-DOCUMENT", "name": "alpine"}`,
+		wantErr:   true,
+	}, {
+		name:      "spdx tag-value",
+		mediaType: ctypes.SPDXMediaType,
+		doc:       "SPDXVersion: SPDX-2.2\nDataLicense: CC0-1.0\nSPDXID: SPDXRef-DOCUMENT\nDocumentName: alpine\n\nPackageName: musl\nSPDXID: SPDXRef-Package-musl\n",
+	}, {
+		name:      "spdx tag-value with a wrong document id",
+		mediaType: ctypes.SPDXMediaType,
+		doc:       "SPDXVersion: SPDX-2.2\nSPDXID: SPDXRef-Package-musl\nDocumentName: alpine\n",
+		wantErr:   true,
+	}, {
+		name:      "spdx tag-value given json",
+		mediaType: ctypes.SPDXMediaType,
+		doc:       `{"spdxVersion": "SPDX-2.2", "SPDXID": "SPDXRef-DOCUMENT", "name": "alpine"}`,
+		wantErr:   true,
+	}, {
+		name:      "cyclonedx json",
+		mediaType: ctypes.CycloneDXJSONMediaType,
+		doc:       `{"bomFormat": "CycloneDX", "specVersion": "1.4", "components": []}`,
+	}, {
+		name:      "cyclonedx json with a wrong format",
+		mediaType: ctypes.CycloneDXJSONMediaType,
+		doc:       `{"bomFormat": "SPDX", "specVersion": "1.4"}`,
+		wantErr:   true,
+	}, {
+		name:      "cyclonedx xml",
+		mediaType: ctypes.CycloneDXXMLMediaType,
+		doc:       `<?xml version="1.0"?><bom xmlns="http://cyclonedx.org/schema/bom/1.4" version="1"><components/></bom>`,
+	}, {
+		name:      "cyclonedx xml without namespace",
+		mediaType: ctypes.CycloneDXXMLMediaType,
+		doc:       `<bom version="1"/>`,
+		wantErr:   true,
+	}, {
+		name:      "not xml",
+		mediaType: ctypes.CycloneDXXMLMediaType,
+		doc:       `{}`,
+		wantErr:   true,
+	}, {
+		name:      "syft",
+		mediaType: ctypes.SyftMediaType,
+		doc:       `{"artifacts": []}`,
+	}, {
+		name:      "unknown media type",
+		mediaType: "application/octet-stream",
+		doc:       `{}`,
+		wantErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate([]byte(tt.doc), tt.mediaType); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -35,6 +35,7 @@ import (
 
 	"github.com/sigstore/cosign/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/pkg/cosign/tuf"
+	"github.com/sigstore/cosign/pkg/retry"
 	"github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/generated/client/entries"
 	"github.com/sigstore/rekor/pkg/generated/client/index"
@@ -149,7 +150,9 @@ func TLogUploadInTotoAttestation(ctx context.Context, rekorClient *client.Rekor,
 }
 
 func doUpload(ctx context.Context, rekorClient *client.Rekor, pe models.ProposedEntry) (*models.LogEntryAnon, error) {
-	params := entries.NewCreateLogEntryParamsWithContext(ctx)
+	// Retrying an upload doesn't create a duplicate entry: Rekor answers
+	// with a conflict, which is handled below.
+	params := entries.NewCreateLogEntryParamsWithContext(retry.WithIdempotent(ctx))
 	params.SetProposedEntry(pe)
 	resp, err := rekorClient.Entries.CreateLogEntry(params)
 	if err != nil {
@@ -280,7 +283,7 @@ func proposedEntry(b64Sig string, payload, pubKey []byte) ([]models.ProposedEntr
 }
 
 func FindTlogEntry(ctx context.Context, rekorClient *client.Rekor, b64Sig string, payload, pubKey []byte) (entry *models.LogEntryAnon, err error) {
-	searchParams := entries.NewSearchLogQueryParamsWithContext(ctx)
+	searchParams := entries.NewSearchLogQueryParamsWithContext(retry.WithIdempotent(ctx))
 	searchLogQuery := models.SearchLogQuery{}
 	proposedEntry, err := proposedEntry(b64Sig, payload, pubKey)
 	if err != nil {
@@ -316,7 +319,7 @@ func FindTlogEntry(ctx context.Context, rekorClient *client.Rekor, b64Sig string
 }
 
 func FindTLogEntriesByPayload(ctx context.Context, rekorClient *client.Rekor, payload []byte) (uuids []string, err error) {
-	params := index.NewSearchIndexParamsWithContext(ctx)
+	params := index.NewSearchIndexParamsWithContext(retry.WithIdempotent(ctx))
 	params.Query = &models.SearchIndex{}
 
 	h := sha256.New()
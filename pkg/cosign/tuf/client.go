@@ -25,6 +25,7 @@ import (
 	"io"
 	"io/fs"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -38,6 +39,8 @@ import (
 	tuf_leveldbstore "github.com/theupdateframework/go-tuf/client/leveldbstore"
 	"github.com/theupdateframework/go-tuf/data"
 	"github.com/theupdateframework/go-tuf/util"
+
+	"github.com/sigstore/cosign/pkg/retry"
 )
 
 const (
@@ -615,5 +618,7 @@ func remoteFromMirror(ctx context.Context, mirror string) (client.RemoteStore, e
 	if _, parseErr := url.ParseRequestURI(mirror); parseErr != nil {
 		return GcsRemoteStore(ctx, mirror, nil, nil)
 	}
-	return client.HTTPRemoteStore(mirror, nil, nil)
+	return client.HTTPRemoteStore(mirror, nil, &http.Client{
+		Transport: retry.NewTransport(http.DefaultTransport, retry.Default()),
+	})
 }
@@ -101,6 +101,13 @@ type CheckOpts struct {
 	// to be met for the signature to ve valid.
 	// Supercedes CertEmail / CertOidcIssuer
 	Identities []Identity
+
+	// MaxAttestationAge, if set, rejects the attestations produced longer
+	// ago, see CheckAttestationAge.
+	MaxAttestationAge time.Duration
+	// OnTlogEntry, if set, is called with the attestations verified online
+	// against Rekor, along with the time they were integrated in it.
+	OnTlogEntry func(att oci.Signature, integratedTime time.Time)
 }
 
 func getSignedEntity(signedImgRef name.Reference, regClientOpts []ociremote.Option) (oci.SignedEntity, v1.Hash, error) {
@@ -311,30 +318,29 @@ func ValidateAndUnpackCertWithChain(cert *x509.Certificate, chain []*x509.Certif
 	return ValidateAndUnpackCert(cert, co)
 }
 
-func tlogValidatePublicKey(ctx context.Context, rekorClient *client.Rekor, pub crypto.PublicKey, sig oci.Signature) error {
+func tlogValidatePublicKey(ctx context.Context, rekorClient *client.Rekor, pub crypto.PublicKey, sig oci.Signature) (*models.LogEntryAnon, error) {
 	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = tlogValidateEntry(ctx, rekorClient, sig, pemBytes)
-	return err
+	return tlogValidateEntry(ctx, rekorClient, sig, pemBytes)
 }
 
-func tlogValidateCertificate(ctx context.Context, rekorClient *client.Rekor, sig oci.Signature) error {
+func tlogValidateCertificate(ctx context.Context, rekorClient *client.Rekor, sig oci.Signature) (*models.LogEntryAnon, error) {
 	cert, err := sig.Cert()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	pemBytes, err := cryptoutils.MarshalCertificateToPEM(cert)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	e, err := tlogValidateEntry(ctx, rekorClient, sig, pemBytes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// if we have a cert, we should check expiry
-	return CheckExpiry(cert, time.Unix(*e.IntegratedTime, 0))
+	return e, CheckExpiry(cert, time.Unix(*e.IntegratedTime, 0))
 }
 
 func tlogValidateEntry(ctx context.Context, client *client.Rekor, sig oci.Signature, pem []byte) (*models.LogEntryAnon, error) {
@@ -521,10 +527,12 @@ func VerifyImageSignature(ctx context.Context, sig oci.Signature, h v1.Hash, co
 			if err != nil {
 				return bundleVerified, err
 			}
-			return bundleVerified, tlogValidatePublicKey(ctx, co.RekorClient, pub, sig)
+			_, err = tlogValidatePublicKey(ctx, co.RekorClient, pub, sig)
+			return bundleVerified, err
 		}
 
-		return bundleVerified, tlogValidateCertificate(ctx, co.RekorClient, sig)
+		_, err = tlogValidateCertificate(ctx, co.RekorClient, sig)
+		return bundleVerified, err
 	}
 
 	return bundleVerified, nil
@@ -695,16 +703,32 @@ func verifyImageAttestations(ctx context.Context, atts oci.Signatures, h v1.Hash
 			}
 			bundleVerified = bundleVerified || verified
 
+			var integratedTime time.Time
 			if !verified && co.RekorClient != nil {
+				var e *models.LogEntryAnon
 				if co.SigVerifier != nil {
 					pub, err := co.SigVerifier.PublicKey(co.PKOpts...)
 					if err != nil {
 						return err
 					}
-					return tlogValidatePublicKey(ctx, co.RekorClient, pub, att)
+					e, err = tlogValidatePublicKey(ctx, co.RekorClient, pub, att)
+					if err != nil {
+						return err
+					}
+				} else {
+					e, err = tlogValidateCertificate(ctx, co.RekorClient, att)
+					if err != nil {
+						return err
+					}
 				}
+				integratedTime = time.Unix(*e.IntegratedTime, 0)
+				if co.OnTlogEntry != nil {
+					co.OnTlogEntry(att, integratedTime)
+				}
+			}
 
-				return tlogValidateCertificate(ctx, co.RekorClient, att)
+			if co.MaxAttestationAge > 0 {
+				return CheckAttestationAge(att, integratedTime, co.MaxAttestationAge)
 			}
 			return nil
 		}(att); err != nil {
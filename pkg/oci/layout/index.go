@@ -30,6 +30,9 @@ const (
 	imageIndexAnnotation = "dev.cosignproject.cosign/imageIndex"
 	sigsAnnotation       = "dev.cosignproject.cosign/sigs"
 	attsAnnotation       = "dev.cosignproject.cosign/atts"
+	attachmentAnnotation = "dev.cosignproject.cosign/attachment"
+
+	attachmentNameAnnotation = "dev.cosignproject.cosign/attachmentName"
 )
 
 // SignedImageIndex provides access to a local index reference, and its signatures.
@@ -81,9 +84,49 @@ func (i *index) Attestations() (oci.Signatures, error) {
 	return &sigs{img}, nil
 }
 
-// Attestations implements oci.SignedImage
+// Attachment implements oci.SignedImageIndex
 func (i *index) Attachment(name string) (oci.File, error) {
-	return nil, fmt.Errorf("not yet implemented")
+	manifest, err := i.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range manifest.Manifests {
+		if m.Annotations[kindAnnotation] != attachmentAnnotation || m.Annotations[attachmentNameAnnotation] != name {
+			continue
+		}
+		img, err := i.Image(m.Digest)
+		if err != nil {
+			return nil, err
+		}
+		ls, err := img.Layers()
+		if err != nil {
+			return nil, err
+		}
+		if len(ls) != 1 {
+			return nil, fmt.Errorf("expected exactly one layer in attachment, got %d", len(ls))
+		}
+		return &attached{
+			SignedImage: signed.Image(img),
+			layer:       ls[0],
+		}, nil
+	}
+	return nil, fmt.Errorf("attachment %q not found", name)
+}
+
+// AttachmentNames returns the names of the attachments stored alongside the
+// signed entity.
+func (i *index) AttachmentNames() ([]string, error) {
+	manifest, err := i.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, m := range manifest.Manifests {
+		if m.Annotations[kindAnnotation] == attachmentAnnotation {
+			names = append(names, m.Annotations[attachmentNameAnnotation])
+		}
+	}
+	return names, nil
 }
 
 // SignedImage implements oci.SignedImageIndex
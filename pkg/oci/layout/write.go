@@ -24,8 +24,8 @@ import (
 	"github.com/sigstore/cosign/pkg/oci"
 )
 
-// WriteSignedImage writes the image and all related signatures, attestations and attachments
-func WriteSignedImage(path string, si oci.SignedImage) error {
+// WriteSignedImage writes the image and all related signatures, attestations and the named attachments
+func WriteSignedImage(path string, si oci.SignedImage, attachments ...string) error {
 	// First, write an empty index
 	layoutPath, err := layout.Write(path, empty.Index)
 	if err != nil {
@@ -35,11 +35,11 @@ func WriteSignedImage(path string, si oci.SignedImage) error {
 	if err := appendImage(layoutPath, si, imageAnnotation); err != nil {
 		return fmt.Errorf("appending signed image: %w", err)
 	}
-	return writeSignedEntity(layoutPath, si)
+	return writeSignedEntity(layoutPath, si, attachments)
 }
 
-// WriteSignedImageIndex writes the image index and all related signatures, attestations and attachments
-func WriteSignedImageIndex(path string, si oci.SignedImageIndex) error {
+// WriteSignedImageIndex writes the image index and all related signatures, attestations and the named attachments
+func WriteSignedImageIndex(path string, si oci.SignedImageIndex, attachments ...string) error {
 	// First, write an empty index
 	layoutPath, err := layout.Write(path, empty.Index)
 	if err != nil {
@@ -51,10 +51,10 @@ func WriteSignedImageIndex(path string, si oci.SignedImageIndex) error {
 	)); err != nil {
 		return fmt.Errorf("appending signed image index: %w", err)
 	}
-	return writeSignedEntity(layoutPath, si)
+	return writeSignedEntity(layoutPath, si, attachments)
 }
 
-func writeSignedEntity(path layout.Path, se oci.SignedEntity) error {
+func writeSignedEntity(path layout.Path, se oci.SignedEntity, attachments []string) error {
 	// write the signatures
 	sigs, err := se.Signatures()
 	if err != nil {
@@ -76,7 +76,20 @@ func writeSignedEntity(path layout.Path, se oci.SignedEntity) error {
 			return fmt.Errorf("appending atts: %w", err)
 		}
 	}
-	// TODO (priyawadhwa@) and attachments
+
+	// write the attachments
+	for _, name := range attachments {
+		f, err := se.Attachment(name)
+		if err != nil {
+			return fmt.Errorf("getting attachment %s: %w", name, err)
+		}
+		if err := path.AppendImage(f, layout.WithAnnotations(map[string]string{
+			kindAnnotation:           attachmentAnnotation,
+			attachmentNameAnnotation: name,
+		})); err != nil {
+			return fmt.Errorf("appending attachment %s: %w", name, err)
+		}
+	}
 	return nil
 }
 
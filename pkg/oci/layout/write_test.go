@@ -94,6 +94,61 @@ func TestReadWrite(t *testing.T) {
 	}
 }
 
+func TestReadWriteAttachments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test is flaky on windows, see https://github.com/sigstore/cosign/issues/1389")
+	}
+	payload := "these are the release notes"
+	f, err := static.NewFile([]byte(payload), static.WithLayerMediaType("text/markdown"))
+	if err != nil {
+		t.Fatalf("static.NewFile() = %v", err)
+	}
+	si, err := mutate.AttachFileToImage(randomSignedImage(t), "release-notes", f)
+	if err != nil {
+		t.Fatalf("AttachFileToImage() = %v", err)
+	}
+
+	tmp := t.TempDir()
+	if err := WriteSignedImage(tmp, si, "release-notes"); err != nil {
+		t.Fatal(err)
+	}
+
+	imageIndex, err := SignedImageIndex(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err := imageIndex.(interface{ AttachmentNames() ([]string, error) }).AttachmentNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff([]string{"release-notes"}, names); d != "" {
+		t.Fatalf("AttachmentNames() diff: %s", d)
+	}
+
+	got, err := imageIndex.Attachment("release-notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, err := got.FileMediaType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt != "text/markdown" {
+		t.Errorf("FileMediaType() = %s, wanted text/markdown", mt)
+	}
+	b, err := got.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != payload {
+		t.Errorf("Payload() = %s, wanted %s", b, payload)
+	}
+
+	if _, err := imageIndex.Attachment("missing"); err == nil {
+		t.Error("Attachment(missing) succeeded, wanted error")
+	}
+}
+
 func randomSignedImage(t *testing.T) oci.SignedImage {
 	i, err := random.Image(300 /* byteSize */, 7 /* layers */)
 	if err != nil {
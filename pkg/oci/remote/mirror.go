@@ -0,0 +1,108 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// MirrorMappingEnvKey is the environment variable holding the mirror mapping,
+// in the format parsed by ParseMirrorMapping.
+const MirrorMappingEnvKey = "COSIGN_MIRROR_MAPPING"
+
+// MirrorRule rewrites the repositories starting with Prefix, so that the
+// Prefix is replaced with Replacement.
+type MirrorRule struct {
+	Prefix      string
+	Replacement string
+}
+
+// MirrorMapping maps the repository an image is pulled from, such as a
+// pull-through mirror, to the repository its signatures, attestations and
+// other attachments are stored in, such as the upstream repository.
+type MirrorMapping []MirrorRule
+
+// ParseMirrorMapping parses comma separated rules of the form
+// `prefix=replacement`, such as `mirror.corp/dockerhub=index.docker.io`.
+func ParseMirrorMapping(s string) (MirrorMapping, error) {
+	var m MirrorMapping
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid mirror mapping rule %q, expected prefix=replacement", rule)
+		}
+		r := MirrorRule{
+			Prefix:      strings.TrimSuffix(parts[0], "/"),
+			Replacement: strings.TrimSuffix(parts[1], "/"),
+		}
+		// Repositories are matched by their full name, which names
+		// Docker Hub index.docker.io.
+		if r.Prefix == "docker.io" || strings.HasPrefix(r.Prefix, "docker.io/") {
+			r.Prefix = "index." + r.Prefix
+		}
+		// The replacement must be a repository, or the start of one.
+		if _, err := name.NewRepository(r.Replacement + "/xx"); err != nil {
+			return nil, fmt.Errorf("invalid mirror mapping rule %q: %w", rule, err)
+		}
+		m = append(m, r)
+	}
+	return m, nil
+}
+
+// GetEnvMirrorMapping returns the MirrorMapping specified by
+// `os.Getenv(MirrorMappingEnvKey)`, or nil if not set.
+// Returns an error if the value is set but cannot be parsed.
+func GetEnvMirrorMapping() (MirrorMapping, error) {
+	m, err := ParseMirrorMapping(os.Getenv(MirrorMappingEnvKey))
+	if err != nil {
+		return nil, fmt.Errorf("parsing $"+MirrorMappingEnvKey+": %w", err)
+	}
+	return m, nil
+}
+
+// Map returns the repository repo is mapped to by the rule with the longest
+// matching prefix. A prefix matches whole path components only, so
+// `mirror.corp/docker` does not match `mirror.corp/dockerhub/nginx`.
+// It returns repo itself when no rule matches.
+func (m MirrorMapping) Map(repo name.Repository) name.Repository {
+	full := repo.Name()
+	var best *MirrorRule
+	for i, rule := range m {
+		if full != rule.Prefix && !strings.HasPrefix(full, rule.Prefix+"/") {
+			continue
+		}
+		if best == nil || len(rule.Prefix) > len(best.Prefix) {
+			best = &m[i]
+		}
+	}
+	if best == nil {
+		return repo
+	}
+	mapped, err := name.NewRepository(best.Replacement + strings.TrimPrefix(full, best.Prefix))
+	if err != nil {
+		// The replacement was validated by ParseMirrorMapping and the rest
+		// comes from a valid repository, this is not expected.
+		return repo
+	}
+	return mapped
+}
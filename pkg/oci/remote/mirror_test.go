@@ -0,0 +1,113 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func TestParseMirrorMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    MirrorMapping
+		wantErr bool
+	}{{
+		name: "empty",
+		in:   "",
+	}, {
+		name: "rules",
+		in:   "mirror.corp/dockerhub=index.docker.io, mirror.corp/gcr/=gcr.io/",
+		want: MirrorMapping{
+			{Prefix: "mirror.corp/dockerhub", Replacement: "index.docker.io"},
+			{Prefix: "mirror.corp/gcr", Replacement: "gcr.io"},
+		},
+	}, {
+		name: "docker hub prefix",
+		in:   "docker.io/library=mirror.corp/library",
+		want: MirrorMapping{
+			{Prefix: "index.docker.io/library", Replacement: "mirror.corp/library"},
+		},
+	}, {
+		name:    "missing replacement",
+		in:      "mirror.corp/dockerhub=",
+		wantErr: true,
+	}, {
+		name:    "not a rule",
+		in:      "mirror.corp/dockerhub",
+		wantErr: true,
+	}, {
+		name:    "invalid replacement",
+		in:      "mirror.corp/dockerhub=Not A Repo",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMirrorMapping(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMirrorMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMirrorMapping() = %#v, wanted %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirrorMappingMap(t *testing.T) {
+	m, err := ParseMirrorMapping("mirror.corp/dockerhub=index.docker.io,mirror.corp/dockerhub/library/nginx=sigs.corp/nginx,mirror.corp/gcr=gcr.io")
+	if err != nil {
+		t.Fatalf("ParseMirrorMapping() = %v", err)
+	}
+	tests := map[string]string{
+		"mirror.corp/dockerhub/library/alpine": "index.docker.io/library/alpine",
+		"mirror.corp/dockerhub/library/nginx":  "sigs.corp/nginx",
+		"mirror.corp/gcr/distroless/static":    "gcr.io/distroless/static",
+		"mirror.corp/gcrx/distroless/static":   "mirror.corp/gcrx/distroless/static",
+		"ghcr.io/sigstore/cosign":              "ghcr.io/sigstore/cosign",
+	}
+	for in, want := range tests {
+		repo, err := name.NewRepository(in)
+		if err != nil {
+			t.Fatalf("NewRepository() = %v", err)
+		}
+		if got := m.Map(repo); got.Name() != want {
+			t.Errorf("Map(%s) = %s, wanted %s", in, got, want)
+		}
+	}
+}
+
+func TestGetEnvMirrorMapping(t *testing.T) {
+	ev := os.Getenv(MirrorMappingEnvKey)
+	defer os.Setenv(MirrorMappingEnvKey, ev)
+
+	os.Setenv(MirrorMappingEnvKey, "mirror.corp/gcr=gcr.io")
+	got, err := GetEnvMirrorMapping()
+	if err != nil {
+		t.Fatalf("GetEnvMirrorMapping() = %v", err)
+	}
+	if want := (MirrorMapping{{Prefix: "mirror.corp/gcr", Replacement: "gcr.io"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetEnvMirrorMapping() = %#v, wanted %#v", got, want)
+	}
+
+	os.Setenv(MirrorMappingEnvKey, "mirror.corp/gcr")
+	if _, err := GetEnvMirrorMapping(); err == nil {
+		t.Error("GetEnvMirrorMapping() = nil, wanted an error")
+	}
+}
@@ -42,6 +42,7 @@ type options struct {
 	SBOMSuffix        string
 	TagPrefix         string
 	TargetRepository  name.Repository
+	Mirrors           MirrorMapping
 	ROpt              []remote.Option
 
 	OriginalOptions []Option
@@ -70,6 +71,11 @@ func makeOptions(target name.Repository, opts ...Option) *options {
 		option(o)
 	}
 
+	// An explicit target repository takes precedence over the mirrors.
+	if len(o.Mirrors) > 0 && o.TargetRepository == target {
+		o.TargetRepository = o.Mirrors.Map(target)
+	}
+
 	return o
 }
 
@@ -121,6 +127,16 @@ func WithTargetRepository(repo name.Repository) Option {
 	}
 }
 
+// WithMirrorMapping is a functional option mapping the repository of the
+// image to the target repository hosting its signature and attestation tags,
+// for images pulled through a mirror. It is ignored when the target
+// repository is overridden with WithTargetRepository.
+func WithMirrorMapping(m MirrorMapping) Option {
+	return func(o *options) {
+		o.Mirrors = m
+	}
+}
+
 // GetEnvTargetRepository returns the Repository specified by
 // `os.Getenv(RepoOverrideEnvKey)`, or the empty value if not set.
 // Returns an error if the value is set but cannot be parsed.
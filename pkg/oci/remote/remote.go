@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -35,6 +36,7 @@ var (
 	remoteIndex = remote.Index
 	remoteGet   = remote.Get
 	remoteWrite = remote.Write
+	remoteList  = remote.List
 )
 
 // SignedEntity provides access to a remote reference, and its signatures.
@@ -104,6 +106,75 @@ func SBOMTag(ref name.Reference, opts ...Option) (name.Tag, error) {
 	return suffixTag(ref, o.SBOMSuffix, o)
 }
 
+// AttachmentTag returns the name.Tag that associated the named attachment with a particular digest.
+func AttachmentTag(ref name.Reference, attName string, opts ...Option) (name.Tag, error) {
+	o := makeOptions(ref.Context(), opts...)
+	return suffixTag(ref, attName, o)
+}
+
+// AttachmentNames returns the names of the attachments (including signatures,
+// attestations and SBOMs) associated with a particular digest. As there is no
+// other way to enumerate them, this lists the tags of the repository holding
+// the attachments.
+func AttachmentNames(ref name.Reference, opts ...Option) ([]string, error) {
+	return NewAttachmentLister(opts...).Names(ref)
+}
+
+// AttachmentLister returns the names of the attachments of several images,
+// listing the tags of each repository holding attachments only once.
+type AttachmentLister struct {
+	opts []Option
+	tags map[string]listedTags
+}
+
+type listedTags struct {
+	tags []string
+	err  error
+}
+
+// NewAttachmentLister returns an AttachmentLister listing the repositories
+// with opts.
+func NewAttachmentLister(opts ...Option) *AttachmentLister {
+	return &AttachmentLister{
+		opts: opts,
+		tags: map[string]listedTags{},
+	}
+}
+
+// Names returns the names of the attachments (including signatures,
+// attestations and SBOMs) associated with a particular digest, as
+// AttachmentNames does.
+func (l *AttachmentLister) Names(ref name.Reference) ([]string, error) {
+	o := makeOptions(ref.Context(), l.opts...)
+	base, err := suffixTag(ref, "", o)
+	if err != nil {
+		return nil, err
+	}
+	repo := o.TargetRepository.String()
+	listed, ok := l.tags[repo]
+	if !ok {
+		listed.tags, listed.err = remoteList(o.TargetRepository, o.ROpt...)
+		var te *transport.Error
+		if errors.As(listed.err, &te) && te.StatusCode == http.StatusNotFound {
+			// The repository holding the attachments does not exist (yet).
+			listed.err = nil
+		}
+		l.tags[repo] = listed
+	}
+	if listed.err != nil {
+		return nil, listed.err
+	}
+
+	prefix := base.TagStr() + "."
+	var names []string
+	for _, tag := range listed.tags {
+		if strings.HasPrefix(tag, prefix) && len(tag) > len(prefix) {
+			names = append(names, strings.TrimPrefix(tag, prefix))
+		}
+	}
+	return names, nil
+}
+
 func suffixTag(ref name.Reference, suffix string, o *options) (name.Tag, error) {
 	var h v1.Hash
 	if digest, ok := ref.(name.Digest); ok {
@@ -96,6 +96,42 @@ func TestTagMethods(t *testing.T) {
 		fn:   SBOMTag,
 		ref:  name.MustParseReference("gcr.io/distroless/static@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4"),
 		want: name.MustParseReference("gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.sbom"),
+	}, {
+		name: "attachment passed a tag",
+		fn: func(ref name.Reference, opts ...Option) (name.Tag, error) {
+			return AttachmentTag(ref, "release-notes", opts...)
+		},
+		ref:  name.MustParseReference("gcr.io/distroless/static:nonroot"),
+		want: name.MustParseReference("gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.release-notes"),
+	}, {
+		name: "attachment passed a digest (w/ custom prefix)",
+		fn: func(ref name.Reference, opts ...Option) (name.Tag, error) {
+			return AttachmentTag(ref, "release-notes", opts...)
+		},
+		ref:  name.MustParseReference("gcr.io/distroless/static@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4"),
+		opts: []Option{WithPrefix("custom-")},
+		want: name.MustParseReference("gcr.io/distroless/static:custom-sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.release-notes"),
+	}, {
+		name: "signature passed a mirrored tag",
+		fn:   SignatureTag,
+		ref:  name.MustParseReference("mirror.corp/gcr/distroless/static:nonroot"),
+		opts: []Option{WithMirrorMapping(MirrorMapping{{Prefix: "mirror.corp/gcr", Replacement: "gcr.io"}})},
+		want: name.MustParseReference("gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.sig"),
+	}, {
+		name: "attestation passed a mirrored digest",
+		fn:   AttestationTag,
+		ref:  name.MustParseReference("mirror.corp/gcr/distroless/static@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4"),
+		opts: []Option{WithMirrorMapping(MirrorMapping{{Prefix: "mirror.corp/gcr", Replacement: "gcr.io"}})},
+		want: name.MustParseReference("gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.att"),
+	}, {
+		name: "signature passed a mirrored tag (w/ target repository)",
+		fn:   SignatureTag,
+		ref:  name.MustParseReference("mirror.corp/gcr/distroless/static:nonroot"),
+		opts: []Option{
+			WithMirrorMapping(MirrorMapping{{Prefix: "mirror.corp/gcr", Replacement: "gcr.io"}}),
+			WithTargetRepository(name.MustParseReference("sigs.corp/static").Context()),
+		},
+		want: name.MustParseReference("sigs.corp/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.sig"),
 	}}
 
 	for _, test := range tests {
@@ -147,3 +183,68 @@ func TestTagMethodErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachmentNames(t *testing.T) {
+	rl := remoteList
+	defer func() {
+		remoteList = rl
+	}()
+	remoteList = func(repo name.Repository, options ...remote.Option) ([]string, error) {
+		if repo.String() != "gcr.io/distroless/static" {
+			t.Errorf("remoteList(%s), wanted gcr.io/distroless/static", repo)
+		}
+		return []string{
+			"nonroot",
+			"sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4",
+			"sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.sig",
+			"sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.release-notes",
+			"sha256-0000000000000000000000000000000000000000000000000000000000000000.sig",
+		}, nil
+	}
+
+	ref := name.MustParseReference("gcr.io/distroless/static@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4")
+	got, err := AttachmentNames(ref)
+	if err != nil {
+		t.Fatalf("AttachmentNames() = %v", err)
+	}
+	want := []string{"sig", "release-notes"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("AttachmentNames() = %v, wanted %v", got, want)
+	}
+}
+
+func TestAttachmentListerListsOnce(t *testing.T) {
+	rl := remoteList
+	defer func() {
+		remoteList = rl
+	}()
+	calls := 0
+	remoteList = func(repo name.Repository, options ...remote.Option) ([]string, error) {
+		calls++
+		return []string{
+			"sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.sbom",
+			"sha256-0000000000000000000000000000000000000000000000000000000000000000.release-notes",
+		}, nil
+	}
+
+	l := NewAttachmentLister()
+	for digest, want := range map[string]string{
+		"sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4": "sbom",
+		"sha256:0000000000000000000000000000000000000000000000000000000000000000": "release-notes",
+	} {
+		ref, err := name.NewDigest("gcr.io/distroless/static@" + digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := l.Names(ref)
+		if err != nil {
+			t.Fatalf("Names(%s) = %v", digest, err)
+		}
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("Names(%s) = %v, wanted [%s]", digest, got, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("listed the tags %d times, wanted once", calls)
+	}
+}
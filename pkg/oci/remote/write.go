@@ -27,7 +27,6 @@ import (
 // WriteSignedImageIndexImages writes the images within the image index
 // This includes the signed image and associated signatures in the image index
 // TODO (priyawadhwa@): write the `index.json` itself to the repo as well
-// TODO (priyawadhwa@): write the attestations
 func WriteSignedImageIndexImages(ref name.Reference, sii oci.SignedImageIndex, opts ...Option) error {
 	repo := ref.Context()
 	o := makeOptions(repo, opts...)
@@ -79,7 +78,32 @@ func WriteSignedImageIndexImages(ref name.Reference, sii oci.SignedImageIndex, o
 		if err != nil {
 			return fmt.Errorf("sigs tag: %w", err)
 		}
-		return remoteWrite(attsTag, atts, o.ROpt...)
+		if err := remoteWrite(attsTag, atts, o.ROpt...); err != nil {
+			return err
+		}
+	}
+
+	// write the attachments, if the index is able to enumerate them
+	lister, ok := sii.(interface{ AttachmentNames() ([]string, error) })
+	if !ok {
+		return nil
+	}
+	names, err := lister.AttachmentNames()
+	if err != nil {
+		return err
+	}
+	for _, attName := range names {
+		f, err := sii.Attachment(attName)
+		if err != nil {
+			return fmt.Errorf("attachment %s: %w", attName, err)
+		}
+		attTag, err := AttachmentTag(ref, attName, opts...)
+		if err != nil {
+			return fmt.Errorf("attachment %s tag: %w", attName, err)
+		}
+		if err := remoteWrite(attTag, f, o.ROpt...); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -0,0 +1,271 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package walk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+)
+
+// DefaultJobs is the number of entities visited at the same time when
+// WithJobs is not used.
+const DefaultJobs = 4
+
+// CollectFn is the signature of the callback supplied to Collect.
+// Like Fn, it is called on an oci.SignedImageIndex *before* its children,
+// and may return mutate.ErrSkipChildren to skip them.
+type CollectFn func(context.Context, oci.SignedEntity) (interface{}, error)
+
+// Option is a functional option for the concurrent walkers.
+type Option func(*options)
+
+type options struct {
+	jobs            int
+	continueOnError bool
+}
+
+// WithJobs sets the maximum number of entities visited at the same time.
+func WithJobs(jobs int) Option {
+	return func(o *options) {
+		if jobs > 0 {
+			o.jobs = jobs
+		}
+	}
+}
+
+// WithContinueOnError keeps walking the other entities when the callback
+// fails on one of them, the failures are then returned together as Errors.
+// By default the walk stops on the first failure.
+func WithContinueOnError() Option {
+	return func(o *options) {
+		o.continueOnError = true
+	}
+}
+
+// EntityError is the error returned by the callback for a single entity.
+type EntityError struct {
+	Digest v1.Hash
+	Err    error
+}
+
+// Error implements error
+func (e *EntityError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Digest, e.Err)
+}
+
+// Unwrap returns the error returned by the callback.
+func (e *EntityError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is returned when WithContinueOnError is used and the callback failed
+// on some entities. They are in the order SignedEntity would visit the entities.
+type Errors []*EntityError
+
+// Error implements error
+func (es Errors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Sprintf("%d error(s) occurred: %s", len(es), strings.Join(msgs, "; "))
+}
+
+// SignedEntityConcurrently is like SignedEntity, but visits the children of
+// an index concurrently. The callback is still called on an index before its
+// children, but siblings are visited in no particular order so `fn` must be
+// safe for concurrent use.
+func SignedEntityConcurrently(ctx context.Context, parent oci.SignedEntity, fn Fn, opts ...Option) error {
+	_, err := Collect(ctx, parent, func(ctx context.Context, se oci.SignedEntity) (interface{}, error) {
+		return nil, fn(ctx, se)
+	}, opts...)
+	return err
+}
+
+// Collect concurrently calls `fn` on the signed entity and each of its
+// constituent entities transitively, and returns the values it returned in
+// the order SignedEntity would visit the entities, whatever the order they
+// were actually visited in. The values of the entities `fn` failed on are
+// left out.
+func Collect(ctx context.Context, parent oci.SignedEntity, fn CollectFn, opts ...Option) ([]interface{}, error) {
+	o := &options{jobs: DefaultJobs}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := &walker{
+		opts:   o,
+		fn:     fn,
+		sem:    make(chan struct{}, o.jobs),
+		cancel: cancel,
+	}
+	w.wg.Add(1)
+	go w.visit(ctx, parent, nil)
+	w.wg.Wait()
+
+	sort.Slice(w.results, func(i, j int) bool { return less(w.results[i].path, w.results[j].path) })
+	values := make([]interface{}, 0, len(w.results))
+	for _, r := range w.results {
+		values = append(values, r.value)
+	}
+
+	if w.firstErr != nil && !o.continueOnError {
+		return nil, w.firstErr
+	}
+	if len(w.errs) > 0 {
+		sort.Slice(w.errs, func(i, j int) bool { return less(w.errs[i].path, w.errs[j].path) })
+		errs := make(Errors, 0, len(w.errs))
+		for _, e := range w.errs {
+			errs = append(errs, e.err)
+		}
+		return values, errs
+	}
+	return values, nil
+}
+
+type walker struct {
+	opts   *options
+	fn     CollectFn
+	sem    chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	results  []result
+	errs     []pathError
+	firstErr error
+}
+
+// Entities are identified by their path: the position of each of their
+// ancestors in the manifests of their parent, and then their own.
+type result struct {
+	path  []int
+	value interface{}
+}
+
+type pathError struct {
+	path []int
+	err  *EntityError
+}
+
+func (w *walker) visit(ctx context.Context, se oci.SignedEntity, path []int) {
+	defer w.wg.Done()
+
+	if err := ctx.Err(); err != nil {
+		w.fail(se, path, err)
+		return
+	}
+	// Only hold a slot while calling fn: children are visited by other
+	// goroutines, which could never start if their parent held on to it.
+	select {
+	case w.sem <- struct{}{}:
+	case <-ctx.Done():
+		w.fail(se, path, ctx.Err())
+		return
+	}
+	value, err := w.fn(ctx, se)
+	<-w.sem
+
+	switch {
+	case errors.Is(err, mutate.ErrSkipChildren):
+		w.succeed(path, value)
+		return
+	case err != nil:
+		w.fail(se, path, err)
+		return
+	}
+	w.succeed(path, value)
+
+	sii, ok := se.(oci.SignedImageIndex)
+	if !ok {
+		return
+	}
+	im, err := sii.IndexManifest()
+	if err != nil {
+		w.fail(se, path, err)
+		return
+	}
+	for i, desc := range im.Manifests {
+		var child oci.SignedEntity
+		switch desc.MediaType {
+		case types.OCIImageIndex, types.DockerManifestList:
+			child, err = sii.SignedImageIndex(desc.Digest)
+		case types.OCIManifestSchema1, types.DockerManifestSchema2:
+			child, err = sii.SignedImage(desc.Digest)
+		default:
+			err = fmt.Errorf("unknown mime type: %v", desc.MediaType)
+		}
+		childPath := append(path[:len(path):len(path)], i)
+		if err != nil {
+			w.failDigest(desc.Digest, childPath, err)
+			continue
+		}
+		w.wg.Add(1)
+		go w.visit(ctx, child, childPath)
+	}
+}
+
+func (w *walker) succeed(path []int, value interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.results = append(w.results, result{path: path, value: value})
+}
+
+func (w *walker) fail(se oci.SignedEntity, path []int, err error) {
+	var h v1.Hash
+	if d, ok := se.(interface{ Digest() (v1.Hash, error) }); ok {
+		h, _ = d.Digest()
+	}
+	w.failDigest(h, path, err)
+}
+
+func (w *walker) failDigest(h v1.Hash, path []int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ee := &EntityError{Digest: h, Err: err}
+	if w.opts.continueOnError {
+		w.errs = append(w.errs, pathError{path: path, err: ee})
+		return
+	}
+	// The other entities fail with context.Canceled once we stop the
+	// walk, only report what stopped it.
+	if w.firstErr == nil {
+		w.firstErr = ee
+		w.cancel()
+	}
+}
+
+// less orders paths the way SignedEntity visits entities: depth-first,
+// a parent before its children.
+func less(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package walk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	omutate "github.com/sigstore/cosign/pkg/oci/mutate"
+	"github.com/sigstore/cosign/pkg/oci/signed"
+)
+
+// nestedIndex returns an index of 2 images and of an index of 2 images.
+func nestedIndex(t *testing.T) oci.SignedImageIndex {
+	t.Helper()
+	ii, err := random.Index(300 /* bytes */, 3 /* layers */, 2 /* images */)
+	if err != nil {
+		t.Fatalf("random.Index() = %v", err)
+	}
+	ii2, err := random.Index(300 /* bytes */, 3 /* layers */, 2 /* images */)
+	if err != nil {
+		t.Fatalf("random.Index() = %v", err)
+	}
+	return signed.ImageIndex(mutate.AppendManifests(ii, mutate.IndexAddendum{
+		Add: ii2,
+	}))
+}
+
+func digestOf(t *testing.T, se oci.SignedEntity) v1.Hash {
+	t.Helper()
+	h, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
+	if err != nil {
+		t.Fatalf("Digest() = %v", err)
+	}
+	return h
+}
+
+func TestCollectOrder(t *testing.T) {
+	sii := nestedIndex(t)
+
+	// The order of the sequential walk is the reference.
+	var want []v1.Hash
+	if err := SignedEntity(context.Background(), sii, func(ctx context.Context, se oci.SignedEntity) error {
+		want = append(want, digestOf(t, se))
+		return nil
+	}); err != nil {
+		t.Fatalf("SignedEntity() = %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		got, err := Collect(context.Background(), sii, func(ctx context.Context, se oci.SignedEntity) (interface{}, error) {
+			return digestOf(t, se), nil
+		}, WithJobs(3))
+		if err != nil {
+			t.Fatalf("Collect() = %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Collect() returned %d values, wanted %d", len(got), len(want))
+		}
+		for j := range want {
+			if got[j].(v1.Hash) != want[j] {
+				t.Fatalf("Collect()[%d] = %v, wanted %v", j, got[j], want[j])
+			}
+		}
+	}
+}
+
+func TestSignedEntityConcurrentlyJobs(t *testing.T) {
+	sii := nestedIndex(t)
+
+	var mu sync.Mutex
+	running, max, calls := 0, 0, 0
+	err := SignedEntityConcurrently(context.Background(), sii, func(ctx context.Context, se oci.SignedEntity) error {
+		mu.Lock()
+		calls++
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}, WithJobs(2))
+	if err != nil {
+		t.Fatalf("SignedEntityConcurrently() = %v", err)
+	}
+	if calls != 6 {
+		t.Errorf("fn called %d times, wanted 6", calls)
+	}
+	if max > 2 {
+		t.Errorf("%d calls ran at the same time, wanted at most 2", max)
+	}
+}
+
+func TestSignedEntityConcurrentlySkipChildren(t *testing.T) {
+	sii := nestedIndex(t)
+
+	calls := 0
+	err := SignedEntityConcurrently(context.Background(), sii, func(ctx context.Context, se oci.SignedEntity) error {
+		calls++
+		return omutate.ErrSkipChildren
+	})
+	if err != nil {
+		t.Fatalf("SignedEntityConcurrently() = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times, wanted 1", calls)
+	}
+}
+
+func TestSignedEntityConcurrentlyErrors(t *testing.T) {
+	sii := nestedIndex(t)
+	want := errors.New("this is the error I expect")
+	failImages := func(ctx context.Context, se oci.SignedEntity) error {
+		if _, ok := se.(oci.SignedImageIndex); ok {
+			return nil
+		}
+		return want
+	}
+
+	t.Run("stops on the first error", func(t *testing.T) {
+		err := SignedEntityConcurrently(context.Background(), sii, failImages)
+		if !errors.Is(err, want) {
+			t.Fatalf("SignedEntityConcurrently() = %v, wanted %v", err, want)
+		}
+		var ee *EntityError
+		if !errors.As(err, &ee) {
+			t.Fatalf("SignedEntityConcurrently() = %T, wanted *EntityError", err)
+		}
+	})
+
+	t.Run("aggregates errors", func(t *testing.T) {
+		var images []v1.Hash
+		if err := SignedEntity(context.Background(), sii, func(ctx context.Context, se oci.SignedEntity) error {
+			if _, ok := se.(oci.SignedImage); ok {
+				images = append(images, digestOf(t, se))
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("SignedEntity() = %v", err)
+		}
+
+		err := SignedEntityConcurrently(context.Background(), sii, failImages, WithContinueOnError())
+		var errs Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("SignedEntityConcurrently() = %v, wanted Errors", err)
+		}
+		if len(errs) != len(images) {
+			t.Fatalf("got %d errors, wanted %d", len(errs), len(images))
+		}
+		for i, e := range errs {
+			if e.Digest != images[i] || !errors.Is(e, want) {
+				t.Errorf("errs[%d] = %v, wanted %v: %v", i, e, images[i], want)
+			}
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := SignedEntityConcurrently(ctx, sii, func(ctx context.Context, se oci.SignedEntity) error {
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("SignedEntityConcurrently() = %v, wanted %v", err, context.Canceled)
+		}
+	})
+}
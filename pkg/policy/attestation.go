@@ -104,6 +104,15 @@ func AttestationToPayloadJSON(ctx context.Context, predicateType string, verifie
 		if err != nil {
 			return nil, fmt.Errorf("marshaling ProvenanceStatement: %w", err)
 		}
+	case options.PredicateSLSA1:
+		var slsaProvenanceStatement attestation.SLSAProvenanceV1Statement
+		if err := json.Unmarshal(decodedPayload, &slsaProvenanceStatement); err != nil {
+			return nil, fmt.Errorf("unmarshaling SLSAProvenanceV1Statement: %w", err)
+		}
+		payload, err = json.Marshal(slsaProvenanceStatement)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling SLSAProvenanceV1Statement: %w", err)
+		}
 	case options.PredicateSPDX:
 		var spdxStatement in_toto.SPDXStatement
 		if err := json.Unmarshal(decodedPayload, &spdxStatement); err != nil {
@@ -122,6 +131,24 @@ func AttestationToPayloadJSON(ctx context.Context, predicateType string, verifie
 		if err != nil {
 			return nil, fmt.Errorf("marshaling CosignVulnStatement: %w", err)
 		}
+	case options.PredicateOpenVEX:
+		var vexStatement attestation.CosignOpenVEXStatement
+		if err := json.Unmarshal(decodedPayload, &vexStatement); err != nil {
+			return nil, fmt.Errorf("unmarshaling CosignOpenVEXStatement: %w", err)
+		}
+		payload, err = json.Marshal(vexStatement)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling CosignOpenVEXStatement: %w", err)
+		}
+	case options.PredicateCycloneDX:
+		var cdxStatement attestation.CycloneDXStatement
+		if err := json.Unmarshal(decodedPayload, &cdxStatement); err != nil {
+			return nil, fmt.Errorf("unmarshaling CycloneDXStatement: %w", err)
+		}
+		payload, err = json.Marshal(cdxStatement)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling CycloneDXStatement: %w", err)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported predicate type: %s", predicateType)
 	}
@@ -153,6 +153,30 @@ func TestAttestationToPayloadJson(t *testing.T) {
 				t.Fatal("Wanted vuln statement, can't unmarshal to it: ", err)
 			}
 			checkPredicateType(t, attestation.CosignVulnProvenanceV01, vulnStatement.PredicateType)
+		case "slsaprovenance1":
+			var slsaStatement attestation.SLSAProvenanceV1Statement
+			if err := json.Unmarshal(jsonBytes, &slsaStatement); err != nil {
+				t.Fatal("Wanted SLSA v1 provenance statement, can't unmarshal to it: ", err)
+			}
+			checkPredicateType(t, attestation.PredicateSLSAProvenanceV1, slsaStatement.PredicateType)
+			if slsaStatement.Predicate.RunDetails.Builder.ID == "" {
+				t.Error("SLSA v1 provenance statement is missing its builder")
+			}
+		case "openvex":
+			var vexStatement attestation.CosignOpenVEXStatement
+			if err := json.Unmarshal(jsonBytes, &vexStatement); err != nil {
+				t.Fatal("Wanted OpenVEX statement, can't unmarshal to it: ", err)
+			}
+			checkPredicateType(t, attestation.PredicateOpenVEX, vexStatement.PredicateType)
+			if len(vexStatement.Predicate.Statements) != 1 || vexStatement.Predicate.Statements[0].Status != "not_affected" {
+				t.Errorf("Unexpected OpenVEX statements: %+v", vexStatement.Predicate.Statements)
+			}
+		case "cyclonedx":
+			var cdxStatement attestation.CycloneDXStatement
+			if err := json.Unmarshal(jsonBytes, &cdxStatement); err != nil {
+				t.Fatal("Wanted CycloneDX statement, can't unmarshal to it: ", err)
+			}
+			checkPredicateType(t, attestation.PredicateCycloneDX, cdxStatement.PredicateType)
 		case "default":
 			t.Fatal("non supported predicate file")
 		}
@@ -0,0 +1,160 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/sigstore/cosign/pkg/oci"
+)
+
+// InputContextField is the field of the policy input holding its
+// InputContext, next to the fields of the document being evaluated.
+const InputContextField = "context"
+
+// InputContext describes what the document a policy is evaluated against was
+// verified for, so that policies can depend on where and by whom the image is
+// deployed, e.g. require fresher scans in production namespaces.
+type InputContext struct {
+	// Image is the image the signatures or attestations were verified for.
+	Image *ImageContext `json:"image,omitempty"`
+	// Namespace is the namespace the image is admitted in.
+	Namespace string `json:"namespace,omitempty"`
+	// Labels are the labels of the pod, or of the pod template of workloads.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Workload is the admitted resource, a pod or the workload creating it.
+	Workload *WorkloadContext `json:"workload,omitempty"`
+	// AdmissionTime is when the admission request was received.
+	AdmissionTime *time.Time `json:"admissionTime,omitempty"`
+	// Signer is the identity which signed the evaluated attestation.
+	Signer *SignerContext `json:"signer,omitempty"`
+	// Rekor is the transparency log entry of the evaluated attestation, if
+	// it was uploaded to one.
+	Rekor *RekorContext `json:"rekor,omitempty"`
+}
+
+// ImageContext identifies an image.
+type ImageContext struct {
+	// Reference is the reference of the image, as it is deployed.
+	Reference string `json:"reference"`
+	// Repository is the repository of the image, without tag or digest.
+	Repository string `json:"repository"`
+	// Digest is set when the image is referenced by digest.
+	Digest string `json:"digest,omitempty"`
+}
+
+// WorkloadContext identifies the admitted resource.
+type WorkloadContext struct {
+	Kind   string            `json:"kind,omitempty"`
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// SignerContext is the identity of the signer of an attestation.
+type SignerContext struct {
+	// Authority is the name of the authority of the ClusterImagePolicy
+	// which verified the signature.
+	Authority string `json:"authority,omitempty"`
+	// Subject, Issuer and Extensions are only set for signatures with a
+	// certificate, as in SignatureCertificate.
+	Subject    string            `json:"subject,omitempty"`
+	Issuer     string            `json:"issuer,omitempty"`
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// RekorContext holds the metadata of a transparency log entry.
+type RekorContext struct {
+	LogIndex       int64     `json:"logIndex"`
+	LogID          string    `json:"logID"`
+	IntegratedTime time.Time `json:"integratedTime"`
+}
+
+type inputContextKey struct{}
+
+// WithInputContext attaches the InputContext to the returned context, for the
+// evaluations of policies made with it to add it to their input.
+func WithInputContext(ctx context.Context, ic InputContext) context.Context {
+	return context.WithValue(ctx, inputContextKey{}, ic)
+}
+
+// InputContextFromContext returns the InputContext attached with
+// WithInputContext, or an empty one.
+func InputContextFromContext(ctx context.Context) InputContext {
+	ic, _ := ctx.Value(inputContextKey{}).(InputContext)
+	return ic
+}
+
+// NewImageContext returns the ImageContext of ref.
+func NewImageContext(ref name.Reference) *ImageContext {
+	ic := &ImageContext{
+		Reference:  ref.Name(),
+		Repository: ref.Context().Name(),
+	}
+	if d, ok := ref.(name.Digest); ok {
+		ic.Digest = d.DigestStr()
+	}
+	return ic
+}
+
+// WithSignature returns a copy of the InputContext with the signer and the
+// transparency log entry of the verified signature or attestation.
+func (ic InputContext) WithSignature(authority string, sig oci.Signature) (InputContext, error) {
+	ic.Signer = &SignerContext{Authority: authority}
+	cert, err := sig.Cert()
+	if err != nil {
+		return ic, fmt.Errorf("getting certificate: %w", err)
+	}
+	if c := signatureCertificate(cert); c != nil {
+		ic.Signer.Subject = c.Subject
+		ic.Signer.Issuer = c.Issuer
+		ic.Signer.Extensions = c.Extensions
+	}
+	b, err := sig.Bundle()
+	if err != nil {
+		return ic, fmt.Errorf("getting bundle: %w", err)
+	}
+	ic.Rekor = nil
+	if b != nil {
+		ic.Rekor = &RekorContext{
+			LogIndex:       b.Payload.LogIndex,
+			LogID:          b.Payload.LogID,
+			IntegratedTime: time.Unix(b.Payload.IntegratedTime, 0).UTC(),
+		}
+	}
+	return ic, nil
+}
+
+// AddInputContext adds the InputContext to the JSON object policies are
+// evaluated against, under InputContextField.
+func AddInputContext(jsonBytes []byte, ic InputContext) ([]byte, error) {
+	var input map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &input); err != nil {
+		return nil, fmt.Errorf("policy input must be a JSON object: %w", err)
+	}
+	if input == nil {
+		input = map[string]json.RawMessage{}
+	}
+	raw, err := json.Marshal(ic)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling input context: %w", err)
+	}
+	input[InputContextField] = raw
+	return json.Marshal(input)
+}
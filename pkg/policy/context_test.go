@@ -0,0 +1,57 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func TestAddInputContext(t *testing.T) {
+	ref := name.MustParseReference("gcr.io/foo/bar@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4")
+	ic := InputContext{
+		Image:     NewImageContext(ref),
+		Namespace: "prod",
+		Labels:    map[string]string{"app": "web"},
+	}
+	ctx := WithInputContext(context.Background(), ic)
+	if got := InputContextFromContext(ctx); got.Namespace != "prod" {
+		t.Errorf("InputContextFromContext() = %+v", got)
+	}
+	if got := InputContextFromContext(context.Background()); got.Namespace != "" || got.Image != nil {
+		t.Errorf("InputContextFromContext() without context = %+v", got)
+	}
+
+	got, err := AddInputContext([]byte(`{"predicate":{"foo":"bar"}}`), InputContextFromContext(ctx))
+	if err != nil {
+		t.Fatalf("AddInputContext() = %v", err)
+	}
+	want := `{"context":{"image":{"reference":"gcr.io/foo/bar@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4",` +
+		`"repository":"gcr.io/foo/bar","digest":"sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4"},` +
+		`"namespace":"prod","labels":{"app":"web"}},"predicate":{"foo":"bar"}}`
+	if string(got) != want {
+		t.Errorf("AddInputContext() = %s, want %s", got, want)
+	}
+	if err := EvaluatePolicyAgainstJSON(context.Background(), "context", "cue", `predicate: foo: "bar"
+context: namespace: "prod"`, got); err != nil {
+		t.Errorf("policy on the context failed: %v", err)
+	}
+
+	if _, err := AddInputContext([]byte(`["not", "an", "object"]`), ic); err == nil {
+		t.Error("AddInputContext() accepted a JSON array")
+	}
+}
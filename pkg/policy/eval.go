@@ -17,33 +17,108 @@ package policy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"github.com/sigstore/cosign/pkg/cosign/cel"
+	"github.com/sigstore/cosign/pkg/cosign/jsonschema"
 	"github.com/sigstore/cosign/pkg/cosign/rego"
 
 	"knative.dev/pkg/logging"
 )
 
+type explainKey struct{}
+
+// WithExplain makes the policies evaluated with the returned context explain
+// why they fail, in one of the rego.ExplainModes for rego policies. The
+// explanation is in the EvaluationError they return.
+func WithExplain(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, explainKey{}, mode)
+}
+
+func explainMode(ctx context.Context) string {
+	mode, _ := ctx.Value(explainKey{}).(string)
+	return mode
+}
+
+// EvaluationError is returned when a policy fails with a context made by
+// WithExplain.
+type EvaluationError struct {
+	Err error
+	// Explanation is the trace of the evaluation of rego policies, or the
+	// conflicting values of cue policies with their paths.
+	Explanation string
+}
+
+// Error implements error
+func (e *EvaluationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the policy.
+func (e *EvaluationError) Unwrap() error {
+	return e.Err
+}
+
+// Explanation returns the explanation of the EvaluationError in the chain of
+// err, or an empty string if there is none.
+func Explanation(err error) string {
+	var ee *EvaluationError
+	if errors.As(err, &ee) {
+		return ee.Explanation
+	}
+	return ""
+}
+
 // EvaluatePolicyAgainstJson is used to run a policy engine against JSON bytes.
 // These bytes can be for example Attestations, or ClusterImagePolicy result
 // types.
 // name - which attestation are we evaluating
-// policyType - cue|rego
-// policyBody - String representing either cue or rego language
+// policyType - cue|rego|jsonschema|cel
+// policyBody - String representing the policy in the language of policyType
 // jsonBytes - Bytes to evaluate against the policyBody in the given language
 func EvaluatePolicyAgainstJSON(ctx context.Context, name, policyType string, policyBody string, jsonBytes []byte) error {
+	return EvaluatePolicyAgainstJSONWithQuery(ctx, name, policyType, policyBody, "", jsonBytes)
+}
+
+// EvaluatePolicyAgainstJSONWithQuery is EvaluatePolicyAgainstJSON with the
+// query deciding whether a rego policy is satisfied, the default one is used
+// when it is empty.
+func EvaluatePolicyAgainstJSONWithQuery(ctx context.Context, name, policyType string, policyBody string, query string, jsonBytes []byte) error {
 	logging.FromContext(ctx).Debugf("Evaluating JSON: %s against policy: %s", string(jsonBytes), policyBody)
 	switch policyType {
 	case "cue":
 		cueValidationErr := evaluateCue(ctx, jsonBytes, policyBody)
 		if cueValidationErr != nil {
-			return fmt.Errorf("failed evaluating cue policy for %s : %s", name, cueValidationErr.Error()) // nolint
+			err := fmt.Errorf("failed evaluating cue policy for %s : %w", name, cueValidationErr)
+			if explainMode(ctx) == "" {
+				return err
+			}
+			return &EvaluationError{Err: err, Explanation: explainCue(cueValidationErr)}
 		}
 	case "rego":
-		regoValidationErr := evaluateRego(ctx, jsonBytes, policyBody)
+		regoValidationErr := evaluateRego(ctx, jsonBytes, policyBody, query)
 		if regoValidationErr != nil {
-			return fmt.Errorf("failed evaluating rego policy for type %s: %s", name, regoValidationErr.Error()) // nolint
+			err := fmt.Errorf("failed evaluating rego policy for type %s: %w", name, regoValidationErr)
+			mode := explainMode(ctx)
+			if mode == "" {
+				return err
+			}
+			trace, explainErr := rego.ExplainJSONWithModuleInput(jsonBytes, policyBody, query, mode)
+			if explainErr != nil {
+				trace = fmt.Sprintf("failed to explain the evaluation: %v", explainErr)
+			}
+			return &EvaluationError{Err: err, Explanation: trace}
+		}
+	case "jsonschema":
+		if err := evaluateJSONSchema(ctx, jsonBytes, policyBody); err != nil {
+			return fmt.Errorf("failed evaluating jsonschema policy for %s: %w", name, err)
+		}
+	case "cel":
+		if err := evaluateCEL(ctx, jsonBytes, policyBody); err != nil {
+			return fmt.Errorf("failed evaluating cel policy for %s: %w", name, err)
 		}
 	default:
 		return fmt.Errorf("sorry Type %s is not supported yet", policyType)
@@ -73,9 +148,35 @@ func evaluateCue(ctx context.Context, attestation []byte, evaluator string) erro
 }
 
 // evaluateRego evaluates a rego policy `evaluator` against `attestation`
-func evaluateRego(ctx context.Context, attestation []byte, evaluator string) error {
+func evaluateRego(ctx context.Context, attestation []byte, evaluator string, query string) error {
 	logging.FromContext(ctx).Infof("Evaluating attestation: %s", string(attestation))
 	logging.FromContext(ctx).Infof("Evaluating evaluator: %s", evaluator)
 
-	return rego.ValidateJSONWithModuleInput(attestation, evaluator)
+	return rego.ValidateJSONWithModuleInputAndQuery(attestation, evaluator, query)
+}
+
+// evaluateJSONSchema validates `attestation` against the JSON schema `evaluator`
+func evaluateJSONSchema(ctx context.Context, attestation []byte, evaluator string) error {
+	logging.FromContext(ctx).Infof("Evaluating attestation: %s", string(attestation))
+	logging.FromContext(ctx).Infof("Evaluating schema: %s", evaluator)
+
+	return jsonschema.ValidateJSON(attestation, evaluator)
+}
+
+// evaluateCEL evaluates the cel expression `evaluator` against `attestation`
+func evaluateCEL(ctx context.Context, attestation []byte, evaluator string) error {
+	logging.FromContext(ctx).Infof("Evaluating attestation: %s", string(attestation))
+	logging.FromContext(ctx).Infof("Evaluating expression: %s", evaluator)
+
+	return cel.ValidateJSONWithContext(ctx, attestation, evaluator)
+}
+
+// explainCue returns the details of the cue errors in the chain of err, one
+// per line with the path of the conflicting values and their positions.
+func explainCue(err error) string {
+	var cueErr cueerrors.Error
+	if !errors.As(err, &cueErr) {
+		return ""
+	}
+	return cueerrors.Details(cueErr, nil)
 }
@@ -86,6 +86,7 @@ func TestEvalPolicy(t *testing.T) {
 		json       string
 		policyType string
 		policyFile string
+		query      string
 		wantErr    bool
 		wantErrSub string
 	}{{
@@ -198,10 +199,53 @@ func TestEvalPolicy(t *testing.T) {
 				keySignature := input.authorityMatches.keysignature.signatures
 				count(keySignature) == 1
 			}`,
+		}, {
+			name:       "Rego cluster image policy with query, denied",
+			json:       cipAttestation,
+			policyType: "rego",
+			query:      "data.images.allow",
+			wantErr:    true,
+			wantErrSub: `failed evaluating rego policy for type Rego cluster image policy with query, denied: policy denied: only one keyless attestation`,
+			policyFile: `package images
+			allow = true
+			deny[msg] {
+				count(input.authorityMatches.keylessatt.attestations) < 2
+				msg := "only one keyless attestation"
+			}`,
+		}, {
+			name:       "JSON schema vuln attestation, checks out",
+			json:       vulnAttestation,
+			policyType: "jsonschema",
+			policyFile: `{
+				"required": ["predicate"],
+				"properties": {
+					"predicateType": {"const": "cosign.sigstore.dev/attestation/vuln/v1"},
+					"predicate": {"required": ["scanner", "metadata"]}
+				}
+			}`,
+		}, {
+			name:       "JSON schema custom attestation, fails",
+			json:       customAttestation,
+			policyType: "jsonschema",
+			wantErr:    true,
+			wantErrSub: `failed evaluating jsonschema policy for JSON schema custom attestation, fails: does not match the schema: predicateType: predicateType does not match: "cosign.sigstore.dev/attestation/vuln/v1"`,
+			policyFile: `{"properties": {"predicateType": {"const": "cosign.sigstore.dev/attestation/vuln/v1"}}}`,
+		}, {
+			name:       "CEL cluster image policy main policy, checks out",
+			json:       cipAttestation,
+			policyType: "cel",
+			policyFile: `size(input.authorityMatches.keylessatt.attestations) == 1 && size(input.authorityMatches.keysignature.signatures) == 1`,
+		}, {
+			name:       "CEL cluster image policy main policy, fails",
+			json:       cipAttestation,
+			policyType: "cel",
+			wantErr:    true,
+			wantErrSub: `failed evaluating cel policy for CEL cluster image policy main policy, fails: expression "size(input.authorityMatches.keylessatt.attestations) > 1" evaluated to false`,
+			policyFile: `size(input.authorityMatches.keylessatt.attestations) > 1`,
 		}}
 	for _, tc := range tests {
 		ctx := context.Background()
-		err := EvaluatePolicyAgainstJSON(ctx, tc.name, tc.policyType, tc.policyFile, []byte(tc.json))
+		err := EvaluatePolicyAgainstJSONWithQuery(ctx, tc.name, tc.policyType, tc.policyFile, tc.query, []byte(tc.json))
 		if tc.wantErr {
 			if err == nil {
 				t.Errorf("Did not get an error, wanted %s", tc.wantErrSub)
@@ -215,3 +259,36 @@ func TestEvalPolicy(t *testing.T) {
 		}
 	}
 }
+
+func TestEvaluatePolicyExplain(t *testing.T) {
+	regoPolicy := `package sigstore
+	default isCompliant = false
+	isCompliant {
+		trace("checking the keyless attestations")
+		count(input.authorityMatches.keylessatt.attestations) == 2
+	}`
+	cuePolicy := `authorityMatches: keylessatt: attestations: "custom-keyless": [...{subject: "ci@example.com"}]`
+
+	// Without explain mode, there is no explanation.
+	err := EvaluatePolicyAgainstJSON(context.Background(), "rego", "rego", regoPolicy, []byte(cipAttestation))
+	if err == nil || Explanation(err) != "" {
+		t.Fatalf("EvaluatePolicyAgainstJSON() = %v, wanted an error without explanation", err)
+	}
+
+	ctx := WithExplain(context.Background(), "notes")
+	err = EvaluatePolicyAgainstJSON(ctx, "rego", "rego", regoPolicy, []byte(cipAttestation))
+	if err == nil || !strings.Contains(err.Error(), "policy is not compliant") {
+		t.Fatalf("EvaluatePolicyAgainstJSON() = %v, wanted a non compliant policy", err)
+	}
+	if got := Explanation(err); !strings.Contains(got, "checking the keyless attestations") {
+		t.Errorf("explanation does not contain the note:\n%s", got)
+	}
+
+	err = EvaluatePolicyAgainstJSON(ctx, "cue", "cue", cuePolicy, []byte(cipAttestation))
+	if err == nil {
+		t.Fatal("EvaluatePolicyAgainstJSON() succeeded")
+	}
+	if got := Explanation(err); !strings.Contains(got, `authorityMatches.keylessatt.attestations."custom-keyless".0.subject`) {
+		t.Errorf("explanation does not contain the path of the conflict:\n%s", got)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/types"
+)
+
+// maxPolicySize bounds the size of the policies fetched from registries.
+const maxPolicySize = 1 << 20
+
+var policyMediaTypes = map[string]ggcrtypes.MediaType{
+	"cue":        types.CUEPolicyMediaType,
+	"rego":       types.RegoPolicyMediaType,
+	"jsonschema": types.JSONSchemaPolicyMediaType,
+	"cel":        types.CELPolicyMediaType,
+}
+
+// MediaType returns the media type of the layer holding policies of the
+// given type in the OCI artifacts pushed by cosign policy push.
+func MediaType(policyType string) (ggcrtypes.MediaType, error) {
+	mt, ok := policyMediaTypes[policyType]
+	if !ok {
+		return "", fmt.Errorf("unsupported policy type %q", policyType)
+	}
+	return mt, nil
+}
+
+// FetchOCIPolicy returns the type and the body of the policy pushed by cosign
+// policy push at ref. Its signature is not verified, which is up to the
+// caller, ideally with ref being the digest that was verified.
+func FetchOCIPolicy(ref name.Reference, opts ...ociremote.Option) (string, []byte, error) {
+	img, err := ociremote.SignedImage(ref, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return "", nil, err
+	}
+	if len(layers) != 1 {
+		return "", nil, fmt.Errorf("%s has %d layers, expected a single policy layer", ref, len(layers))
+	}
+	mt, err := layers[0].MediaType()
+	if err != nil {
+		return "", nil, err
+	}
+	policyType := ""
+	for t, policyMT := range policyMediaTypes {
+		if mt == policyMT {
+			policyType = t
+		}
+	}
+	if policyType == "" {
+		return "", nil, fmt.Errorf("%s has a layer of media type %q, which is not a policy", ref, mt)
+	}
+
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		return "", nil, err
+	}
+	defer rc.Close()
+	body, err := io.ReadAll(io.LimitReader(rc, maxPolicySize+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("reading policy: %w", err)
+	}
+	if len(body) > maxPolicySize {
+		return "", nil, fmt.Errorf("%s has a policy larger than %d bytes", ref, maxPolicySize)
+	}
+	return policyType, body, nil
+}
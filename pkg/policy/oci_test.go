@@ -0,0 +1,80 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package policy
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+func TestFetchOCIPolicy(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	push := func(repo string, body string, mt ggcrtypes.MediaType) name.Reference {
+		t.Helper()
+		ref, err := name.ParseReference(fmt.Sprintf("%s/%s:latest", u.Host, repo))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := static.NewFile([]byte(body), static.WithLayerMediaType(mt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+		return ref
+	}
+
+	regoMT, err := MediaType("rego")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MediaType("opa"); err == nil {
+		t.Error("MediaType() accepted an unknown policy type")
+	}
+
+	policyType, body, err := FetchOCIPolicy(push("policies/rego", "package sigstore\nisCompliant = true", regoMT))
+	if err != nil {
+		t.Fatalf("FetchOCIPolicy() = %v", err)
+	}
+	if policyType != "rego" || string(body) != "package sigstore\nisCompliant = true" {
+		t.Errorf("FetchOCIPolicy() = %q, %q", policyType, body)
+	}
+
+	_, _, err = FetchOCIPolicy(push("policies/text", "hello", "text/plain"))
+	if err == nil || !strings.Contains(err.Error(), `has a layer of media type "text/plain", which is not a policy`) {
+		t.Errorf("FetchOCIPolicy() = %v, want an error about the media type", err)
+	}
+
+	_, _, err = FetchOCIPolicy(push("policies/large", strings.Repeat("#", maxPolicySize+1), regoMT))
+	if err == nil || !strings.Contains(err.Error(), "has a policy larger than") {
+		t.Errorf("FetchOCIPolicy() = %v, want an error about the size", err)
+	}
+}
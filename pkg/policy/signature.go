@@ -0,0 +1,97 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	sigs "github.com/sigstore/cosign/pkg/signature"
+)
+
+// SignaturePolicyInput is the document signature policies are evaluated
+// against: the simple signing payload, whose optional section holds the
+// annotations of the signature, along with the identity of its signer.
+type SignaturePolicyInput struct {
+	payload.SimpleContainerImage
+	// Certificate is only set for signatures with a certificate, such as
+	// keyless ones.
+	Certificate *SignatureCertificate `json:"certificate,omitempty"`
+}
+
+// SignatureCertificate holds the details of the certificate of a signature.
+type SignatureCertificate struct {
+	// Subject is the email or URI identity of the signer.
+	Subject string `json:"subject"`
+	// Issuer is the OIDC issuer which authenticated the signer.
+	Issuer string `json:"issuer,omitempty"`
+	// Extensions maps the readable names of the Fulcio extensions of the
+	// certificate to their values, such as the GitHub workflow details.
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// SignatureToPayloadJSON takes in a verified signature and marshals it into
+// the JSON of a SignaturePolicyInput, which is then consumable by policy
+// engines like cue, rego, etc.
+//
+// Anything fed here must have been validated with either
+// `VerifyLocalImageSignatures` or `VerifyImageSignatures`
+func SignatureToPayloadJSON(verifiedSignature oci.Signature) ([]byte, error) {
+	p, err := verifiedSignature.Payload()
+	if err != nil {
+		return nil, fmt.Errorf("getting payload: %w", err)
+	}
+	var input SignaturePolicyInput
+	if err := json.Unmarshal(p, &input.SimpleContainerImage); err != nil {
+		return nil, fmt.Errorf("unmarshaling simple signing payload: %w", err)
+	}
+
+	cert, err := verifiedSignature.Cert()
+	if err != nil {
+		return nil, fmt.Errorf("getting certificate: %w", err)
+	}
+	input.Certificate = signatureCertificate(cert)
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling policy input: %w", err)
+	}
+	return payload, nil
+}
+
+// signatureCertificate returns the SignatureCertificate of cert, or nil for
+// signatures without a certificate.
+func signatureCertificate(cert *x509.Certificate) *SignatureCertificate {
+	if cert == nil {
+		return nil
+	}
+	c := &SignatureCertificate{
+		Subject: sigs.CertSubject(cert),
+		Issuer:  sigs.CertIssuerExtension(cert),
+	}
+	for _, ext := range cert.Extensions {
+		if name, ok := sigs.CertExtensionMap[ext.Id.String()]; ok {
+			if c.Extensions == nil {
+				c.Extensions = map[string]string{}
+			}
+			c.Extensions[name] = string(ext.Value)
+		}
+	}
+	return c
+}
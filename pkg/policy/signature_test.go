@@ -0,0 +1,80 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/test"
+)
+
+const signaturePayload = `{"critical":{"identity":{"docker-reference":"example.com/app"},` +
+	`"image":{"docker-manifest-digest":"sha256:a1b2c3"},"type":"cosign container image signature"},` +
+	`"optional":{"build_id":"1234"}}`
+
+const ciSignerRego = `package sigstore
+default isCompliant = false
+isCompliant {
+  input.optional.build_id != ""
+  input.certificate.subject == "ci@example.com"
+  input.certificate.issuer == "https://ci.example.com"
+}`
+
+func TestSignatureToPayloadJSON(t *testing.T) {
+	rootCert, rootKey, _ := test.GenerateRootCa()
+	leafCert, _, _ := test.GenerateLeafCert("ci@example.com", "https://ci.example.com", rootCert, rootKey)
+	leafPEM, err := cryptoutils.MarshalCertificateToPEM(leafCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyless, err := static.NewSignature([]byte(signaturePayload), "", static.WithCertChain(leafPEM, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := SignatureToPayloadJSON(keyless)
+	if err != nil {
+		t.Fatalf("SignatureToPayloadJSON() = %v", err)
+	}
+	if err := EvaluatePolicyAgainstJSON(context.Background(), "signature", "rego", ciSignerRego, payload); err != nil {
+		t.Errorf("policy failed against %s: %v", payload, err)
+	}
+	cuePolicy := `optional: build_id: =~"^[0-9]+$"
+certificate: extensions: oidcIssuer: "https://ci.example.com"`
+	if err := EvaluatePolicyAgainstJSON(context.Background(), "signature", "cue", cuePolicy, payload); err != nil {
+		t.Errorf("cue policy failed against %s: %v", payload, err)
+	}
+
+	// Without a certificate, the policy can't tell who signed the image.
+	keyed, err := static.NewSignature([]byte(signaturePayload), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err = SignatureToPayloadJSON(keyed)
+	if err != nil {
+		t.Fatalf("SignatureToPayloadJSON() = %v", err)
+	}
+	if err := EvaluatePolicyAgainstJSON(context.Background(), "signature", "rego", ciSignerRego, payload); err == nil {
+		t.Errorf("policy succeeded against %s", payload)
+	}
+
+	if _, err := SignatureToPayloadJSON(&failingAttestation{}); err == nil {
+		t.Error("SignatureToPayloadJSON() succeeded with a failing signature")
+	}
+}
@@ -20,11 +20,17 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/sigstore/cosign/pkg/apis/config"
 	"github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
 	"github.com/sigstore/cosign/pkg/apis/utils"
 	clusterimagepolicyreconciler "github.com/sigstore/cosign/pkg/client/injection/reconciler/policy/v1alpha1/clusterimagepolicy"
+	"github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook"
 	webhookcip "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook/clusterimagepolicy"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/policy"
 	"github.com/sigstore/cosign/pkg/reconciler/clusterimagepolicy/resources"
 
 	corev1 "k8s.io/api/core/v1"
@@ -34,6 +40,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
@@ -55,6 +62,9 @@ type Reconciler struct {
 	secretlister    corev1listers.SecretLister
 	configmaplister corev1listers.ConfigMapLister
 	kubeclient      kubernetes.Interface
+	// namespaceSecrets informs the secrets of the namespaces of the
+	// ImagePolicies, it is nil for ClusterImagePolicies.
+	namespaceSecrets *namespaceSecrets
 }
 
 // Check that our Reconciler implements Interface as well as finalizer
@@ -63,16 +73,33 @@ var _ clusterimagepolicyreconciler.Finalizer = (*Reconciler)(nil)
 
 // ReconcileKind implements Interface.ReconcileKind.
 func (r *Reconciler) ReconcileKind(ctx context.Context, cip *v1alpha1.ClusterImagePolicy) reconciler.Event {
-	cipCopy, cipErr := r.inlinePublicKeys(ctx, cip)
-	if cipErr != nil {
+	cipCopy := cip.DeepCopy()
+	if err := r.inlinePolicy(ctx, cip, &cipCopy.Spec); err != nil {
 		r.handleCIPError(ctx, cip.Name)
 		// Note that we return the error about the Invalid cip here to make
 		// sure that it's surfaced.
-		return cipErr
+		return err
 	}
+	return r.writeEntry(ctx, cip.Name, webhookcip.ConvertClusterImagePolicyV1alpha1ToWebhook(cipCopy))
+}
 
-	webhookCIP := webhookcip.ConvertClusterImagePolicyV1alpha1ToWebhook(cipCopy)
+// FinalizeKind implements Interface.ReconcileKind.
+func (r *Reconciler) FinalizeKind(ctx context.Context, cip *v1alpha1.ClusterImagePolicy) reconciler.Event {
+	return r.removeEntry(ctx, cip.Name)
+}
+
+// inlinePolicy inlines the secrets, KMS keys and OCI policies referenced by
+// spec, the one of owner, in place.
+func (r *Reconciler) inlinePolicy(ctx context.Context, owner kmeta.Accessor, spec *v1alpha1.ClusterImagePolicySpec) error {
+	if err := r.inlinePublicKeys(ctx, owner, spec); err != nil {
+		return err
+	}
+	return r.inlineOCIPolicies(ctx, owner, spec)
+}
 
+// writeEntry adds or updates the entry cipName of the ConfigMap with
+// webhookCIP.
+func (r *Reconciler) writeEntry(ctx context.Context, cipName string, webhookCIP *webhookcip.ClusterImagePolicy) error {
 	// See if the CM holding configs exists
 	existing, err := r.configmaplister.ConfigMaps(system.Namespace()).Get(config.ImagePoliciesConfigName)
 	if err != nil {
@@ -81,7 +108,7 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, cip *v1alpha1.ClusterIma
 			return err
 		}
 		// Does not exist, create it.
-		cm, err := resources.NewConfigMap(system.Namespace(), config.ImagePoliciesConfigName, cip.Name, webhookCIP)
+		cm, err := resources.NewConfigMap(system.Namespace(), config.ImagePoliciesConfigName, cipName, webhookCIP)
 		if err != nil {
 			logging.FromContext(ctx).Errorf("Failed to construct configmap: %v", err)
 			return err
@@ -91,7 +118,7 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, cip *v1alpha1.ClusterIma
 	}
 
 	// Check if we need to update the configmap or not.
-	patchBytes, err := resources.CreatePatch(system.Namespace(), config.ImagePoliciesConfigName, cip.Name, existing.DeepCopy(), webhookCIP)
+	patchBytes, err := resources.CreatePatch(system.Namespace(), config.ImagePoliciesConfigName, cipName, existing.DeepCopy(), webhookCIP)
 	if err != nil {
 		logging.FromContext(ctx).Errorf("Failed to create patch: %v", err)
 		return err
@@ -103,8 +130,8 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, cip *v1alpha1.ClusterIma
 	return nil
 }
 
-// FinalizeKind implements Interface.ReconcileKind.
-func (r *Reconciler) FinalizeKind(ctx context.Context, cip *v1alpha1.ClusterImagePolicy) reconciler.Event {
+// removeEntry removes the entry cipName from the ConfigMap, if any.
+func (r *Reconciler) removeEntry(ctx context.Context, cipName string) error {
 	// See if the CM holding configs even exists
 	existing, err := r.configmaplister.ConfigMaps(system.Namespace()).Get(config.ImagePoliciesConfigName)
 	if err != nil {
@@ -121,7 +148,7 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, cip *v1alpha1.ClusterImag
 		return nil
 	}
 	// CM exists, so remove our entry from it.
-	return r.removeCIPEntry(ctx, existing, cip.Name)
+	return r.removeCIPEntry(ctx, existing, cipName)
 }
 
 func (r *Reconciler) handleCIPError(ctx context.Context, cipName string) {
@@ -136,36 +163,121 @@ func (r *Reconciler) handleCIPError(ctx context.Context, cipName string) {
 	}
 }
 
-// inlinePublicKeys will go through the CIP and try to read the referenced
-// secrets, KMS keys and convert them into inlined data. Makes a copy of the CIP
-// before modifying it and returns the copy.
-func (r *Reconciler) inlinePublicKeys(ctx context.Context, cip *v1alpha1.ClusterImagePolicy) (*v1alpha1.ClusterImagePolicy, error) {
-	ret := cip.DeepCopy()
-	for _, authority := range ret.Spec.Authorities {
-		if authority.Key != nil && authority.Key.SecretRef != nil {
-			if err := r.inlineAndTrackSecret(ctx, ret, authority.Key); err != nil {
-				logging.FromContext(ctx).Errorf("Failed to read secret %q: %v", authority.Key.SecretRef.Name, err)
-				return nil, err
-			}
+// inlinePublicKeys will go through the spec of owner and try to read the
+// referenced secrets, KMS keys and convert them into inlined data.
+func (r *Reconciler) inlinePublicKeys(ctx context.Context, owner kmeta.Accessor, spec *v1alpha1.ClusterImagePolicySpec) error {
+	for i := range spec.Authorities {
+		if err := r.inlineAuthorityKeys(ctx, owner, &spec.Authorities[i]); err != nil {
+			return err
 		}
-		if authority.Keyless != nil && authority.Keyless.CACert != nil &&
-			authority.Keyless.CACert.SecretRef != nil {
-			if err := r.inlineAndTrackSecret(ctx, ret, authority.Keyless.CACert); err != nil {
-				logging.FromContext(ctx).Errorf("Failed to read secret %q: %v", authority.Keyless.CACert.SecretRef.Name, err)
-				return nil, err
-			}
+	}
+	return nil
+}
+
+// inlineAuthorityKeys inlines the secrets and KMS keys of the authority.
+func (r *Reconciler) inlineAuthorityKeys(ctx context.Context, owner kmeta.Accessor, authority *v1alpha1.Authority) error {
+	if authority.Key != nil && authority.Key.SecretRef != nil {
+		if err := r.inlineAndTrackSecret(ctx, owner, authority.Key); err != nil {
+			logging.FromContext(ctx).Errorf("Failed to read secret %q: %v", authority.Key.SecretRef.Name, err)
+			return err
+		}
+	}
+	if authority.Keyless != nil && authority.Keyless.CACert != nil &&
+		authority.Keyless.CACert.SecretRef != nil {
+		if err := r.inlineAndTrackSecret(ctx, owner, authority.Keyless.CACert); err != nil {
+			logging.FromContext(ctx).Errorf("Failed to read secret %q: %v", authority.Keyless.CACert.SecretRef.Name, err)
+			return err
+		}
+	}
+	if authority.Key != nil && strings.Contains(authority.Key.KMS, "://") {
+		pubKeyString, err := getKMSPublicKey(ctx, authority.Key.KMS)
+		if err != nil {
+			return err
 		}
-		if authority.Key != nil && strings.Contains(authority.Key.KMS, "://") {
-			pubKeyString, err := getKMSPublicKey(ctx, authority.Key.KMS)
-			if err != nil {
-				return nil, err
+
+		authority.Key.Data = pubKeyString
+		authority.Key.KMS = ""
+	}
+	return nil
+}
+
+// For testing
+var verifyPolicySignatures = webhook.ValidatePolicySignaturesForAuthority
+var fetchOCIPolicy = policy.FetchOCIPolicy
+
+// inlineOCIPolicies goes through the policies of the spec of owner, the spec
+// level one and the ones of the attestations, and fetches those stored in OCI
+// registries. Once their signature is verified with their authority, their
+// body is inlined in place of Data, and their OCI reference and authority are
+// cleared out.
+func (r *Reconciler) inlineOCIPolicies(ctx context.Context, owner kmeta.Accessor, spec *v1alpha1.ClusterImagePolicySpec) error {
+	if err := r.inlineOCIPolicy(ctx, owner, spec.Policy); err != nil {
+		return err
+	}
+	for _, authority := range spec.Authorities {
+		for _, att := range authority.Attestations {
+			if err := r.inlineOCIPolicy(ctx, owner, att.Policy); err != nil {
+				return err
 			}
+		}
+	}
+	return nil
+}
 
-			authority.Key.Data = pubKeyString
-			authority.Key.KMS = ""
+func (r *Reconciler) inlineOCIPolicy(ctx context.Context, owner kmeta.Accessor, p *v1alpha1.Policy) error {
+	if p == nil || p.OCI == "" {
+		return nil
+	}
+	if p.Authority == nil {
+		return fmt.Errorf("policy %q has no authority to verify it", p.OCI)
+	}
+	if err := r.inlineAuthorityKeys(ctx, owner, p.Authority); err != nil {
+		return err
+	}
+	authority := webhookcip.ConvertAuthorityV1Alpha1ToWebhook(*p.Authority)
+	authority.Name = "policy"
+	if authority.Key != nil {
+		keys, err := webhookcip.ConvertKeyDataToPublicKeys(authority.Key.Data)
+		if err != nil {
+			return fmt.Errorf("parsing the public key of the authority of policy %q: %w", p.OCI, err)
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("parsing the public key of the authority of policy %q: no PEM encoded key", p.OCI)
 		}
+		authority.Key.PublicKeys = keys
+	}
+
+	ref, err := name.ParseReference(p.OCI)
+	if err != nil {
+		return err
 	}
-	return ret, nil
+	kc, err := k8schain.New(ctx, r.kubeclient, k8schain.Options{Namespace: secretsNamespace(owner)})
+	if err != nil {
+		return fmt.Errorf("creating keychain: %w", err)
+	}
+	opts := []ociremote.Option{ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(kc), remote.WithContext(ctx))}
+	// Verify and fetch the same digest, so that the policy can't be swapped
+	// in between when it is referenced by tag.
+	digest, err := ociremote.ResolveDigest(ref, opts...)
+	if err != nil {
+		return fmt.Errorf("resolving policy %q: %w", p.OCI, err)
+	}
+	if _, err := verifyPolicySignatures(ctx, digest, *authority, opts...); err != nil {
+		logging.FromContext(ctx).Errorf("Failed to verify policy %q: %v", digest, err)
+		return fmt.Errorf("verifying policy %q: %w", p.OCI, err)
+	}
+	policyType, body, err := fetchOCIPolicy(digest, opts...)
+	if err != nil {
+		return fmt.Errorf("fetching policy %q: %w", p.OCI, err)
+	}
+	if policyType != p.Type {
+		return fmt.Errorf("policy %q is a %s policy, not %s", p.OCI, policyType, p.Type)
+	}
+	logging.FromContext(ctx).Infof("inlining policy %q", digest)
+	p.Data = string(body)
+	p.OCI = ""
+	p.Authority = nil
+	return nil
 }
 
 // getKMSPublicKey returns the public key as a string from the configured KMS service using the key ID
@@ -190,16 +302,27 @@ func getKMSPublicKey(ctx context.Context, keyID string) (string, error) {
 // There's still some discussion about how to handle multiple keys in a secret
 // for now, just grab one from it. For reference, the discussion is here:
 // TODO(vaikas): https://github.com/sigstore/cosign/issues/1573
-func (r *Reconciler) inlineAndTrackSecret(ctx context.Context, cip *v1alpha1.ClusterImagePolicy, keyref *v1alpha1.KeyRef) error {
+func (r *Reconciler) inlineAndTrackSecret(ctx context.Context, owner kmeta.Accessor, keyref *v1alpha1.KeyRef) error {
+	namespace := secretsNamespace(owner)
 	if err := r.tracker.TrackReference(tracker.Reference{
 		APIVersion: "v1",
 		Kind:       "Secret",
-		Namespace:  system.Namespace(),
+		Namespace:  namespace,
 		Name:       keyref.SecretRef.Name,
-	}, cip); err != nil {
+	}, owner); err != nil {
 		return fmt.Errorf("failed to track changes to secret %q : %w", keyref.SecretRef.Name, err)
 	}
-	secret, err := r.secretlister.Secrets(system.Namespace()).Get(keyref.SecretRef.Name)
+	secrets := r.secretlister.Secrets(namespace)
+	if namespace != system.Namespace() {
+		if r.namespaceSecrets == nil {
+			return fmt.Errorf("the secrets of namespace %q are not informed", namespace)
+		}
+		var err error
+		if secrets, err = r.namespaceSecrets.Lister(namespace); err != nil {
+			return err
+		}
+	}
+	secret, err := secrets.Get(keyref.SecretRef.Name)
 	if err != nil {
 		return err
 	}
@@ -221,6 +344,16 @@ func (r *Reconciler) inlineAndTrackSecret(ctx context.Context, cip *v1alpha1.Clu
 	return nil
 }
 
+// secretsNamespace returns the namespace the secrets referenced by the policy
+// of owner are read from: the one of an ImagePolicy, or the system namespace
+// for ClusterImagePolicies.
+func secretsNamespace(owner kmeta.Accessor) string {
+	if ns := owner.GetNamespace(); ns != "" {
+		return ns
+	}
+	return system.Namespace()
+}
+
 // removeCIPEntry removes an entry from a CM. If no entry exists, it's a nop.
 func (r *Reconciler) removeCIPEntry(ctx context.Context, cm *corev1.ConfigMap, cipName string) error {
 	patchBytes, err := resources.CreateRemovePatch(system.Namespace(), config.ImagePoliciesConfigName, cm.DeepCopy(), cipName)
@@ -19,15 +19,23 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
 	logtesting "knative.dev/pkg/logging/testing"
 
 	"github.com/sigstore/cosign/pkg/apis/config"
 	"github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
 	fakecosignclient "github.com/sigstore/cosign/pkg/client/injection/client/fake"
 	"github.com/sigstore/cosign/pkg/client/injection/reconciler/policy/v1alpha1/clusterimagepolicy"
+	"github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook"
+	webhookcip "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook/clusterimagepolicy"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/policy"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -66,6 +74,10 @@ RCTfQ5s1kD+hGMSE1rH7s46hmXEeyhnlRnaGF8eMU/SBJE/2NKPnxE7WzQ==
 	// This is the patch for replacing a single entry in the ConfigMap
 	replaceCIPPatch = `[{"op":"replace","path":"/data/test-cip","value":"{\"images\":[{\"glob\":\"ghcr.io/example/*\"}],\"authorities\":[{\"name\":\"authority-0\",\"key\":{\"data\":\"-----BEGIN PUBLIC KEY-----\\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAExB6+H6054/W1SJgs5JR6AJr6J35J\\nRCTfQ5s1kD+hGMSE1rH7s46hmXEeyhnlRnaGF8eMU/SBJE/2NKPnxE7WzQ==\\n-----END PUBLIC KEY-----\"}}]}"}]`
 
+	// This is the patch for replacing a single entry in the ConfigMap with a
+	// ClusterImagePolicy in warn mode.
+	replaceCIPWarnModePatch = `[{"op":"replace","path":"/data/test-cip","value":"{\"images\":[{\"glob\":\"ghcr.io/example/*\"}],\"authorities\":[{\"name\":\"authority-0\",\"key\":{\"data\":\"-----BEGIN PUBLIC KEY-----\\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAExB6+H6054/W1SJgs5JR6AJr6J35J\\nRCTfQ5s1kD+hGMSE1rH7s46hmXEeyhnlRnaGF8eMU/SBJE/2NKPnxE7WzQ==\\n-----END PUBLIC KEY-----\"}}],\"mode\":\"warn\"}"}]`
+
 	// This is the patch for adding an entry for non-existing KMS for cipName2
 	addCIP2Patch = `[{"op":"add","path":"/data/test-cip-2","value":"{\"images\":[{\"glob\":\"ghcr.io/example/*\"}],\"authorities\":[{\"name\":\"authority-0\",\"key\":{\"data\":\"azure-kms://foo/bar\"}}]}"}]`
 
@@ -172,6 +184,27 @@ func TestReconcile(t *testing.T) {
 		WantPatches: []clientgotesting.PatchActionImpl{
 			makePatch(replaceCIPPatch),
 		},
+	}, {
+		Name: "ClusterImagePolicy in warn mode, needs a patch",
+		Key:  testKey,
+
+		SkipNamespaceValidation: true, // Cluster scoped
+		Objects: []runtime.Object{
+			NewClusterImagePolicy(cipName,
+				WithFinalizer,
+				WithImagePattern(v1alpha1.ImagePattern{
+					Glob: glob,
+				}),
+				WithAuthority(v1alpha1.Authority{
+					Key: &v1alpha1.KeyRef{
+						Data: validPublicKeyData,
+					}}),
+				WithMode("warn")),
+			makeDifferentConfigMap(),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			makePatch(replaceCIPWarnModePatch),
+		},
 	}, {
 		Name: "ClusterImagePolicy with glob and KMS key data, added as a patch",
 		Key:  testKey2,
@@ -711,3 +744,107 @@ func patchRemoveFinalizers(namespace, name string) clientgotesting.PatchActionIm
 	action.Patch = []byte(patch)
 	return action
 }
+
+func TestInlineOCIPolicies(t *testing.T) {
+	const (
+		policyRef    = "example.com/policies/foo@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+		policyBody   = `predicate: foo: "bar"`
+		wrongKeyData = "not a key"
+	)
+	tests := []struct {
+		name      string
+		policy    v1alpha1.Policy
+		verifyErr error
+		fetchType string
+		wantErr   string
+	}{{
+		name: "verified",
+		policy: v1alpha1.Policy{
+			Type:      "cue",
+			OCI:       policyRef,
+			Authority: &v1alpha1.Authority{Key: &v1alpha1.KeyRef{Data: validPublicKeyData}},
+		},
+		fetchType: "cue",
+	}, {
+		name: "not signed by the authority",
+		policy: v1alpha1.Policy{
+			Type:      "cue",
+			OCI:       policyRef,
+			Authority: &v1alpha1.Authority{Key: &v1alpha1.KeyRef{Data: validPublicKeyData}},
+		},
+		verifyErr: errors.New("no matching signatures"),
+		fetchType: "cue",
+		wantErr:   "verifying policy",
+	}, {
+		name: "type mismatch",
+		policy: v1alpha1.Policy{
+			Type:      "cue",
+			OCI:       policyRef,
+			Authority: &v1alpha1.Authority{Key: &v1alpha1.KeyRef{Data: validPublicKeyData}},
+		},
+		fetchType: "rego",
+		wantErr:   "is a rego policy, not cue",
+	}, {
+		name: "invalid key",
+		policy: v1alpha1.Policy{
+			Type:      "cue",
+			OCI:       policyRef,
+			Authority: &v1alpha1.Authority{Key: &v1alpha1.KeyRef{Data: wrongKeyData}},
+		},
+		fetchType: "cue",
+		wantErr:   "parsing the public key",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			verified := false
+			verifyPolicySignatures = func(_ context.Context, ref name.Reference, authority webhookcip.Authority, _ ...ociremote.Option) ([]webhook.PolicySignature, error) {
+				if ref.String() != policyRef {
+					t.Errorf("verified %s, want %s", ref, policyRef)
+				}
+				if authority.Key == nil || len(authority.Key.PublicKeys) != 1 {
+					t.Errorf("authority has no public key: %+v", authority)
+				}
+				verified = true
+				return nil, tc.verifyErr
+			}
+			fetchOCIPolicy = func(ref name.Reference, _ ...ociremote.Option) (string, []byte, error) {
+				if !verified {
+					t.Error("policy fetched before its signature was verified")
+				}
+				return tc.fetchType, []byte(policyBody), nil
+			}
+			t.Cleanup(func() {
+				verifyPolicySignatures = webhook.ValidatePolicySignaturesForAuthority
+				fetchOCIPolicy = policy.FetchOCIPolicy
+			})
+
+			p := tc.policy
+			cip := NewClusterImagePolicy(cipName,
+				WithImagePattern(v1alpha1.ImagePattern{Glob: glob}),
+				WithAuthority(v1alpha1.Authority{
+					Key: &v1alpha1.KeyRef{Data: validPublicKeyData},
+					Attestations: []v1alpha1.Attestation{{
+						Name:          "custom",
+						PredicateType: "custom",
+						Policy:        &p,
+					}},
+				}))
+			r := &Reconciler{kubeclient: k8sfake.NewSimpleClientset(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: "default"},
+			})}
+			err := r.inlineOCIPolicies(context.Background(), cip, &cip.Spec)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("inlineOCIPolicies() = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("inlineOCIPolicies() = %v", err)
+			}
+			if p.Data != policyBody || p.OCI != "" || p.Authority != nil {
+				t.Errorf("policy was not inlined: %+v", p)
+			}
+		})
+	}
+}
@@ -33,14 +33,19 @@ import (
 
 	"github.com/sigstore/cosign/pkg/apis/config"
 	clusterimagepolicyinformer "github.com/sigstore/cosign/pkg/client/injection/informers/policy/v1alpha1/clusterimagepolicy"
+	imagepolicyinformer "github.com/sigstore/cosign/pkg/client/injection/informers/policy/v1alpha1/imagepolicy"
 	clusterimagepolicyreconciler "github.com/sigstore/cosign/pkg/client/injection/reconciler/policy/v1alpha1/clusterimagepolicy"
+	imagepolicyreconciler "github.com/sigstore/cosign/pkg/client/injection/reconciler/policy/v1alpha1/imagepolicy"
 	cminformer "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/configmap"
 	secretinformer "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret"
 )
 
 // This is what the default finalizer name is, but make it explicit so we can
 // use it in tests as well.
-const finalizerName = "clusterimagepolicies.policy.sigstore.dev"
+const (
+	finalizerName            = "clusterimagepolicies.policy.sigstore.dev"
+	imagePolicyFinalizerName = "imagepolicies.policy.sigstore.dev"
+)
 
 // NewController creates a Reconciler and returns the result of NewImpl.
 func NewController(
@@ -95,3 +100,55 @@ func NewController(
 	})
 	return impl
 }
+
+// NewImagePolicyController creates an ImagePolicyReconciler and returns the
+// result of NewImpl.
+func NewImagePolicyController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+	imagepolicyInformer := imagepolicyinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
+	configMapInformer := cminformer.Get(ctx)
+
+	r := &ImagePolicyReconciler{
+		Reconciler: &Reconciler{
+			secretlister:    secretInformer.Lister(),
+			configmaplister: configMapInformer.Lister(),
+			kubeclient:      kubeclient.Get(ctx),
+		},
+		imagepolicylister: imagepolicyInformer.Lister(),
+	}
+	impl := imagepolicyreconciler.NewImpl(ctx, r, func(impl *controller.Impl) controller.Options {
+		return controller.Options{FinalizerName: imagePolicyFinalizerName}
+	})
+	r.tracker = impl.Tracker
+
+	imagepolicyInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	// The secrets of the namespaces of the ImagePolicies are informed once
+	// they are reconciled, the ones of the system namespace by the injected
+	// informer.
+	secretHandler := controller.HandleAll(
+		controller.EnsureTypeMeta(
+			r.tracker.OnChanged,
+			corev1.SchemeGroupVersion.WithKind("Secret"),
+		),
+	)
+	secretInformer.Informer().AddEventHandler(secretHandler)
+	r.namespaceSecrets = newNamespaceSecrets(ctx, kubeclient.Get(ctx), secretHandler)
+
+	// Like ClusterImagePolicies, resync the ImagePolicies when the ConfigMap
+	// they are reconciled into changes.
+	grCb := func(obj interface{}) {
+		logging.FromContext(ctx).Info("Doing a global resync on ImagePolicies due to ConfigMap changing.")
+		impl.GlobalResync(imagepolicyInformer.Informer())
+	}
+	configMapInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.ChainFilterFuncs(
+			pkgreconciler.NamespaceFilterFunc(system.Namespace()),
+			pkgreconciler.NameFilterFunc(config.ImagePoliciesConfigName)),
+		Handler: controller.HandleAll(grCb),
+	})
+	return impl
+}
@@ -22,6 +22,7 @@ import (
 
 	// Fake injection informers
 	_ "github.com/sigstore/cosign/pkg/client/injection/informers/policy/v1alpha1/clusterimagepolicy/fake"
+	_ "github.com/sigstore/cosign/pkg/client/injection/informers/policy/v1alpha1/imagepolicy/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap/fake"
 	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/configmap/fake"
 	_ "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret/fake"
@@ -37,3 +38,13 @@ func TestNew(t *testing.T) {
 		t.Fatal("Expected NewController to return a non-nil value")
 	}
 }
+
+func TestNewImagePolicyController(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+
+	c := NewImagePolicyController(ctx, &configmap.ManualWatcher{})
+
+	if c == nil {
+		t.Fatal("Expected NewImagePolicyController to return a non-nil value")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterimagepolicy
+
+import (
+	"context"
+
+	"github.com/sigstore/cosign/pkg/apis/config"
+	"github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
+	imagepolicyreconciler "github.com/sigstore/cosign/pkg/client/injection/reconciler/policy/v1alpha1/imagepolicy"
+	listers "github.com/sigstore/cosign/pkg/client/listers/policy/v1alpha1"
+	webhookcip "github.com/sigstore/cosign/pkg/cosign/kubernetes/webhook/clusterimagepolicy"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/reconciler"
+)
+
+// ImagePolicyReconciler implements imagepolicyreconciler.Interface for
+// ImagePolicy resources. They are reconciled like ClusterImagePolicies, into
+// the same ConfigMap, except that the secrets they reference are read from
+// their namespace.
+type ImagePolicyReconciler struct {
+	*Reconciler
+	imagepolicylister listers.ImagePolicyLister
+}
+
+// Check that our ImagePolicyReconciler implements Interface as well as
+// finalizer
+var _ imagepolicyreconciler.Interface = (*ImagePolicyReconciler)(nil)
+var _ imagepolicyreconciler.Finalizer = (*ImagePolicyReconciler)(nil)
+
+// ReconcileKind implements Interface.ReconcileKind.
+func (r *ImagePolicyReconciler) ReconcileKind(ctx context.Context, ip *v1alpha1.ImagePolicy) reconciler.Event {
+	key := config.ImagePolicyKey(ip.Namespace, ip.Name)
+	ipCopy := ip.DeepCopy()
+	if err := r.inlinePolicy(ctx, ip, &ipCopy.Spec); err != nil {
+		r.handleCIPError(ctx, key)
+		return err
+	}
+	return r.writeEntry(ctx, key, webhookcip.ConvertImagePolicyV1alpha1ToWebhook(ipCopy))
+}
+
+// FinalizeKind implements Interface.ReconcileKind.
+func (r *ImagePolicyReconciler) FinalizeKind(ctx context.Context, ip *v1alpha1.ImagePolicy) reconciler.Event {
+	if err := r.removeEntry(ctx, config.ImagePolicyKey(ip.Namespace, ip.Name)); err != nil {
+		return err
+	}
+	// Stop informing the secrets of the namespace along with its last
+	// ImagePolicy.
+	ips, err := r.imagepolicylister.ImagePolicies(ip.Namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, other := range ips {
+		if other.DeletionTimestamp == nil {
+			return nil
+		}
+	}
+	r.namespaceSecrets.Release(ip.Namespace)
+	return nil
+}
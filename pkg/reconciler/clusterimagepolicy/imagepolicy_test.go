@@ -0,0 +1,229 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterimagepolicy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/sigstore/cosign/pkg/apis/config"
+	"github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
+	fakecosignclient "github.com/sigstore/cosign/pkg/client/injection/client/fake"
+	"github.com/sigstore/cosign/pkg/client/injection/reconciler/policy/v1alpha1/imagepolicy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/tracker"
+
+	. "github.com/sigstore/cosign/pkg/reconciler/testing/v1alpha1"
+	. "knative.dev/pkg/reconciler/testing"
+	_ "knative.dev/pkg/system/testing"
+)
+
+const (
+	ipNamespace = "team-a"
+	ipName      = "test-ip"
+	ipTestKey   = "team-a/test-ip"
+
+	// This is the patch for adding the entry of the ImagePolicy, which keeps
+	// its namespace.
+	addIPPatch = `[{"op":"add","path":"/data/team-a_test-ip","value":"{\"images\":[{\"glob\":\"ghcr.io/example/*\"}],\"authorities\":[{\"name\":\"authority-0\",\"key\":{\"data\":\"-----BEGIN PUBLIC KEY-----\\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAExB6+H6054/W1SJgs5JR6AJr6J35J\\nRCTfQ5s1kD+hGMSE1rH7s46hmXEeyhnlRnaGF8eMU/SBJE/2NKPnxE7WzQ==\\n-----END PUBLIC KEY-----\"}}],\"namespace\":\"team-a\"}"}]`
+
+	// This is the patch for removing the entry of the ImagePolicy, leaving
+	// the one of the ClusterImagePolicy.
+	removeIPPatch = `[{"op":"remove","path":"/data/team-a_test-ip"}]`
+)
+
+func TestReconcileImagePolicy(t *testing.T) {
+	privKMSKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate private key: %v", err)
+	}
+
+	table := TableTest{{
+		Name: "ImagePolicy not found",
+		Key:  ipTestKey,
+	}, {
+		Name: "ImagePolicy with glob and inline key data, added as a patch with its namespace",
+		Key:  ipTestKey,
+
+		SkipNamespaceValidation: true, // The configmap is in the system namespace
+		Objects: []runtime.Object{
+			NewImagePolicy(ipNamespace, ipName,
+				WithImagePolicyFinalizer,
+				WithImagePolicyImagePattern(v1alpha1.ImagePattern{
+					Glob: glob,
+				}),
+				WithImagePolicyAuthority(v1alpha1.Authority{
+					Key: &v1alpha1.KeyRef{
+						Data: validPublicKeyData,
+					}})),
+			makeConfigMap(),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			makePatch(addIPPatch),
+		},
+	}, {
+		Name: "ImagePolicy with glob and inline key data, added finalizer",
+		Key:  ipTestKey,
+
+		Objects: []runtime.Object{
+			NewImagePolicy(ipNamespace, ipName,
+				WithImagePolicyImagePattern(v1alpha1.ImagePattern{
+					Glob: glob,
+				}),
+				WithImagePolicyAuthority(v1alpha1.Authority{
+					Key: &v1alpha1.KeyRef{
+						Data: validPublicKeyData,
+					}})),
+			makeConfigMapWithImagePolicy(),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchImagePolicyFinalizers(ipNamespace, ipName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "test-ip" finalizers`),
+		},
+	}, {
+		Name: "Key with secret in the namespace of the ImagePolicy, inlined",
+		Key:  ipTestKey,
+
+		SkipNamespaceValidation: true, // The configmap is in the system namespace
+		Objects: []runtime.Object{
+			NewImagePolicy(ipNamespace, ipName,
+				WithImagePolicyFinalizer,
+				WithImagePolicyImagePattern(v1alpha1.ImagePattern{
+					Glob: glob,
+				}),
+				WithImagePolicyAuthority(v1alpha1.Authority{
+					Key: &v1alpha1.KeyRef{
+						SecretRef: &corev1.SecretReference{
+							Name: keySecretName,
+						},
+					}})),
+			// The secret of the same name in the system namespace is not
+			// used by the ImagePolicy.
+			makeSecret(keySecretName, "garbage"),
+			makeNamespacedSecret(ipNamespace, keySecretName, validPublicKeyData),
+			makeConfigMap(),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			makePatch(addIPPatch),
+		},
+	}, {
+		Name: "Key with secret, secret does not exist in the namespace of the ImagePolicy",
+		Key:  ipTestKey,
+
+		SkipNamespaceValidation: true, // The configmap is in the system namespace
+		Objects: []runtime.Object{
+			NewImagePolicy(ipNamespace, ipName,
+				WithImagePolicyFinalizer,
+				WithImagePolicyImagePattern(v1alpha1.ImagePattern{
+					Glob: glob,
+				}),
+				WithImagePolicyAuthority(v1alpha1.Authority{
+					Key: &v1alpha1.KeyRef{
+						SecretRef: &corev1.SecretReference{
+							Name: keySecretName,
+						},
+					}})),
+			makeSecret(keySecretName, validPublicKeyData),
+			makeConfigMapWithImagePolicy(),
+		},
+		WantErr: true,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			makePatch(removeIPPatch),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError", `secret "publickey-key" not found`),
+		},
+	}, {
+		Name: "ImagePolicy is being deleted, entry removed from configmap",
+		Key:  ipTestKey,
+
+		SkipNamespaceValidation: true, // The configmap is in the system namespace
+		Objects: []runtime.Object{
+			NewImagePolicy(ipNamespace, ipName,
+				WithImagePolicyFinalizer,
+				WithImagePolicyImagePattern(v1alpha1.ImagePattern{
+					Glob: glob,
+				}),
+				WithImagePolicyAuthority(v1alpha1.Authority{
+					Key: &v1alpha1.KeyRef{
+						Data: validPublicKeyData,
+					}}),
+				WithImagePolicyDeletionTimestamp),
+			makeConfigMapWithImagePolicy(),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchRemoveFinalizers(ipNamespace, ipName),
+			makePatch(removeIPPatch),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "test-ip" finalizers`),
+		},
+	}}
+
+	logger := logtesting.TestLogger(t)
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &ImagePolicyReconciler{
+			Reconciler: &Reconciler{
+				secretlister:     listers.GetSecretLister(),
+				configmaplister:  listers.GetConfigMapLister(),
+				kubeclient:       fakekubeclient.Get(ctx),
+				tracker:          ctx.Value(TrackerKey).(tracker.Interface),
+				namespaceSecrets: newNamespaceSecrets(ctx, fakekubeclient.Get(ctx), cache.ResourceEventHandlerFuncs{}),
+			},
+			imagepolicylister: listers.GetImagePolicyLister(),
+		}
+		return imagepolicy.NewReconciler(ctx, logger,
+			fakecosignclient.Get(ctx), listers.GetImagePolicyLister(),
+			controller.GetEventRecorder(ctx),
+			r)
+	},
+		false,
+		logger,
+		privKMSKey,
+	))
+}
+
+func makeNamespacedSecret(namespace, name, secret string) *corev1.Secret {
+	s := makeSecret(name, secret)
+	s.Namespace = namespace
+	return s
+}
+
+func makeConfigMapWithImagePolicy() *corev1.ConfigMap {
+	cm := makeConfigMap()
+	cm.Data[config.ImagePolicyKey(ipNamespace, ipName)] = `{"images":[{"glob":"ghcr.io/example/*"}],"authorities":[{"name":"authority-0","key":{"data":"-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAExB6+H6054/W1SJgs5JR6AJr6J35J\nRCTfQ5s1kD+hGMSE1rH7s46hmXEeyhnlRnaGF8eMU/SBJE/2NKPnxE7WzQ==\n-----END PUBLIC KEY-----"}}],"namespace":"team-a"}`
+	return cm
+}
+
+func patchImagePolicyFinalizers(namespace, name string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	patch := `{"metadata":{"finalizers":["` + imagePolicyFinalizerName + `"],"resourceVersion":""}}`
+	action.Patch = []byte(patch)
+	return action
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterimagepolicy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// For testing
+var namespaceSecretsSyncTimeout = time.Minute
+
+// namespaceSecrets informs the secrets of the namespaces of the ImagePolicies.
+// The injected informers only inform the secrets of the system namespace, so
+// that the controller doesn't cache all the secrets of the cluster. The
+// informer of another namespace is started along with the first ImagePolicy
+// reconciled in it, and stopped along with the last one.
+type namespaceSecrets struct {
+	ctx     context.Context
+	client  kubernetes.Interface
+	handler cache.ResourceEventHandler
+
+	mu        sync.Mutex
+	informers map[string]*namespaceSecretInformer
+}
+
+type namespaceSecretInformer struct {
+	stop context.CancelFunc
+	// synced is closed once the informer synced, or failed to, and lister
+	// or err is set.
+	synced chan struct{}
+	lister corev1listers.SecretLister
+	err    error
+}
+
+// newNamespaceSecrets returns the informers of the secrets of the namespaces
+// of the ImagePolicies, which notify handler of their changes until ctx is
+// done.
+func newNamespaceSecrets(ctx context.Context, client kubernetes.Interface, handler cache.ResourceEventHandler) *namespaceSecrets {
+	return &namespaceSecrets{
+		ctx:       ctx,
+		client:    client,
+		handler:   handler,
+		informers: map[string]*namespaceSecretInformer{},
+	}
+}
+
+// Lister returns the lister of the secrets of namespace, whose informer is
+// started on first use. The lock is not held while the informer syncs, so
+// that a namespace slow to sync doesn't block the others.
+func (s *namespaceSecrets) Lister(namespace string) (corev1listers.SecretNamespaceLister, error) {
+	s.mu.Lock()
+	i, ok := s.informers[namespace]
+	if !ok {
+		i = s.start(namespace)
+		s.informers[namespace] = i
+	}
+	s.mu.Unlock()
+
+	<-i.synced
+	if i.err != nil {
+		return nil, i.err
+	}
+	return i.lister.Secrets(namespace), nil
+}
+
+// start starts the informer of the secrets of namespace, which is then
+// waited for in the background. It fails when it doesn't sync before
+// namespaceSecretsSyncTimeout, and is started again on the next use.
+func (s *namespaceSecrets) start(namespace string) *namespaceSecretInformer {
+	logging.FromContext(s.ctx).Infof("Informing the secrets of namespace %q", namespace)
+	ctx, cancel := context.WithCancel(s.ctx)
+	factory := informers.NewSharedInformerFactoryWithOptions(s.client, controller.GetResyncPeriod(ctx), informers.WithNamespace(namespace))
+	secretInformer := factory.Core().V1().Secrets()
+	secretInformer.Informer().AddEventHandler(s.handler)
+	factory.Start(ctx.Done())
+
+	i := &namespaceSecretInformer{stop: cancel, synced: make(chan struct{})}
+	go func() {
+		defer close(i.synced)
+		syncCtx, syncCancel := context.WithTimeout(ctx, namespaceSecretsSyncTimeout)
+		defer syncCancel()
+		if cache.WaitForCacheSync(syncCtx.Done(), secretInformer.Informer().HasSynced) {
+			i.lister = secretInformer.Lister()
+			return
+		}
+		i.err = fmt.Errorf("failed to sync the secrets of namespace %q", namespace)
+		cancel()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.informers[namespace] == i {
+			delete(s.informers, namespace)
+		}
+	}()
+	return i
+}
+
+// Release stops informing the secrets of namespace.
+func (s *namespaceSecrets) Release(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i, ok := s.informers[namespace]; ok {
+		logging.FromContext(s.ctx).Infof("No longer informing the secrets of namespace %q", namespace)
+		i.stop()
+		delete(s.informers, namespace)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterimagepolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/tracker"
+
+	. "github.com/sigstore/cosign/pkg/reconciler/testing/v1alpha1"
+)
+
+func TestNamespaceSecrets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	secret := makeNamespacedSecret(ipNamespace, keySecretName, validPublicKeyData)
+	client := fakekube.NewSimpleClientset(secret)
+
+	// Changes to the secret re-reconcile the ImagePolicy tracking it.
+	reconciled := make(chan types.NamespacedName, 10)
+	tr := tracker.New(func(key types.NamespacedName) { reconciled <- key }, time.Minute)
+	ip := NewImagePolicy(ipNamespace, ipName)
+	ip.APIVersion, ip.Kind = "policy.sigstore.dev/v1alpha1", "ImagePolicy"
+	if err := tr.TrackReference(tracker.Reference{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  ipNamespace,
+		Name:       keySecretName,
+	}, ip); err != nil {
+		t.Fatalf("TrackReference() = %v", err)
+	}
+	ns := newNamespaceSecrets(ctx, client, controller.HandleAll(
+		controller.EnsureTypeMeta(tr.OnChanged, corev1.SchemeGroupVersion.WithKind("Secret"))))
+
+	lister, err := ns.Lister(ipNamespace)
+	if err != nil {
+		t.Fatalf("Lister() = %v", err)
+	}
+	if _, err := lister.Get(keySecretName); err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	// The informer notifies the secret when it is added to its cache.
+	drain := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case <-reconciled:
+		case <-drain:
+			done = true
+		}
+	}
+
+	rotated := secret.DeepCopy()
+	rotated.Data = map[string][]byte{"publicKey": []byte("rotated")}
+	if _, err := client.CoreV1().Secrets(ipNamespace).Update(ctx, rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	select {
+	case key := <-reconciled:
+		if want := (types.NamespacedName{Namespace: ipNamespace, Name: ipName}); key != want {
+			t.Errorf("reconciled %v, wanted %v", key, want)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the ImagePolicy was not reconciled after its secret changed")
+	}
+
+	ns.Release(ipNamespace)
+	if len(ns.informers) != 0 {
+		t.Errorf("informers = %v after Release(), wanted none", ns.informers)
+	}
+}
+
+func TestNamespaceSecretsSlowNamespace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	timeout := namespaceSecretsSyncTimeout
+	t.Cleanup(func() { namespaceSecretsSyncTimeout = timeout })
+	namespaceSecretsSyncTimeout = 2 * time.Second
+
+	// The secrets of the slow namespace can't be listed, as when the
+	// controller isn't allowed to.
+	client := fakekube.NewSimpleClientset(makeNamespacedSecret(ipNamespace, keySecretName, validPublicKeyData))
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "slow" {
+			return true, nil, errors.New("forbidden")
+		}
+		return false, nil, nil
+	})
+	ns := newNamespaceSecrets(ctx, client, controller.HandleAll(func(interface{}) {}))
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := ns.Lister("slow")
+		slow <- err
+	}()
+	// Wait for the slow namespace to be syncing.
+	for {
+		ns.mu.Lock()
+		_, ok := ns.informers["slow"]
+		ns.mu.Unlock()
+		if ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The other namespaces don't wait for it.
+	start := time.Now()
+	if _, err := ns.Lister(ipNamespace); err != nil {
+		t.Fatalf("Lister() = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= namespaceSecretsSyncTimeout {
+		t.Errorf("Lister() took %v, waiting for the slow namespace", elapsed)
+	}
+
+	if err := <-slow; err == nil {
+		t.Error("Lister() of a namespace which can't sync succeeded")
+	}
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	if _, ok := ns.informers["slow"]; ok {
+		t.Error("the informer which failed to sync is kept")
+	}
+}
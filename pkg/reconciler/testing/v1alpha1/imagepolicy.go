@@ -0,0 +1,64 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"context"
+	"time"
+
+	"github.com/sigstore/cosign/pkg/apis/policy/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const imagePolicyFinalizerName = "imagepolicies.policy.sigstore.dev"
+
+// ImagePolicyOption enables further configuration of an ImagePolicy.
+type ImagePolicyOption func(*v1alpha1.ImagePolicy)
+
+// NewImagePolicy creates an ImagePolicy with ImagePolicyOptions.
+func NewImagePolicy(namespace, name string, o ...ImagePolicyOption) *v1alpha1.ImagePolicy {
+	ip := &v1alpha1.ImagePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+	for _, opt := range o {
+		opt(ip)
+	}
+	ip.SetDefaults(context.Background())
+	return ip
+}
+
+func WithImagePolicyDeletionTimestamp(ip *v1alpha1.ImagePolicy) {
+	t := metav1.NewTime(time.Unix(1e9, 0))
+	ip.ObjectMeta.SetDeletionTimestamp(&t)
+}
+
+func WithImagePolicyImagePattern(pattern v1alpha1.ImagePattern) ImagePolicyOption {
+	return func(ip *v1alpha1.ImagePolicy) {
+		ip.Spec.Images = append(ip.Spec.Images, pattern)
+	}
+}
+
+func WithImagePolicyAuthority(a v1alpha1.Authority) ImagePolicyOption {
+	return func(ip *v1alpha1.ImagePolicy) {
+		ip.Spec.Authorities = append(ip.Spec.Authorities, a)
+	}
+}
+
+func WithImagePolicyFinalizer(ip *v1alpha1.ImagePolicy) {
+	ip.Finalizers = []string{imagePolicyFinalizerName}
+}
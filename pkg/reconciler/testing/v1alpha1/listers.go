@@ -86,6 +86,10 @@ func (l *Listers) GetClusterImagePolicyLister() cosignlisters.ClusterImagePolicy
 	return cosignlisters.NewClusterImagePolicyLister(l.indexerFor(&v1alpha1.ClusterImagePolicy{}))
 }
 
+func (l *Listers) GetImagePolicyLister() cosignlisters.ImagePolicyLister {
+	return cosignlisters.NewImagePolicyLister(l.indexerFor(&v1alpha1.ImagePolicy{}))
+}
+
 func (l *Listers) GetSecretLister() corev1listers.SecretLister {
 	return corev1listers.NewSecretLister(l.indexerFor(&corev1.Secret{}))
 }
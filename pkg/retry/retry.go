@@ -0,0 +1,309 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retry implements the policy used to retry the requests cosign sends
+// to registries, Rekor, Fulcio and TUF mirrors when they fail transiently.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// MaxAttemptsEnvKey is the environment variable overriding Policy.MaxAttempts.
+	MaxAttemptsEnvKey = "COSIGN_RETRY_MAX_ATTEMPTS"
+	// InitialBackoffEnvKey is the environment variable overriding Policy.InitialBackoff.
+	InitialBackoffEnvKey = "COSIGN_RETRY_INITIAL_BACKOFF"
+	// MaxBackoffEnvKey is the environment variable overriding Policy.MaxBackoff.
+	MaxBackoffEnvKey = "COSIGN_RETRY_MAX_BACKOFF"
+)
+
+// Policy describes how failed requests are retried.
+type Policy struct {
+	// MaxAttempts is the number of times a request is sent at most,
+	// 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the time waited before the first retry, it doubles
+	// after each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time waited between two attempts. Requests the
+	// server asks to retry later than that are not retried.
+	MaxBackoff time.Duration
+}
+
+// DefaultPolicy is the policy used when none is configured.
+var DefaultPolicy = Policy{
+	MaxAttempts:    4,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// Validate checks that the policy is usable.
+func (p Policy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("max attempts must be at least 1, got %d", p.MaxAttempts)
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return errors.New("backoffs must not be negative")
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		return fmt.Errorf("max backoff %v is shorter than initial backoff %v", p.MaxBackoff, p.InitialBackoff)
+	}
+	return nil
+}
+
+// Backoff returns the time to wait before the n-th retry, starting at 1.
+func (p Policy) Backoff(n int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// PolicyFromEnv returns DefaultPolicy, overridden by the environment variables
+// which are set.
+func PolicyFromEnv() (Policy, error) {
+	p := DefaultPolicy
+	if v := os.Getenv(MaxAttemptsEnvKey); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Policy{}, fmt.Errorf("parsing $%s: %w", MaxAttemptsEnvKey, err)
+		}
+		p.MaxAttempts = n
+	}
+	for key, d := range map[string]*time.Duration{
+		InitialBackoffEnvKey: &p.InitialBackoff,
+		MaxBackoffEnvKey:     &p.MaxBackoff,
+	} {
+		if v := os.Getenv(key); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return Policy{}, fmt.Errorf("parsing $%s: %w", key, err)
+			}
+			*d = parsed
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return Policy{}, err
+	}
+	return p, nil
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultPolicy *Policy
+)
+
+// SetDefault sets the policy returned by Default.
+func SetDefault(p Policy) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultPolicy = &p
+}
+
+// Default returns the policy set with SetDefault or, if none was, the one
+// configured by the environment, falling back to DefaultPolicy when it is
+// invalid.
+func Default() Policy {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultPolicy != nil {
+		return *defaultPolicy
+	}
+	p, err := PolicyFromEnv()
+	if err != nil {
+		return DefaultPolicy
+	}
+	return p
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks the requests sent with the returned context as safe to
+// send several times whatever their method, such as a POST searching the
+// transparency log, or uploading an entry to it, which conflicts with the
+// existing entry rather than creating a duplicate.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	v, _ := req.Context().Value(idempotentKey{}).(bool)
+	return v
+}
+
+// Error is returned by the transport when a request kept failing with a
+// network error. It is not temporary, so that callers with a retry loop
+// of their own don't retry it again.
+type Error struct {
+	Attempts int
+	Err      error
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return fmt.Sprintf("giving up after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Temporary returns false.
+func (e *Error) Temporary() bool {
+	return false
+}
+
+// NewTransport returns a transport sending the requests with inner, and
+// retrying them according to p when they fail with a network error, a 429 or
+// a 5xx status. The Retry-After header of the responses is respected.
+//
+// To avoid applying a request twice, requests whose method is not idempotent
+// are only retried on 429 and 503 statuses, which tell the request was not
+// processed, unless their context is marked with WithIdempotent. Requests
+// whose body cannot be read again are never retried.
+func NewTransport(inner http.RoundTripper, p Policy) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return &transport{inner: inner, policy: p}
+}
+
+type transport struct {
+	inner  http.RoundTripper
+	policy Policy
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if t.policy.MaxAttempts <= 1 || !replayable {
+		return t.inner.RoundTrip(req)
+	}
+	idempotent := isIdempotent(req)
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.inner.RoundTrip(r)
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if !idempotent || !retryableError(ctx, err) {
+				return nil, err
+			}
+			if attempt >= t.policy.MaxAttempts {
+				return nil, &Error{Attempts: attempt, Err: err}
+			}
+			wait = t.policy.Backoff(attempt)
+		case retryableStatus(resp.StatusCode, idempotent):
+			if attempt >= t.policy.MaxAttempts {
+				return resp, nil
+			}
+			if wait = retryAfter(resp, t.policy.Backoff(attempt)); wait > t.policy.MaxBackoff {
+				return resp, nil
+			}
+			// Drain the body so the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func retryableStatus(code int, idempotent bool) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// Retryable reports whether err is a network error worth retrying, which the
+// transport did not already give up on. It lets callers retrying operations
+// the transport cannot, such as streamed uploads, follow the same rules.
+func Retryable(err error) bool {
+	var e *Error
+	if err == nil || errors.As(err, &e) {
+		return false
+	}
+	return retryableError(context.Background(), err)
+}
+
+func retryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// retryAfter returns the delay requested by the Retry-After header of resp,
+// or def if it has no valid one.
+func retryAfter(resp *http.Response, def time.Duration) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return def
+}
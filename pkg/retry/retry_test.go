@@ -0,0 +1,258 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+func TestBackoff(t *testing.T) {
+	p := Policy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 9: 5 * time.Second} {
+		if got := p.Backoff(n); got != want {
+			t.Errorf("Backoff(%d) = %v, wanted %v", n, got, want)
+		}
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv(MaxAttemptsEnvKey, "7")
+	t.Setenv(MaxBackoffEnvKey, "1m")
+	p, err := PolicyFromEnv()
+	if err != nil {
+		t.Fatalf("PolicyFromEnv() = %v", err)
+	}
+	want := Policy{MaxAttempts: 7, InitialBackoff: DefaultPolicy.InitialBackoff, MaxBackoff: time.Minute}
+	if p != want {
+		t.Errorf("PolicyFromEnv() = %+v, wanted %+v", p, want)
+	}
+
+	t.Setenv(MaxAttemptsEnvKey, "0")
+	if _, err := PolicyFromEnv(); err == nil {
+		t.Error("PolicyFromEnv() succeeded with 0 attempts")
+	}
+	t.Setenv(MaxAttemptsEnvKey, "")
+	t.Setenv(InitialBackoffEnvKey, "soon")
+	if _, err := PolicyFromEnv(); err == nil {
+		t.Error("PolicyFromEnv() succeeded with an invalid duration")
+	}
+}
+
+// server fails the first `failures` requests with `status`.
+func server(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if r.Body != nil {
+			if b, _ := io.ReadAll(r.Body); r.Method == http.MethodPost && string(b) != "body" {
+				t.Errorf("attempt %d got body %q", n, b)
+			}
+		}
+		if n <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+	return s, &calls
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		idempotent bool
+		failures   int32
+		status     int
+		header     http.Header
+		wantStatus int
+		wantCalls  int32
+	}{{
+		name:       "GET retried on 500",
+		method:     http.MethodGet,
+		failures:   2,
+		status:     http.StatusInternalServerError,
+		wantStatus: http.StatusOK,
+		wantCalls:  3,
+	}, {
+		name:       "GET gives up after max attempts",
+		method:     http.MethodGet,
+		failures:   5,
+		status:     http.StatusBadGateway,
+		wantStatus: http.StatusBadGateway,
+		wantCalls:  3,
+	}, {
+		name:       "GET not retried on 404",
+		method:     http.MethodGet,
+		failures:   1,
+		status:     http.StatusNotFound,
+		wantStatus: http.StatusNotFound,
+		wantCalls:  1,
+	}, {
+		name:       "POST retried on 429",
+		method:     http.MethodPost,
+		failures:   1,
+		status:     http.StatusTooManyRequests,
+		wantStatus: http.StatusOK,
+		wantCalls:  2,
+	}, {
+		name:       "POST not retried on 500",
+		method:     http.MethodPost,
+		failures:   1,
+		status:     http.StatusInternalServerError,
+		wantStatus: http.StatusInternalServerError,
+		wantCalls:  1,
+	}, {
+		name:       "idempotent POST retried on 500",
+		method:     http.MethodPost,
+		idempotent: true,
+		failures:   1,
+		status:     http.StatusInternalServerError,
+		wantStatus: http.StatusOK,
+		wantCalls:  2,
+	}, {
+		name:       "Retry-After respected",
+		method:     http.MethodGet,
+		failures:   1,
+		status:     http.StatusServiceUnavailable,
+		header:     http.Header{"Retry-After": []string{"0"}},
+		wantStatus: http.StatusOK,
+		wantCalls:  2,
+	}, {
+		name:       "Retry-After beyond max backoff",
+		method:     http.MethodGet,
+		failures:   1,
+		status:     http.StatusServiceUnavailable,
+		header:     http.Header{"Retry-After": []string{"3600"}},
+		wantStatus: http.StatusServiceUnavailable,
+		wantCalls:  1,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, calls := server(t, test.failures, test.status, test.header)
+
+			ctx := context.Background()
+			if test.idempotent {
+				ctx = WithIdempotent(ctx)
+			}
+			var body io.Reader
+			if test.method == http.MethodPost {
+				body = strings.NewReader("body")
+			}
+			req, err := http.NewRequestWithContext(ctx, test.method, s.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := NewTransport(http.DefaultTransport, testPolicy).RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip() = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("status = %d, wanted %d", resp.StatusCode, test.wantStatus)
+			}
+			if got := atomic.LoadInt32(calls); got != test.wantCalls {
+				t.Errorf("server called %d times, wanted %d", got, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTransportUnreplayableBody(t *testing.T) {
+	s, calls := server(t, 1, http.StatusServiceUnavailable, nil)
+	req, err := http.NewRequest(http.MethodPut, s.URL, io.NopCloser(bytes.NewBufferString("body")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewTransport(http.DefaultTransport, testPolicy).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() = %v", err)
+	}
+	resp.Body.Close()
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("server called %d times, wanted 1", got)
+	}
+}
+
+type failingTransport struct {
+	calls int
+	err   error
+}
+
+func (f *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func TestTransportNetworkErrors(t *testing.T) {
+	ft := &failingTransport{err: syscall.ECONNRESET}
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewTransport(ft, testPolicy).RoundTrip(req)
+	var re *Error
+	if !errors.As(err, &re) || re.Attempts != 3 || !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("RoundTrip() = %v, wanted to give up after 3 attempts", err)
+	}
+	if ft.calls != 3 {
+		t.Errorf("inner transport called %d times, wanted 3", ft.calls)
+	}
+	if Retryable(err) {
+		t.Error("Retryable() = true for an error the transport gave up on")
+	}
+	if !Retryable(syscall.ECONNRESET) {
+		t.Error("Retryable(ECONNRESET) = false")
+	}
+
+	ft = &failingTransport{err: errors.New("x509: certificate signed by unknown authority")}
+	if _, err := NewTransport(ft, testPolicy).RoundTrip(req); err == nil || ft.calls != 1 {
+		t.Errorf("RoundTrip() = %v after %d calls, wanted a single attempt", err, ft.calls)
+	}
+}
+
+func TestTransportCancelled(t *testing.T) {
+	s, _ := server(t, 10, http.StatusServiceUnavailable, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Policy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := NewTransport(http.DefaultTransport, p).RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() = %v, wanted %v", err, context.Canceled)
+	}
+}
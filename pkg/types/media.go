@@ -31,3 +31,12 @@ const (
 	WasmLayerMediaType     = "application/vnd.wasm.content.layer.v1+wasm"
 	WasmConfigMediaType    = "application/vnd.wasm.config.v1+json"
 )
+
+// The media types of the layer of the policies pushed by cosign policy push,
+// one per policy type.
+const (
+	CUEPolicyMediaType        = "application/vnd.dev.cosign.policy.v1+cue"
+	RegoPolicyMediaType       = "application/vnd.dev.cosign.policy.v1+rego"
+	JSONSchemaPolicyMediaType = "application/vnd.dev.cosign.policy.v1+jsonschema"
+	CELPolicyMediaType        = "application/vnd.dev.cosign.policy.v1+cel"
+)